@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	_ "mpc/docs"
 	"mpc/internal/api"
 	"mpc/internal/config"
@@ -8,10 +10,15 @@ import (
 	"mpc/internal/db/redis"
 	"mpc/internal/repository"
 	"mpc/internal/service"
+	"mpc/pkg/envelope"
 	"mpc/pkg/ethereum"
+	"mpc/pkg/lock"
 	"mpc/pkg/logger"
+	"mpc/pkg/ratelimit"
 	"mpc/pkg/token"
 	"mpc/pkg/tss"
+	"net/http"
+	"os"
 )
 
 // @title MPC API
@@ -51,24 +58,49 @@ func main() {
 	// token
 	tokenManager := token.NewTokenManager(redisClient)
 
-	// ethereum
-	ethClient, err := ethereum.NewEthClient(cfg.Eth.URL)
+	// tss
+	tssClient, err := tss.NewClient(redisClient, &cfg.TSS)
+	// Signing, the job workers and the health check all need the client
 	if err != nil {
-		logger.Error("Failed to initialize Ethereum client", err)
+		logger.Error("Failed to initialize TSS client", err)
+		os.Exit(1)
 	}
 
-	// tss
-	tssClient, err := tss.NewTSS(redisClient)
+	// Wallets may only use topologies of the configured parties
+	topologies, err := tss.AllowedTopologies(&cfg.TSS)
 	if err != nil {
-		logger.Error("Failed to initialize TSS client", err)
+		logger.Error("Invalid TSS topologies", err)
+		os.Exit(1)
+	}
+
+	// custody
+	var shareVault *envelope.Envelope
+	if cfg.Custody.Enabled {
+		logger.Info("Custody mode enabled")
+		kek, err := envelope.NewKEKProvider(&cfg.Custody)
+		// Shares cannot be sealed or opened without the KEK, so the API must not start
+		if err != nil {
+			logger.Error("Failed to initialize KEK provider", err)
+			os.Exit(1)
+		}
+		shareVault = envelope.New(kek)
 	}
 
 	// repository
 	chainRepo := repository.NewChainRepository(dbPool)
 	tokenRepo := repository.NewTokenRepository(dbPool)
 	transactionRepo := repository.NewTransactionRepository(dbPool)
+	transactionJobRepo := repository.NewTransactionJobRepository(dbPool)
+	tssSessionRepo := repository.NewTSSSessionRepository(dbPool)
 	userRepo := repository.NewUserRepository(dbPool)
 	walletRepo := repository.NewWalletRepository(dbPool)
+	walletAuditEventRepo := repository.NewWalletAuditEventRepository(dbPool)
+	walletNonceRepo := repository.NewWalletNonceRepository(dbPool)
+	idempotencyKeyRepo := repository.NewIdempotencyKeyRepository(dbPool)
+
+	// ethereum, one client per active chain
+	chainRegistry := ethereum.NewRegistry(chainRepo, cfg.Eth.GasLimitMargin)
+	defer chainRegistry.Close()
 
 	// service
 	oauthClient := &service.GoogleOAuthClient{
@@ -77,16 +109,58 @@ func main() {
 		RedirectURI:  cfg.OauthClient.RedirectURI,
 	}
 	assetService := service.NewAssetService(chainRepo, tokenRepo, redisClient)
-	walletService := service.NewWalletService(walletRepo, tssClient)
+	tssSessionService := service.NewTSSSessionService(tssSessionRepo)
+	walletService := service.NewWalletService(walletRepo, tssSessionService, tssClient, topologies, shareVault)
 	userService := service.NewUserService(userRepo, walletRepo, redisClient)
 	authService := service.NewAuthService(userService, walletService, tokenManager, oauthClient)
-	transactionService := service.NewTransactionService(transactionRepo, walletService, assetService, ethClient, tssClient)
+	signingService := service.NewSigningService(walletService, assetService, tssSessionService, tssClient)
+	locker := lock.NewLocker(redisClient)
+	nonceManager := service.NewNonceManager(walletNonceRepo, locker, &cfg.Nonce)
+	idempotencyService := service.NewIdempotencyService(idempotencyKeyRepo, redisClient, &cfg.Idempotency)
+	transactionService := service.NewTransactionService(
+		transactionRepo,
+		transactionJobRepo,
+		walletService,
+		assetService,
+		signingService,
+		chainRegistry,
+		nonceManager,
+		idempotencyService,
+		locker,
+		&cfg.Txn,
+	)
+	backupService := service.NewBackupService(walletService, walletAuditEventRepo, ratelimit.NewLimiter(redisClient), &cfg.Backup)
+	healthService := service.NewHealthService(tssClient)
+
+	// transaction workers
+	logger.Info("Starting transaction workers")
+	transactionService.StartJobWorkers(context.Background())
+
+	// confirmation tracker
+	logger.Info("Starting transaction confirmation tracker")
+	transactionService.StartConfirmationTracker(context.Background())
+
+	// metrics, on an internal listener since they include the command line and memory stats
+	if cfg.Metrics.Address != "" {
+		go serveMetrics(cfg.Metrics.Address)
+	}
 
 	// router
-	router := api.NewRouter(authService, assetService, userService, transactionService, tokenManager)
+	router := api.NewRouter(authService, assetService, userService, walletService, transactionService, signingService, backupService, tssSessionService, healthService, tokenManager)
 
 	// run router
 	logger.Info("Running router")
 	logger.Info("Server running on port " + cfg.Port)
 	router.Run(":" + cfg.Port)
 }
+
+// serveMetrics serves the expvar metrics at /metrics
+func serveMetrics(address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", expvar.Handler())
+
+	logger.Info("Metrics listening on " + address)
+	if err := http.ListenAndServe(address, mux); err != nil {
+		logger.Error("Metrics listener stopped", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"mpc/internal/config"
+	"mpc/internal/db/redis"
+	"mpc/internal/mpcnode"
+	"mpc/pkg/envelope"
+	"mpc/pkg/logger"
+	"mpc/pkg/tss"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+)
+
+// mpcnode runs a development stub of the MPC cluster in a single process. It
+// uses trusted dealer secret sharing instead of threshold ECDSA, see
+// internal/mpcnode.
+func main() {
+	logger.Info("Starting MPC node")
+
+	// config
+	logger.Info("Loading config")
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("Failed to load config", err)
+		os.Exit(1)
+	}
+
+	// The node holds enough to rebuild every key with a client share, so it
+	// only runs when that is explicitly accepted
+	if !cfg.MPCNode.InsecureDealer {
+		logger.Error("Refusing to start", errors.New("mpcnode is a trusted dealer, not threshold ECDSA: set MPCNODE_INSECURE_DEALER=true to run it for development"))
+		os.Exit(1)
+	}
+	logger.Warn("mpcnode is a development stub, not threshold ECDSA: keys are reconstructed in memory to sign")
+
+	// redis
+	logger.Info("Initializing Redis client")
+	redisClient, err := redis.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		logger.Error("Failed to initialize Redis client", err)
+		os.Exit(1)
+	}
+	defer redisClient.Close()
+
+	// result signing
+	var signer *tss.ResultSigner
+	if cfg.MPCNode.ResultKey != "" {
+		signer, err = tss.NewResultSigner(cfg.MPCNode.NodeID, cfg.MPCNode.ResultKey)
+		if err != nil {
+			logger.Error("Failed to initialize result signer", err)
+			os.Exit(1)
+		}
+	} else {
+		logger.Warn("MPCNODE_RESULT_KEY is not set, results are published without a MAC")
+	}
+
+	// state
+	var state *envelope.Envelope
+	if cfg.MPCNode.StateFile != "" {
+		key, err := hex.DecodeString(cfg.MPCNode.StateKey)
+		if err != nil {
+			logger.Error("Invalid MPCNODE_STATE_KEY", err)
+			os.Exit(1)
+		}
+		kek, err := envelope.NewAESKEK("mpcnode-state", key)
+		if err != nil {
+			logger.Error("Invalid MPCNODE_STATE_KEY", err)
+			os.Exit(1)
+		}
+		state = envelope.New(kek)
+	} else {
+		logger.Warn("MPCNODE_STATE_FILE is not set, keys are lost when the node stops")
+	}
+
+	// node
+	node, err := mpcnode.NewServer(redisClient, signer, cfg.MPCNode.StateFile, state)
+	if err != nil {
+		logger.Error("Failed to initialize MPC node", err)
+		os.Exit(1)
+	}
+
+	lis, err := net.Listen("tcp", cfg.MPCNode.ListenAddress)
+	if err != nil {
+		logger.Error("Failed to listen on "+cfg.MPCNode.ListenAddress, err)
+		os.Exit(1)
+	}
+
+	var opts []grpc.ServerOption
+	if cfg.MPCNode.TLSCertFile != "" {
+		creds, err := tss.ServerCredentials(cfg.MPCNode.TLSCertFile, cfg.MPCNode.TLSKeyFile, cfg.MPCNode.TLSClientCAFile)
+		if err != nil {
+			logger.Error("Failed to load TLS credentials", err)
+			os.Exit(1)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	} else {
+		logger.Warn("MPCNODE_TLS_CERT_FILE is not set, serving without TLS")
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	node.Register(grpcServer)
+
+	go node.RunHeartbeats(context.Background(), cfg.MPCNode.Parties, cfg.MPCNode.HeartbeatInterval)
+
+	logger.Info("MPC node listening on " + cfg.MPCNode.ListenAddress)
+	if err := grpcServer.Serve(lis); err != nil {
+		logger.Error("MPC node stopped", err)
+		os.Exit(1)
+	}
+}
@@ -4,32 +4,36 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math/big"
 	"mpc/internal/config"
 	"mpc/internal/db"
 	"mpc/internal/db/redis"
 	"mpc/internal/model"
 	"mpc/internal/repository"
+	"mpc/pkg/ethereum"
 	"mpc/pkg/logger"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
-)
-
-const (
-	infuraURL = "https://sepolia.infura.io/v3/6c89fb7fa351451f939eea9da6bee755"
 )
 
 var (
-	ctx                = context.Background()
-	redisClient        *redis.Client
-	monitoredAddresses map[common.Address]bool
-	txnRepo            *repository.TransactionRepository
-	walletRepo         *repository.WalletRepository
+	ctx         = context.Background()
+	redisClient *redis.Client
+	txnRepo     *repository.TransactionRepository
+	walletRepo  *repository.WalletRepository
+	chainRepo   *repository.ChainRepository
+	tokenRepo   *repository.TokenRepository
 )
 
+// chainTokens are the listed tokens of a chain, ERC-20 tokens by lowercased
+// contract address
+type chainTokens struct {
+	native model.Token
+	erc20  map[string]model.Token
+}
+
 func main() {
 	logger.Info("Starting worker")
 
@@ -50,6 +54,8 @@ func main() {
 
 	txnRepo = repository.NewTransactionRepository(dbPool)
 	walletRepo = repository.NewWalletRepository(dbPool)
+	chainRepo = repository.NewChainRepository(dbPool)
+	tokenRepo = repository.NewTokenRepository(dbPool)
 
 	// Initialize Redis
 	logger.Info("Initializing Redis client")
@@ -66,19 +72,36 @@ func main() {
 
 	go updateCachePeriodically()
 
-	// Connect to Ethereum client
-	client, err := ethclient.Dial(infuraURL)
+	// Connect to every active chain, chains added later need a restart
+	chainRegistry := ethereum.NewRegistry(chainRepo, cfg.Eth.GasLimitMargin)
+	defer chainRegistry.Close()
+
+	clients, err := chainRegistry.Clients(ctx)
 	if err != nil {
-		log.Fatalf("Failed to connect to Infura: %v", err)
+		log.Fatalf("Failed to load chains: %v", err)
+	}
+	if len(clients) == 0 {
+		log.Fatalf("No active chain to scan")
 	}
-	defer client.Close()
 
 	fmt.Println("Starting transaction scanner...")
 
-	for {
-		checkLatestBlock(client)
-		time.Sleep(10 * time.Second)
+	var wg sync.WaitGroup
+	for chainID, client := range clients {
+		wg.Add(1)
+		go func(chainID int, client *ethereum.EthClient) {
+			defer wg.Done()
+			tokens, err := loadTokens(chainID)
+			if err != nil {
+				log.Printf("Error loading tokens of chain %d: %v", chainID, err)
+			}
+			for {
+				checkLatestBlock(chainID, client, tokens)
+				time.Sleep(10 * time.Second)
+			}
+		}(chainID, client)
 	}
+	wg.Wait()
 }
 
 func loadAddressesToRedis() error {
@@ -124,8 +147,30 @@ func updateCachePeriodically() {
 	}
 }
 
-func checkLatestBlock(client *ethclient.Client) {
-	monitoredAddresses, _ = getMonitoredAddressesFromRedis()
+// loadTokens returns the tokens of a chain, so transfers of listed ERC-20
+// tokens are recorded with their recipient and amount
+func loadTokens(chainID int) (chainTokens, error) {
+	tokens := chainTokens{erc20: make(map[string]model.Token)}
+	chain, err := chainRepo.GetChainByChainID(ctx, chainID)
+	if err != nil {
+		return tokens, err
+	}
+	rows, err := tokenRepo.GetTokensByChainID(ctx, chain.ID)
+	if err != nil {
+		return tokens, err
+	}
+	for _, token := range rows {
+		if token.Type == model.TokenTypeNative {
+			tokens.native = token
+		} else {
+			tokens.erc20[strings.ToLower(token.ContractAddress)] = token
+		}
+	}
+	return tokens, nil
+}
+
+func checkLatestBlock(chainID int, client *ethereum.EthClient, tokens chainTokens) {
+	monitoredAddresses, _ := getMonitoredAddressesFromRedis()
 
 	block, err := client.BlockByNumber(ctx, nil)
 	if err != nil {
@@ -133,30 +178,55 @@ func checkLatestBlock(client *ethclient.Client) {
 		return
 	}
 
-	fmt.Printf("Scanning Block #%d...\n", block.NumberU64())
+	fmt.Printf("Scanning chain %d block #%d...\n", chainID, block.NumberU64())
 
-	for _, tx := range block.Transactions() {
+	for i, tx := range block.Transactions() {
 		if tx.To() == nil {
 			continue
 		}
 
-		from, err := client.TransactionSender(ctx, tx, block.Header().Hash(), 0)
+		from, err := client.TransactionSender(ctx, tx, block.Hash(), uint(i))
 		if err != nil {
 			log.Printf("Error getting sender: %v", err)
 			continue
 		}
 
+		// A transfer of a listed token moves the token to the recipient in its calldata
 		to := *tx.To()
+		token := tokens.native
+		amount := tx.Value()
+		decimals := int32(ethereum.NativeDecimals)
+		if erc20, ok := tokens.erc20[strings.ToLower(to.Hex())]; ok {
+			if recipient, value, ok := ethereum.DecodeTransfer(tx.Data()); ok {
+				token, to, amount, decimals = erc20, recipient, value, erc20.Decimals
+			}
+		}
+
 		if monitoredAddresses[from] || monitoredAddresses[to] {
-			fmt.Printf("Transaction Found! Hash: %s, From: %s, To: %s, Value: %s ETH\n",
-				tx.Hash().Hex(), from.Hex(), to.Hex(), weiToEth(tx.Value()))
+			txHash := strings.ToLower(tx.Hash().Hex())
+			// Sends of the API are recorded when broadcast. One recorded
+			// meanwhile is not duplicated: the insert returns the existing row.
+			if _, err := txnRepo.GetTransactionByTxHash(ctx, chainID, txHash); err == nil {
+				continue
+			}
 
-			// Save transaction to database
+			fmt.Printf("Transaction Found! Hash: %s, From: %s, To: %s, Amount: %s %s\n",
+				tx.Hash().Hex(), from.Hex(), to.Hex(), ethereum.FromBaseUnits(amount, decimals), token.Symbol)
+
+			// Save transaction to database, the tracker confirms it
+			nonce := tx.Nonce()
+			blockNumber := block.NumberU64()
 			txn := model.Transaction{
-				TxHash:      strings.ToLower(tx.Hash().Hex()),
+				TxHash:      txHash,
 				FromAddress: strings.ToLower(from.Hex()),
 				ToAddress:   strings.ToLower(to.Hex()),
-				ChainID:     11155111,
+				ChainID:     chainID,
+				TokenID:     token.ID,
+				Status:      model.TransactionStatusPending,
+				Nonce:       &nonce,
+				Value:       tx.Value().String(),
+				Amount:      amount.String(),
+				BlockNumber: &blockNumber,
 			}
 			if _, err := txnRepo.CreateTransaction(ctx, txn); err != nil {
 				log.Printf("Error saving transaction: %v", err)
@@ -164,9 +234,3 @@ func checkLatestBlock(client *ethclient.Client) {
 		}
 	}
 }
-
-func weiToEth(wei *big.Int) string {
-	ethValue := new(big.Float).SetInt(wei)
-	ethValue.Quo(ethValue, big.NewFloat(1e18))
-	return ethValue.Text('f', 6)
-}
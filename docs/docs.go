@@ -103,6 +103,12 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -160,6 +166,12 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -321,7 +333,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "payload": {
-                                            "$ref": "#/definitions/model.AuthResponse"
+                                            "$ref": "#/definitions/model.SignupResponse"
                                         }
                                     }
                                 }
@@ -345,7 +357,7 @@ const docTemplate = `{
         },
         "/health": {
             "get": {
-                "description": "Check if the server is running",
+                "description": "Check if the server is running and which MPC parties are alive. The message is DEGRADED when fewer parties than the signing threshold send heartbeats.",
                 "consumes": [
                     "application/json"
                 ],
@@ -368,10 +380,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "payload": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/model.HealthResponse"
                                         }
                                     }
                                 }
@@ -442,7 +451,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create and submit transaction",
+                "description": "Queue a transaction for signing and broadcasting, poll the returned job for the result",
                 "consumes": [
                     "application/json"
                 ],
@@ -454,6 +463,12 @@ const docTemplate = `{
                 ],
                 "summary": "Create and submit transaction",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key return the job of the first request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Transaction request",
                         "name": "request",
@@ -465,8 +480,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "allOf": [
                                 {
@@ -476,7 +491,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "payload": {
-                                            "$ref": "#/definitions/model.Transaction"
+                                            "$ref": "#/definitions/model.TransactionJobResponse"
                                         }
                                     }
                                 }
@@ -488,13 +503,107 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/user": {
+        "/transactions/batch": {
+            "post": {
+                "description": "Queue several transfers from one wallet, signed in a single TSS session and broadcast in order. Every transfer gets its own job.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create and submit a batch of transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key return the jobs of the first request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Batch request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateBatchTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.TransactionBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/jobs/{id}": {
             "get": {
-                "description": "Get user by ID",
+                "description": "Get the status of a queued transaction and the transaction once it is broadcast",
                 "consumes": [
                     "application/json"
                 ],
@@ -502,9 +611,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "transactions"
+                ],
+                "summary": "Get transaction job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get user",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -517,7 +635,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "payload": {
-                                            "$ref": "#/definitions/model.UserResponse"
+                                            "$ref": "#/definitions/model.TransactionJobResponse"
                                         }
                                     }
                                 }
@@ -530,71 +648,887 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.AuthResponse": {
-            "type": "object",
-            "properties": {
-                "access_token": {
-                    "type": "string"
-                },
-                "refresh_token": {
-                    "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/model.UserResponse"
-                },
-                "wallet": {
-                    "$ref": "#/definitions/model.WalletResponse"
+        },
+        "/transactions/quote": {
+            "post": {
+                "description": "Build the unsigned transaction a send would make and return its fees and total cost, without signing or broadcasting. Problems such as an insufficient balance or a contract recipient are returned as warnings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Quote a transaction",
+                "parameters": [
+                    {
+                        "description": "Quote request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.QuoteTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.TransactionQuoteResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "model.ChainResponse": {
-            "type": "object",
-            "properties": {
-                "chain_id": {
-                    "type": "integer",
-                    "example": 11155111
-                },
-                "explorer_url": {
-                    "type": "string",
-                    "example": "https://sepolia.etherscan.io"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "Sepolia"
-                },
-                "native_currency": {
-                    "type": "string",
-                    "example": "ETH"
-                },
-                "rpc_url": {
-                    "type": "string",
-                    "example": "https://ethereum-sepolia-rpc.publicnode.com"
+        "/transactions/{id}/cancel": {
+            "post": {
+                "description": "Queue a replacement of a pending transaction with a zero value transfer to the sender itself using the same nonce, signed in a new TSS session. Fees rise at least 10% over the original. Poll the returned job for the replacement; the original is marked replaced once it is broadcast. Fails with 409 while another speed-up or cancel of the transaction is queued or running.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Cancel a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Replacement request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ReplaceTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.TransactionJobResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "model.CreateAndSubmitTransactionRequest": {
-            "type": "object",
+        "/transactions/{id}/speed-up": {
+            "post": {
+                "description": "Queue a replacement of a pending transaction with the same transfer and nonce at higher fees, signed in a new TSS session. Fees rise at least 10% over the original. Poll the returned job for the replacement; the original is marked replaced once it is broadcast. Fails with 409 while another speed-up or cancel of the transaction is queued or running.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Speed up a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Replacement request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ReplaceTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.TransactionJobResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tss/sessions/{id}": {
+            "get": {
+                "description": "Get the status of a keygen, sign or reshare session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tss"
+                ],
+                "summary": "Get TSS session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.TSSSessionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "get": {
+                "description": "Get user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/audit-events": {
+            "get": {
+                "description": "List backup exports and recovery attempts of a wallet, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Get wallet audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.WalletAuditEventResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/backup": {
+            "post": {
+                "description": "Check the client share with a signing session, then encrypt it with a password (Argon2id, AES-256-GCM) and return a versioned backup file. Rate limited and audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Export share backup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Client share and backup password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ExportBackupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/backup.File"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/recover": {
+            "post": {
+                "description": "Decrypt a share backup and reshare the wallet with it, returning a fresh client share. A backup made before the last refresh fails with 409 BACKUP_STALE. Older backups stop working afterwards. Rate limited and audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Recover wallet from backup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Backup file and password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RecoverWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.RefreshSharesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/refresh-shares": {
+            "post": {
+                "description": "Rotate the key shares of a wallet without changing its address and return the new client share. Bumps the share version, so existing backups stop working; export a new one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Refresh key shares",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Current client share",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RefreshSharesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.RefreshSharesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/sign-message": {
+            "post": {
+                "description": "Sign a message with the EIP-191 personal_sign prefix. Messages starting with 0x are signed as hex decoded bytes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Sign message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message to sign",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SignMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.SignatureResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/sign-typed-data": {
+            "post": {
+                "description": "Sign EIP-712 typed data. The domain must declare a chainId of a supported chain",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Sign typed data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Typed data to sign",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SignTypedDataRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/model.SignatureResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "backup.File": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+                },
+                "checksum": {
+                    "type": "string"
+                },
+                "cipher": {
+                    "type": "string",
+                    "example": "aes-256-gcm"
+                },
+                "ciphertext": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "kdf": {
+                    "$ref": "#/definitions/backup.KDF"
+                },
+                "key_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "nonce": {
+                    "type": "string"
+                },
+                "share_version": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "wallet_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "backup.KDF": {
+            "type": "object",
+            "properties": {
+                "memory": {
+                    "type": "integer",
+                    "example": 65536
+                },
+                "name": {
+                    "type": "string",
+                    "example": "argon2id"
+                },
+                "salt": {
+                    "type": "string"
+                },
+                "threads": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "time": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "model.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.UserResponse"
+                },
+                "wallet": {
+                    "$ref": "#/definitions/model.WalletResponse"
+                }
+            }
+        },
+        "model.BatchTransfer": {
+            "type": "object",
+            "required": [
+                "amount",
+                "symbol",
+                "to_address"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "to_address": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ChainResponse": {
+            "type": "object",
+            "properties": {
+                "chain_id": {
+                    "type": "integer",
+                    "example": 11155111
+                },
+                "explorer_url": {
+                    "type": "string",
+                    "example": "https://sepolia.etherscan.io"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Sepolia"
+                },
+                "native_currency": {
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "rpc_url": {
+                    "type": "string",
+                    "example": "https://ethereum-sepolia-rpc.publicnode.com"
+                }
+            }
+        },
+        "model.CreateAndSubmitTransactionRequest": {
+            "type": "object",
             "required": [
                 "amount",
                 "chain_id",
+                "from_address",
                 "symbol",
-                "token_id",
-                "wallet_id"
+                "to_address"
             ],
             "properties": {
                 "amount": {
@@ -603,14 +1537,80 @@ const docTemplate = `{
                 "chain_id": {
                     "type": "integer"
                 },
+                "from_address": {
+                    "type": "string"
+                },
+                "share_data": {
+                    "description": "Required unless the wallet is custodial",
+                    "type": "string"
+                },
+                "speed": {
+                    "description": "Defaults to medium",
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high"
+                    ]
+                },
                 "symbol": {
                     "type": "string"
                 },
-                "token_id": {
+                "to_address": {
                     "type": "string"
                 },
-                "wallet_id": {
+                "tx_type": {
+                    "description": "Defaults to eip1559",
+                    "type": "string",
+                    "enum": [
+                        "legacy",
+                        "eip1559"
+                    ]
+                }
+            }
+        },
+        "model.CreateBatchTransactionRequest": {
+            "type": "object",
+            "required": [
+                "chain_id",
+                "from_address",
+                "transfers"
+            ],
+            "properties": {
+                "chain_id": {
+                    "type": "integer"
+                },
+                "from_address": {
                     "type": "string"
+                },
+                "share_data": {
+                    "description": "Required unless the wallet is custodial",
+                    "type": "string"
+                },
+                "speed": {
+                    "description": "Defaults to medium",
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high"
+                    ]
+                },
+                "transfers": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.BatchTransfer"
+                    }
+                },
+                "tx_type": {
+                    "description": "Defaults to eip1559",
+                    "type": "string",
+                    "enum": [
+                        "legacy",
+                        "eip1559"
+                    ]
                 }
             }
         },
@@ -625,6 +1625,34 @@ const docTemplate = `{
                 }
             }
         },
+        "model.ExportBackupRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "share_data"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 12
+                },
+                "share_data": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "OK"
+                },
+                "tss": {
+                    "$ref": "#/definitions/model.TSSHealth"
+                }
+            }
+        },
         "model.LoginRequest": {
             "type": "object",
             "required": [
@@ -640,6 +1668,80 @@ const docTemplate = `{
                 }
             }
         },
+        "model.PartyHealth": {
+            "type": "object",
+            "properties": {
+                "alive": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "party": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.QuoteTransactionRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "chain_id",
+                "from_address",
+                "symbol",
+                "to_address"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "chain_id": {
+                    "type": "integer"
+                },
+                "from_address": {
+                    "type": "string"
+                },
+                "speed": {
+                    "description": "Defaults to medium",
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high"
+                    ]
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "to_address": {
+                    "type": "string"
+                },
+                "tx_type": {
+                    "description": "Defaults to eip1559",
+                    "type": "string",
+                    "enum": [
+                        "legacy",
+                        "eip1559"
+                    ]
+                }
+            }
+        },
+        "model.RecoverWalletRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "backup": {
+                    "$ref": "#/definitions/backup.File"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Refresh": {
             "type": "object",
             "required": [
@@ -662,12 +1764,100 @@ const docTemplate = `{
                 }
             }
         },
+        "model.RefreshSharesRequest": {
+            "type": "object",
+            "properties": {
+                "share_data": {
+                    "description": "ShareData is required unless the wallet is custodial",
+                    "type": "string"
+                }
+            }
+        },
+        "model.RefreshSharesResponse": {
+            "type": "object",
+            "properties": {
+                "share_data": {
+                    "type": "string"
+                },
+                "wallet": {
+                    "$ref": "#/definitions/model.WalletResponse"
+                }
+            }
+        },
+        "model.ReplaceTransactionRequest": {
+            "type": "object",
+            "properties": {
+                "share_data": {
+                    "description": "Required unless the wallet is custodial",
+                    "type": "string"
+                },
+                "speed": {
+                    "description": "Defaults to medium, fees rise at least 10% either way",
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "medium",
+                        "high"
+                    ]
+                }
+            }
+        },
         "model.Response": {
             "type": "object",
             "properties": {
                 "payload": {}
             }
         },
+        "model.SignMessageRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "description": "Message is signed as UTF-8 text, or as raw bytes when it is 0x prefixed hex",
+                    "type": "string",
+                    "example": "Sign in to Example dapp"
+                },
+                "share_data": {
+                    "description": "ShareData is required unless the wallet is custodial",
+                    "type": "string"
+                }
+            }
+        },
+        "model.SignTypedDataRequest": {
+            "type": "object",
+            "required": [
+                "typed_data"
+            ],
+            "properties": {
+                "share_data": {
+                    "description": "ShareData is required unless the wallet is custodial",
+                    "type": "string"
+                },
+                "typed_data": {
+                    "description": "TypedData is an EIP-712 payload with types, primaryType, domain and message",
+                    "type": "object"
+                }
+            }
+        },
+        "model.SignatureResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+                },
+                "hash": {
+                    "type": "string",
+                    "example": "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+                },
+                "signature": {
+                    "type": "string",
+                    "example": "0x6e0d2e0c8c0e5d4f0c1a3b3f...1b"
+                }
+            }
+        },
         "model.SignupRequest": {
             "type": "object",
             "required": [
@@ -678,8 +1868,123 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
-                "password": {
+                "parties": {
+                    "description": "Parties holding shares of the wallet, one of TSS_TOPOLOGIES, defaults to TSS_PARTIES",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "password": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "description": "Parties needed to sign, defaults to TSS_THRESHOLD",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.SignupResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "share_data": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.UserResponse"
+                },
+                "wallet": {
+                    "$ref": "#/definitions/model.WalletResponse"
+                }
+            }
+        },
+        "model.TSSHealth": {
+            "type": "object",
+            "properties": {
+                "alive": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "error": {
+                    "type": "string"
+                },
+                "parties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PartyHealth"
+                    }
+                },
+                "quorum": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "threshold": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.TSSSessionResponse": {
+            "type": "object",
+            "properties": {
+                "ended_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "TSS_PARTY_UNAVAILABLE"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "message_hash": {
+                    "type": "string",
+                    "example": "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+                },
+                "parties": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "started_at": {
                     "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "threshold": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "type": {
+                    "type": "string",
+                    "example": "sign"
+                },
+                "wallet_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
@@ -761,26 +2066,237 @@ const docTemplate = `{
         "model.Transaction": {
             "type": "object",
             "properties": {
+                "amount": {
+                    "description": "Base units of the token transferred",
+                    "type": "string"
+                },
+                "block_hash": {
+                    "type": "string"
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "chain_id": {
+                    "type": "integer"
+                },
+                "confirmations": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "effective_gas_price": {
+                    "description": "Wei",
+                    "type": "string"
+                },
+                "fee": {
+                    "description": "Wei paid for gas, once mined",
+                    "type": "string"
+                },
+                "from_address": {
+                    "type": "string"
+                },
+                "gas_used": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "integer"
+                },
+                "replaces_id": {
+                    "description": "Transaction a speed-up or cancel replaces",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "to_address": {
+                    "type": "string"
+                },
+                "token_id": {
+                    "type": "string"
+                },
+                "tx_hash": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Wei sent with the transaction, 0 for a token transfer",
+                    "type": "string"
+                }
+            }
+        },
+        "model.TransactionBatchResponse": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TransactionJobResponse"
+                    }
+                }
+            }
+        },
+        "model.TransactionHistoryItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Base units of the token transferred",
+                    "type": "string"
+                },
+                "block_hash": {
+                    "type": "string"
+                },
+                "block_number": {
+                    "type": "integer"
+                },
                 "chain_id": {
                     "type": "integer"
                 },
+                "confirmations": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "direction": {
+                    "type": "string",
+                    "example": "outgoing"
+                },
+                "effective_gas_price": {
+                    "description": "Wei",
+                    "type": "string"
+                },
+                "explorer_url": {
+                    "type": "string",
+                    "example": "https://sepolia.etherscan.io/tx/0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+                },
+                "fee": {
+                    "description": "Wei paid for gas, once mined",
+                    "type": "string"
+                },
+                "formatted_amount": {
+                    "type": "string",
+                    "example": "10.5"
+                },
+                "formatted_fee": {
+                    "type": "string",
+                    "example": "0.000021"
+                },
                 "from_address": {
                     "type": "string"
                 },
+                "gas_used": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "string"
                 },
+                "native_symbol": {
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "nonce": {
+                    "type": "integer"
+                },
+                "replaces_id": {
+                    "description": "Transaction a speed-up or cancel replaces",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "USDT"
+                },
                 "to_address": {
                     "type": "string"
                 },
+                "token_id": {
+                    "type": "string"
+                },
                 "tx_hash": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "value": {
+                    "description": "Wei sent with the transaction, 0 for a token transfer",
+                    "type": "string"
+                }
+            }
+        },
+        "model.TransactionJobResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "0.01"
+                },
+                "batch_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 11155111
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "TSS_PARTY_UNAVAILABLE"
+                },
+                "from_address": {
+                    "type": "string",
+                    "example": "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "replaces_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "queued"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "to_address": {
+                    "type": "string",
+                    "example": "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/model.Transaction"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "transfer"
                 }
             }
         },
@@ -802,11 +2318,104 @@ const docTemplate = `{
                 "transactions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Transaction"
+                        "$ref": "#/definitions/model.TransactionHistoryItem"
                     }
                 }
             }
         },
+        "model.TransactionQuoteResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "10"
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 11155111
+                },
+                "data": {
+                    "type": "string",
+                    "example": "0xa9059cbb"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+                },
+                "gas_limit": {
+                    "type": "integer",
+                    "example": 25200
+                },
+                "gas_price": {
+                    "description": "Wei, legacy transactions only",
+                    "type": "string",
+                    "example": "2000000000"
+                },
+                "max_fee": {
+                    "type": "string",
+                    "example": "0.0000756"
+                },
+                "max_fee_per_gas": {
+                    "description": "Wei, eip1559 transactions only",
+                    "type": "string",
+                    "example": "3000000000"
+                },
+                "max_priority_fee_per_gas": {
+                    "description": "Wei, eip1559 transactions only",
+                    "type": "string",
+                    "example": "1000000000"
+                },
+                "native_symbol": {
+                    "description": "Costs in whole units. MaxFee is the gas limit at the max fee, the most\nthe transaction can pay; TotalNative adds the native value sent.",
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "nonce": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "speed": {
+                    "type": "string",
+                    "example": "medium"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "USDT"
+                },
+                "to": {
+                    "description": "To is the token contract for a token transfer",
+                    "type": "string",
+                    "example": "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+                },
+                "total_native": {
+                    "type": "string",
+                    "example": "0.0100756"
+                },
+                "tx_type": {
+                    "type": "string",
+                    "example": "eip1559"
+                },
+                "unsigned_tx": {
+                    "description": "Hex encoded, as signed by the wallet",
+                    "type": "string",
+                    "example": "0x02f0"
+                },
+                "value": {
+                    "description": "Wei",
+                    "type": "string",
+                    "example": "10000000000000000"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "INSUFFICIENT_BALANCE"
+                    ]
+                }
+            }
+        },
         "model.UserResponse": {
             "type": "object",
             "properties": {
@@ -820,6 +2429,37 @@ const docTemplate = `{
                 }
             }
         },
+        "model.WalletAuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "recover"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "BACKUP_WRONG_PASSWORD"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "ip_address": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "succeeded"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
         "model.WalletResponse": {
             "type": "object",
             "properties": {
@@ -827,6 +2467,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "0x0000000000000000000000000000000000000000"
                 },
+                "custodial": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "id": {
                     "type": "string",
                     "example": "123e4567-e89b-12d3-a456-426614174000"
@@ -835,6 +2479,26 @@ const docTemplate = `{
                     "type": "string",
                     "example": "My Wallet"
                 },
+                "parties": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "share_version": {
+                    "description": "ShareVersion is bumped by every share refresh and recovery. Backups\nmade for an older version are rejected.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "threshold": {
+                    "type": "integer",
+                    "example": 2
+                },
                 "user_id": {
                     "type": "string",
                     "example": "123e4567-e89b-12d3-a456-426614174000"
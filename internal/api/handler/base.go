@@ -36,3 +36,10 @@ func (h *BaseHandler) SuccessResponse(c *gin.Context, data interface{}) {
 		"payload": data,
 	})
 }
+
+// AcceptedResponse send response for work that continues in the background
+func (h *BaseHandler) AcceptedResponse(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, gin.H{
+		"payload": data,
+	})
+}
@@ -1,25 +1,31 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"mpc/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
 
 type HealthHandler struct {
 	BaseHandler
+	healthService *service.HealthService
 }
 
-func NewHealthHandler() *HealthHandler {
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
 	return &HealthHandler{
-		BaseHandler: NewBaseHandler(),
+		BaseHandler:   NewBaseHandler(),
+		healthService: healthService,
 	}
 }
 
 // HealthCheck godoc
 // @Summary      Health check
-// @Description  Check if the server is running
+// @Description  Check if the server is running and which MPC parties are alive. The message is DEGRADED when fewer parties than the signing threshold send heartbeats.
 // @Tags         health
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  model.Response{payload=map[string]string}
+// @Success      200  {object}  model.Response{payload=model.HealthResponse}
 // @Router       /health [get]
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
-	h.SuccessResponse(c, gin.H{"message": "OK"})
+	h.SuccessResponse(c, h.healthService.Check(c.Request.Context()))
 }
@@ -3,10 +3,12 @@ package handler
 import (
 	"mpc/internal/model"
 	"mpc/internal/service"
+	"mpc/pkg/errors"
 	"mpc/pkg/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type TransactionHandler struct {
@@ -55,13 +57,17 @@ func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 
 // CreateAndSubmitTransaction godoc
 // @Summary      Create and submit transaction
-// @Description  Create and submit transaction
+// @Description  Queue a transaction for signing and broadcasting, poll the returned job for the result
 // @Tags         transactions
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "Retries with the same key return the job of the first request"
 // @Param        request body model.CreateAndSubmitTransactionRequest true "Transaction request"
-// @Success      200  {object}  model.Response{payload=model.Transaction}
+// @Success      202  {object}  model.Response{payload=model.TransactionJobResponse}
 // @Failure      400  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      422  {object}  model.ErrorResponse
+// @Failure      503  {object}  model.ErrorResponse
 // @Router       /transactions [post]
 func (h *TransactionHandler) CreateAndSubmitTransaction(c *gin.Context) {
 	userID, err := h.GetUserID(c)
@@ -69,16 +75,216 @@ func (h *TransactionHandler) CreateAndSubmitTransaction(c *gin.Context) {
 		c.Error(err)
 		return
 	}
+	idempotencyKey, err := h.idempotencyKey(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	var req model.CreateAndSubmitTransactionRequest
 	if err := utils.ValidateBody(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
 
-	res, err := h.txnService.CreateAndSubmitTransaction(c.Request.Context(), userID, req)
+	res, err := h.txnService.CreateAndSubmitTransaction(c.Request.Context(), userID, idempotencyKey, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Location", "/api/v1/transactions/jobs/"+res.ID.String())
+	h.AcceptedResponse(c, res)
+}
+
+// CreateBatchTransaction godoc
+// @Summary      Create and submit a batch of transactions
+// @Description  Queue several transfers from one wallet, signed in a single TSS session and broadcast in order. Every transfer gets its own job.
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key header string false "Retries with the same key return the jobs of the first request"
+// @Param        request body model.CreateBatchTransactionRequest true "Batch request"
+// @Success      202  {object}  model.Response{payload=model.TransactionBatchResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      422  {object}  model.ErrorResponse
+// @Failure      503  {object}  model.ErrorResponse
+// @Router       /transactions/batch [post]
+func (h *TransactionHandler) CreateBatchTransaction(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	idempotencyKey, err := h.idempotencyKey(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var req model.CreateBatchTransactionRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := h.txnService.CreateBatchTransaction(c.Request.Context(), userID, idempotencyKey, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.AcceptedResponse(c, res)
+}
+
+// QuoteTransaction godoc
+// @Summary      Quote a transaction
+// @Description  Build the unsigned transaction a send would make and return its fees and total cost, without signing or broadcasting. Problems such as an insufficient balance or a contract recipient are returned as warnings.
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        request body model.QuoteTransactionRequest true "Quote request"
+// @Success      200  {object}  model.Response{payload=model.TransactionQuoteResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Router       /transactions/quote [post]
+func (h *TransactionHandler) QuoteTransaction(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var req model.QuoteTransactionRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := h.txnService.QuoteTransaction(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
+
+// SpeedUpTransaction godoc
+// @Summary      Speed up a transaction
+// @Description  Queue a replacement of a pending transaction with the same transfer and nonce at higher fees, signed in a new TSS session. Fees rise at least 10% over the original. Poll the returned job for the replacement; the original is marked replaced once it is broadcast. Fails with 409 while another speed-up or cancel of the transaction is queued or running.
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Transaction ID"
+// @Param        request body model.ReplaceTransactionRequest true "Replacement request"
+// @Success      202  {object}  model.Response{payload=model.TransactionJobResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      503  {object}  model.ErrorResponse
+// @Router       /transactions/{id}/speed-up [post]
+func (h *TransactionHandler) SpeedUpTransaction(c *gin.Context) {
+	userID, txnID, req, ok := h.replaceRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.txnService.SpeedUpTransaction(c.Request.Context(), userID, txnID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Location", "/api/v1/transactions/jobs/"+res.ID.String())
+	h.AcceptedResponse(c, res)
+}
+
+// CancelTransaction godoc
+// @Summary      Cancel a transaction
+// @Description  Queue a replacement of a pending transaction with a zero value transfer to the sender itself using the same nonce, signed in a new TSS session. Fees rise at least 10% over the original. Poll the returned job for the replacement; the original is marked replaced once it is broadcast. Fails with 409 while another speed-up or cancel of the transaction is queued or running.
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Transaction ID"
+// @Param        request body model.ReplaceTransactionRequest true "Replacement request"
+// @Success      202  {object}  model.Response{payload=model.TransactionJobResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      503  {object}  model.ErrorResponse
+// @Router       /transactions/{id}/cancel [post]
+func (h *TransactionHandler) CancelTransaction(c *gin.Context) {
+	userID, txnID, req, ok := h.replaceRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.txnService.CancelTransaction(c.Request.Context(), userID, txnID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Location", "/api/v1/transactions/jobs/"+res.ID.String())
+	h.AcceptedResponse(c, res)
+}
+
+// replaceRequest reads the user, transaction ID and body of a speed-up or
+// cancel. It reports false after recording the error.
+func (h *TransactionHandler) replaceRequest(c *gin.Context) (uuid.UUID, uuid.UUID, model.ReplaceTransactionRequest, bool) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return uuid.Nil, uuid.Nil, model.ReplaceTransactionRequest{}, false
+	}
+
+	txnID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidTransactionID)
+		return uuid.Nil, uuid.Nil, model.ReplaceTransactionRequest{}, false
+	}
+
+	var req model.ReplaceTransactionRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return uuid.Nil, uuid.Nil, model.ReplaceTransactionRequest{}, false
+	}
+	return userID, txnID, req, true
+}
+
+// GetJob godoc
+// @Summary      Get transaction job
+// @Description  Get the status of a queued transaction and the transaction once it is broadcast
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Job ID"
+// @Success      200  {object}  model.Response{payload=model.TransactionJobResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /transactions/jobs/{id} [get]
+func (h *TransactionHandler) GetJob(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidTransactionJobID)
+		return
+	}
+
+	res, err := h.txnService.GetJob(c.Request.Context(), userID, jobID)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 	h.SuccessResponse(c, res)
 }
+
+// idempotencyKey returns the optional Idempotency-Key header of a submission
+func (h *TransactionHandler) idempotencyKey(c *gin.Context) (string, error) {
+	key, ok := c.Request.Header["Idempotency-Key"]
+	if !ok {
+		return "", nil
+	}
+	if len(key) != 1 || key[0] == "" || len(key[0]) > 255 {
+		return "", errors.ErrInvalidIdempotencyKey
+	}
+	return key[0], nil
+}
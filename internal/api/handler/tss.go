@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"mpc/internal/service"
+	"mpc/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TSSHandler struct {
+	BaseHandler
+	sessionService *service.TSSSessionService
+}
+
+func NewTSSHandler(sessionService *service.TSSSessionService) *TSSHandler {
+	return &TSSHandler{
+		BaseHandler:    NewBaseHandler(),
+		sessionService: sessionService,
+	}
+}
+
+// GetSession godoc
+// @Summary      Get TSS session
+// @Description  Get the status of a keygen, sign or reshare session
+// @Tags         tss
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Session ID"
+// @Success      200  {object}  model.Response{payload=model.TSSSessionResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /tss/sessions/{id} [get]
+func (h *TSSHandler) GetSession(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidTSSSessionID)
+		return
+	}
+
+	res, err := h.sessionService.GetSession(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"mpc/internal/model"
+	"mpc/internal/service"
+	"mpc/pkg/errors"
+	"mpc/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WalletHandler struct {
+	BaseHandler
+	walletService  *service.WalletService
+	signingService *service.SigningService
+	backupService  *service.BackupService
+}
+
+func NewWalletHandler(
+	walletService *service.WalletService,
+	signingService *service.SigningService,
+	backupService *service.BackupService,
+) *WalletHandler {
+	return &WalletHandler{
+		BaseHandler:    NewBaseHandler(),
+		walletService:  walletService,
+		signingService: signingService,
+		backupService:  backupService,
+	}
+}
+
+// RefreshShares godoc
+// @Summary      Refresh key shares
+// @Description  Rotate the key shares of a wallet without changing its address and return the new client share. Bumps the share version, so existing backups stop working; export a new one.
+// @Tags         wallets
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body model.RefreshSharesRequest true "Current client share"
+// @Success      200  {object}  model.Response{payload=model.RefreshSharesResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /wallets/{id}/refresh-shares [post]
+func (h *WalletHandler) RefreshShares(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	walletID, err := h.parseWalletID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.RefreshSharesRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := h.walletService.RefreshShares(c.Request.Context(), userID, walletID, req.ShareData)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
+
+// SignMessage godoc
+// @Summary      Sign message
+// @Description  Sign a message with the EIP-191 personal_sign prefix. Messages starting with 0x are signed as hex decoded bytes
+// @Tags         wallets
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body model.SignMessageRequest true "Message to sign"
+// @Success      200  {object}  model.Response{payload=model.SignatureResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /wallets/{id}/sign-message [post]
+func (h *WalletHandler) SignMessage(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	walletID, err := h.parseWalletID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.SignMessageRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := h.signingService.SignMessage(c.Request.Context(), userID, walletID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
+
+// SignTypedData godoc
+// @Summary      Sign typed data
+// @Description  Sign EIP-712 typed data. The domain must declare a chainId of a supported chain
+// @Tags         wallets
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body model.SignTypedDataRequest true "Typed data to sign"
+// @Success      200  {object}  model.Response{payload=model.SignatureResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /wallets/{id}/sign-typed-data [post]
+func (h *WalletHandler) SignTypedData(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	walletID, err := h.parseWalletID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.SignTypedDataRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := h.signingService.SignTypedData(c.Request.Context(), userID, walletID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
+
+// ExportBackup godoc
+// @Summary      Export share backup
+// @Description  Check the client share with a signing session, then encrypt it with a password (Argon2id, AES-256-GCM) and return a versioned backup file. Rate limited and audited.
+// @Tags         wallets
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body model.ExportBackupRequest true "Client share and backup password"
+// @Success      200  {object}  model.Response{payload=backup.File}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      429  {object}  model.ErrorResponse
+// @Failure      503  {object}  model.ErrorResponse
+// @Router       /wallets/{id}/backup [post]
+func (h *WalletHandler) ExportBackup(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	walletID, err := h.parseWalletID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.ExportBackupRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := h.backupService.ExportBackup(c.Request.Context(), userID, walletID, req, h.requestMeta(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
+
+// RecoverWallet godoc
+// @Summary      Recover wallet from backup
+// @Description  Decrypt a share backup and reshare the wallet with it, returning a fresh client share. A backup made before the last refresh fails with 409 BACKUP_STALE. Older backups stop working afterwards. Rate limited and audited.
+// @Tags         wallets
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        request body model.RecoverWalletRequest true "Backup file and password"
+// @Success      200  {object}  model.Response{payload=model.RefreshSharesResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Failure      409  {object}  model.ErrorResponse
+// @Failure      429  {object}  model.ErrorResponse
+// @Router       /wallets/{id}/recover [post]
+func (h *WalletHandler) RecoverWallet(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	walletID, err := h.parseWalletID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.RecoverWalletRequest
+	if err := utils.ValidateBody(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	res, err := h.backupService.RecoverWallet(c.Request.Context(), userID, walletID, req, h.requestMeta(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
+
+// GetAuditEvents godoc
+// @Summary      Get wallet audit events
+// @Description  List backup exports and recovery attempts of a wallet, newest first
+// @Tags         wallets
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Wallet ID"
+// @Param        page query int false "Page"
+// @Param        page_size query int false "Page size"
+// @Success      200  {object}  model.Response{payload=[]model.WalletAuditEventResponse}
+// @Failure      400  {object}  model.ErrorResponse
+// @Failure      404  {object}  model.ErrorResponse
+// @Router       /wallets/{id}/audit-events [get]
+func (h *WalletHandler) GetAuditEvents(c *gin.Context) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	walletID, err := h.parseWalletID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	res, err := h.backupService.GetAuditEvents(c.Request.Context(), userID, walletID, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.SuccessResponse(c, res)
+}
+
+// Helper methods
+func (h *WalletHandler) parseWalletID(c *gin.Context) (uuid.UUID, error) {
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, errors.ErrInvalidWallet
+	}
+	return walletID, nil
+}
+
+func (h *WalletHandler) requestMeta(c *gin.Context) model.RequestMeta {
+	return model.RequestMeta{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
@@ -35,6 +35,7 @@ func DefaultCorsConfig() CorsConfig {
 			"Accept",
 			"Cache-Control",
 			"X-Requested-With",
+			"Idempotency-Key",
 		},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
@@ -17,7 +17,12 @@ func NewRouter(
 	authService *service.AuthService,
 	assetService *service.AssetService,
 	userService *service.UserService,
+	walletService *service.WalletService,
 	txnService *service.TransactionService,
+	signingService *service.SigningService,
+	backupService *service.BackupService,
+	sessionService *service.TSSSessionService,
+	healthService *service.HealthService,
 	tokenManager *token.TokenManager,
 ) *gin.Engine {
 	// Disable default logger
@@ -32,11 +37,13 @@ func NewRouter(
 	router.Use(middleware.ErrorHandler())
 	router.Use(gin.Recovery())
 
-	healthHandler := handler.NewHealthHandler()
+	healthHandler := handler.NewHealthHandler(healthService)
 	authHandler := handler.NewAuthHandler(authService)
 	assetHandler := handler.NewAssetHandler(assetService)
 	userHandler := handler.NewUserHandler(userService)
+	walletHandler := handler.NewWalletHandler(walletService, signingService, backupService)
 	txnHandler := handler.NewTransactionHandler(txnService)
+	tssHandler := handler.NewTSSHandler(sessionService)
 
 	v1 := router.Group("/api/v1")
 	{
@@ -63,11 +70,33 @@ func NewRouter(
 			users.GET("/me", userHandler.GetUser)
 		}
 
+		wallets := v1.Group("/wallets")
+		wallets.Use(middleware.AuthMiddleware(tokenManager))
+		{
+			wallets.POST("/:id/refresh-shares", walletHandler.RefreshShares)
+			wallets.POST("/:id/sign-message", walletHandler.SignMessage)
+			wallets.POST("/:id/sign-typed-data", walletHandler.SignTypedData)
+			wallets.POST("/:id/backup", walletHandler.ExportBackup)
+			wallets.POST("/:id/recover", walletHandler.RecoverWallet)
+			wallets.GET("/:id/audit-events", walletHandler.GetAuditEvents)
+		}
+
 		transactions := v1.Group("/transactions")
 		transactions.Use(middleware.AuthMiddleware(tokenManager))
 		{
 			transactions.GET("", txnHandler.GetTransactions)
 			transactions.POST("/", txnHandler.CreateAndSubmitTransaction)
+			transactions.POST("/batch", txnHandler.CreateBatchTransaction)
+			transactions.POST("/quote", txnHandler.QuoteTransaction)
+			transactions.POST("/:id/speed-up", txnHandler.SpeedUpTransaction)
+			transactions.POST("/:id/cancel", txnHandler.CancelTransaction)
+			transactions.GET("/jobs/:id", txnHandler.GetJob)
+		}
+
+		tss := v1.Group("/tss")
+		tss.Use(middleware.AuthMiddleware(tokenManager))
+		{
+			tss.GET("/sessions/:id", tssHandler.GetSession)
 		}
 
 		// Redirect to swagger docs
@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+// BackupConfig limits share backup exports and wallet recoveries per user
+type BackupConfig struct {
+	ExportLimit    int           `env:"BACKUP_EXPORT_LIMIT" envDefault:"10"`
+	ExportWindow   time.Duration `env:"BACKUP_EXPORT_WINDOW" envDefault:"1h"`
+	RecoveryLimit  int           `env:"BACKUP_RECOVERY_LIMIT" envDefault:"5"`
+	RecoveryWindow time.Duration `env:"BACKUP_RECOVERY_WINDOW" envDefault:"1h"`
+}
@@ -12,6 +12,14 @@ type Config struct {
 	DB          DBConfig
 	Redis       RedisConfig
 	Eth         EthConfig
+	TSS         TSSConfig
+	Custody     CustodyConfig
+	Txn         TxnConfig
+	Nonce       NonceConfig
+	Idempotency IdempotencyConfig
+	MPCNode     MPCNodeConfig
+	Backup      BackupConfig
+	Metrics     MetricsConfig
 	OauthClient GoogleOAuthClient
 	CORS        struct {
 		AllowOrigins     []string `envconfig:"CORS_ALLOW_ORIGINS" default:"*"`
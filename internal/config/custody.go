@@ -0,0 +1,8 @@
+package config
+
+type CustodyConfig struct {
+	Enabled     bool   `env:"CUSTODY_ENABLED" envDefault:"false"`
+	KEKProvider string `env:"CUSTODY_KEK_PROVIDER" envDefault:"env"`
+	KEK         string `env:"CUSTODY_KEK"`
+	KEKFile     string `env:"CUSTODY_KEK_FILE"`
+}
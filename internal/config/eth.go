@@ -1,5 +1,6 @@
 package config
 
 type EthConfig struct {
-	URL string `env:"ETH_URL" envDefault:"wss://sepolia.infura.io/ws/v3/6c89fb7fa351451f939eea9da6bee755"`
+	// GasLimitMargin is the percentage added to estimated gas limits
+	GasLimitMargin uint64 `env:"ETH_GAS_LIMIT_MARGIN" envDefault:"20"`
 }
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// IdempotencyConfig sets how long the Idempotency-Key of a transaction
+// submission is remembered
+type IdempotencyConfig struct {
+	// KeyTTL is the window a retry with the same key gets the original jobs
+	KeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	// ClaimTTL frees a key whose request died before queuing its transfers,
+	// it must outlast the validation of a request
+	ClaimTTL time.Duration `env:"IDEMPOTENCY_CLAIM_TTL" envDefault:"5m"`
+}
@@ -0,0 +1,7 @@
+package config
+
+// MetricsConfig is the internal listener serving the expvar metrics, apart
+// from the public API. It is disabled when Address is empty.
+type MetricsConfig struct {
+	Address string `env:"METRICS_ADDRESS" envDefault:"127.0.0.1:9090"`
+}
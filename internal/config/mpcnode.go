@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+type MPCNodeConfig struct {
+	ListenAddress string `env:"MPCNODE_LISTEN_ADDRESS" envDefault:":50051"`
+	// InsecureDealer must be set to run the node, which is a trusted dealer
+	// and not threshold ECDSA
+	InsecureDealer bool `env:"MPCNODE_INSECURE_DEALER" envDefault:"false"`
+	// StateFile keeps the node shares across restarts, encrypted with the hex
+	// encoded 32 byte StateKey. Shares are only kept in memory when it is empty.
+	StateFile string `env:"MPCNODE_STATE_FILE"`
+	StateKey  string `env:"MPCNODE_STATE_KEY"`
+	// Parties are the party IDs the node publishes heartbeats for
+	Parties           []uint32      `env:"MPCNODE_PARTIES" envDefault:"1,2,3"`
+	HeartbeatInterval time.Duration `env:"MPCNODE_HEARTBEAT_INTERVAL" envDefault:"5s"`
+	// TLS is enabled with a certificate; clients must present a certificate
+	// signed by TLSClientCAFile when it is set
+	TLSCertFile     string `env:"MPCNODE_TLS_CERT_FILE"`
+	TLSKeyFile      string `env:"MPCNODE_TLS_KEY_FILE"`
+	TLSClientCAFile string `env:"MPCNODE_TLS_CLIENT_CA_FILE"`
+	// NodeID and ResultKey authenticate published results, see TSS_RESULT_KEYS
+	NodeID    string `env:"MPCNODE_ID" envDefault:"mpcnode"`
+	ResultKey string `env:"MPCNODE_RESULT_KEY"`
+}
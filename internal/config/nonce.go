@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+// NonceConfig tunes the nonce reservations of sending wallets
+type NonceConfig struct {
+	// ReservationTTL frees a reserved nonce that was never sent or released,
+	// it must outlast TXN_JOB_TIMEOUT
+	ReservationTTL time.Duration `env:"NONCE_RESERVATION_TTL" envDefault:"15m"`
+	LockTTL        time.Duration `env:"NONCE_LOCK_TTL" envDefault:"30s"`
+	LockWait       time.Duration `env:"NONCE_LOCK_WAIT" envDefault:"10s"`
+}
@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+type TSSConfig struct {
+	Mode        string   `env:"TSS_MODE" envDefault:"grpc"`
+	GRPCAddress string   `env:"TSS_GRPC_ADDRESS" envDefault:"localhost:50051"`
+	Parties     []uint32 `env:"TSS_PARTIES" envDefault:"1,2,3"`
+	Threshold   uint32   `env:"TSS_THRESHOLD" envDefault:"2"`
+	// Topologies are the ones new wallets may request besides the default, as
+	// threshold:parties entries, e.g. 2:1,2;3:1,2,3,4,5. Their parties must
+	// all be among Parties.
+	Topologies   []string      `env:"TSS_TOPOLOGIES" envSeparator:";"`
+	Retries      int           `env:"TSS_RETRIES" envDefault:"2"`
+	RetryBackoff time.Duration `env:"TSS_RETRY_BACKOFF" envDefault:"2s"`
+	SoftwareSeed string        `env:"TSS_SOFTWARE_SEED"`
+	// QuorumCheck fails sessions fast when fewer parties than needed have a
+	// heartbeat newer than HeartbeatTimeout
+	QuorumCheck      bool          `env:"TSS_QUORUM_CHECK" envDefault:"true"`
+	HeartbeatTimeout time.Duration `env:"TSS_HEARTBEAT_TIMEOUT" envDefault:"15s"`
+	// TLSMode is insecure, tls or mtls. mtls presents TLSCertFile, which is
+	// reloaded when it changes on disk.
+	TLSMode       string `env:"TSS_TLS_MODE" envDefault:"insecure"`
+	TLSCAFile     string `env:"TSS_TLS_CA_FILE"`
+	TLSCertFile   string `env:"TSS_TLS_CERT_FILE"`
+	TLSKeyFile    string `env:"TSS_TLS_KEY_FILE"`
+	TLSServerName string `env:"TSS_TLS_SERVER_NAME"`
+	// ResultKeys are the hex HMAC keys of the nodes allowed to publish
+	// results, as node:key pairs. Results are not verified when empty.
+	ResultKeys map[string]string `env:"TSS_RESULT_KEYS"`
+}
@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+type TxnConfig struct {
+	JobWorkers   int           `env:"TXN_JOB_WORKERS" envDefault:"4"`
+	JobQueueSize int           `env:"TXN_JOB_QUEUE_SIZE" envDefault:"100"`
+	JobTimeout   time.Duration `env:"TXN_JOB_TIMEOUT" envDefault:"10m"`
+	// InstanceID identifies this API instance as the owner of the jobs it
+	// queues. It must be unique among running instances and defaults to the
+	// hostname.
+	InstanceID string `env:"TXN_INSTANCE_ID"`
+	// JobLease is how long the jobs of an instance stay unfinished after it
+	// last renewed them. Other instances fail them once it runs out.
+	JobLease time.Duration `env:"TXN_JOB_LEASE" envDefault:"1m"`
+	// Confirmations is the number of blocks, counting its own, a transaction
+	// needs before it is confirmed or failed
+	Confirmations int           `env:"TXN_CONFIRMATIONS" envDefault:"12"`
+	TrackInterval time.Duration `env:"TXN_TRACK_INTERVAL" envDefault:"15s"`
+	// DropTimeout is how long a transaction the node does not know is kept
+	// before it is dropped
+	DropTimeout time.Duration `env:"TXN_DROP_TIMEOUT" envDefault:"30m"`
+	// DropBlocks is how many blocks the nonce of a transaction must stay used
+	// by another one, with no receipt for it, before it is dropped
+	DropBlocks uint64 `env:"TXN_DROP_BLOCKS" envDefault:"12"`
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE "wallets" ADD COLUMN "parties" INT[] NOT NULL DEFAULT '{1,2,3}';
+ALTER TABLE "wallets" ADD COLUMN "threshold" INT NOT NULL DEFAULT 2;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "wallets" DROP COLUMN "threshold";
+ALTER TABLE "wallets" DROP COLUMN "parties";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE "tss_sessions" (
+  "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  "user_id" UUID NOT NULL,
+  "wallet_id" UUID,
+  "type" VARCHAR(20) NOT NULL,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'running',
+  "message_hash" VARCHAR(66),
+  "parties" INT[] NOT NULL,
+  "threshold" INT NOT NULL,
+  "error" TEXT,
+  "started_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  "ended_at" TIMESTAMP,
+  "created_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  "updated_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX "idx_tss_sessions_user_id" ON "tss_sessions" ("user_id");
+
+CREATE INDEX "idx_tss_sessions_wallet_id" ON "tss_sessions" ("wallet_id");
+
+ALTER TABLE "tss_sessions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "tss_sessions" ADD FOREIGN KEY ("wallet_id") REFERENCES "wallets" ("id");
+
+-- Keys generated before sessions were unique used the user ID as session ID
+ALTER TABLE "wallets" ADD COLUMN "key_id" VARCHAR(64);
+UPDATE "wallets" SET "key_id" = "user_id"::text;
+ALTER TABLE "wallets" ALTER COLUMN "key_id" SET NOT NULL;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "wallets" DROP COLUMN "key_id";
+DROP TABLE "tss_sessions" CASCADE;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE "transaction_jobs" (
+  "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  "user_id" UUID NOT NULL,
+  "wallet_id" UUID NOT NULL,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'queued',
+  "chain_id" INT NOT NULL,
+  "from_address" VARCHAR(42) NOT NULL,
+  "to_address" VARCHAR(42) NOT NULL,
+  "symbol" VARCHAR(20) NOT NULL,
+  "amount" VARCHAR(78) NOT NULL,
+  "transaction_id" UUID,
+  "error" TEXT,
+  "started_at" TIMESTAMP,
+  "ended_at" TIMESTAMP,
+  "created_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  "updated_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX "idx_transaction_jobs_user_id" ON "transaction_jobs" ("user_id");
+
+CREATE INDEX "idx_transaction_jobs_status" ON "transaction_jobs" ("status");
+
+ALTER TABLE "transaction_jobs" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "transaction_jobs" ADD FOREIGN KEY ("wallet_id") REFERENCES "wallets" ("id");
+
+ALTER TABLE "transaction_jobs" ADD FOREIGN KEY ("transaction_id") REFERENCES "transactions" ("id");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE "transaction_jobs" CASCADE;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
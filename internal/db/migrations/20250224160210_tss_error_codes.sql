@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE "tss_sessions" ADD COLUMN "error_code" VARCHAR(50);
+ALTER TABLE "transaction_jobs" ADD COLUMN "error_code" VARCHAR(50);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "transaction_jobs" DROP COLUMN "error_code";
+ALTER TABLE "tss_sessions" DROP COLUMN "error_code";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE "transaction_jobs" ADD COLUMN "batch_id" UUID;
+
+CREATE INDEX "idx_transaction_jobs_batch_id" ON "transaction_jobs" ("batch_id");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX "idx_transaction_jobs_batch_id";
+ALTER TABLE "transaction_jobs" DROP COLUMN "batch_id";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
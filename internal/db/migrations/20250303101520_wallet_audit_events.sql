@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE "wallet_audit_events" (
+  "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  "user_id" UUID NOT NULL,
+  "wallet_id" UUID NOT NULL,
+  "action" VARCHAR(32) NOT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "error" TEXT,
+  "error_code" VARCHAR(64),
+  "ip_address" VARCHAR(64),
+  "user_agent" TEXT,
+  "created_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX "idx_wallet_audit_events_wallet_id" ON "wallet_audit_events" ("wallet_id", "created_at");
+
+ALTER TABLE "wallet_audit_events" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "wallet_audit_events" ADD FOREIGN KEY ("wallet_id") REFERENCES "wallets" ("id");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE "wallet_audit_events" CASCADE;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Rows created before tokens were recorded are native transfers
+ALTER TABLE "transactions" ADD COLUMN "token_id" UUID;
+
+ALTER TABLE "transactions" ADD FOREIGN KEY ("token_id") REFERENCES "tokens" ("id");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "transactions" DROP COLUMN "token_id";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE "wallet_nonces" (
+  "chain_id" INT NOT NULL,
+  "address" VARCHAR(42) NOT NULL,
+  "nonce" BIGINT NOT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "tx_hash" VARCHAR(66),
+  "reserved_until" TIMESTAMP,
+  "created_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  "updated_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  PRIMARY KEY ("chain_id", "address", "nonce")
+);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE "wallet_nonces" CASCADE;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
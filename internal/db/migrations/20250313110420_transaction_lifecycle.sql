@@ -0,0 +1,30 @@
+-- +goose Up
+-- Rows created before tracking start as submitted and are picked up by the tracker
+ALTER TABLE "transactions" ADD COLUMN "status" VARCHAR(20) NOT NULL DEFAULT 'submitted';
+ALTER TABLE "transactions" ADD COLUMN "nonce" BIGINT;
+ALTER TABLE "transactions" ADD COLUMN "value" VARCHAR(78);
+ALTER TABLE "transactions" ADD COLUMN "block_number" BIGINT;
+ALTER TABLE "transactions" ADD COLUMN "block_hash" VARCHAR(66);
+ALTER TABLE "transactions" ADD COLUMN "gas_used" BIGINT;
+ALTER TABLE "transactions" ADD COLUMN "effective_gas_price" VARCHAR(78);
+ALTER TABLE "transactions" ADD COLUMN "confirmations" INT NOT NULL DEFAULT 0;
+
+CREATE INDEX "idx_transactions_status" ON "transactions" ("status");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX "idx_transactions_status";
+
+ALTER TABLE "transactions" DROP COLUMN "confirmations";
+ALTER TABLE "transactions" DROP COLUMN "effective_gas_price";
+ALTER TABLE "transactions" DROP COLUMN "gas_used";
+ALTER TABLE "transactions" DROP COLUMN "block_hash";
+ALTER TABLE "transactions" DROP COLUMN "block_number";
+ALTER TABLE "transactions" DROP COLUMN "value";
+ALTER TABLE "transactions" DROP COLUMN "nonce";
+ALTER TABLE "transactions" DROP COLUMN "status";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
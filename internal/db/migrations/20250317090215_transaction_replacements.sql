@@ -0,0 +1,14 @@
+-- +goose Up
+-- A speed-up or cancel points to the transaction it replaces, with the same nonce
+ALTER TABLE "transactions" ADD COLUMN "replaces_id" UUID;
+
+ALTER TABLE "transactions" ADD FOREIGN KEY ("replaces_id") REFERENCES "transactions" ("id");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "transactions" DROP COLUMN "replaces_id";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
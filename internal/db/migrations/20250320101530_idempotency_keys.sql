@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE "idempotency_keys" (
+  "user_id" UUID NOT NULL,
+  "key" VARCHAR(255) NOT NULL,
+  "fingerprint" VARCHAR(64) NOT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "batch_id" UUID,
+  "job_ids" UUID[] NOT NULL DEFAULT '{}',
+  "expires_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  "updated_at" TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  PRIMARY KEY ("user_id", "key")
+);
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE "idempotency_keys" CASCADE;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- amount is in base units of the token, fee in Wei once the transaction is mined
+ALTER TABLE "transactions" ADD COLUMN "amount" VARCHAR(78);
+ALTER TABLE "transactions" ADD COLUMN "fee" VARCHAR(78);
+
+CREATE INDEX "idx_transactions_tx_hash" ON "transactions" ("tx_hash");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX "idx_transactions_tx_hash";
+
+ALTER TABLE "transactions" DROP COLUMN "fee";
+ALTER TABLE "transactions" DROP COLUMN "amount";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
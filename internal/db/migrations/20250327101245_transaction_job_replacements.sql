@@ -0,0 +1,16 @@
+-- +goose Up
+-- A job either sends a transfer or replaces a sent transaction with a speed-up or cancel
+ALTER TABLE "transaction_jobs" ADD COLUMN "type" VARCHAR(20) NOT NULL DEFAULT 'transfer';
+ALTER TABLE "transaction_jobs" ADD COLUMN "replaces_id" UUID;
+
+ALTER TABLE "transaction_jobs" ADD FOREIGN KEY ("replaces_id") REFERENCES "transactions" ("id");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "transaction_jobs" DROP COLUMN "replaces_id";
+ALTER TABLE "transaction_jobs" DROP COLUMN "type";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- The tracker pages through the transactions that are not final in creation order
+CREATE INDEX "idx_transactions_tracked" ON "transactions" ("created_at", "id") WHERE "status" IN ('submitted', 'pending');
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX "idx_transactions_tracked";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Head block at which the tracker first found the nonce of a transaction used
+-- by another one while it had no receipt; it is dropped some blocks later
+ALTER TABLE "transactions" ADD COLUMN "nonce_used_block" BIGINT;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "transactions" DROP COLUMN "nonce_used_block";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
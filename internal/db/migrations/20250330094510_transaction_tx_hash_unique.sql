@@ -0,0 +1,26 @@
+-- +goose Up
+-- The worker and the API may both have recorded a transaction: keep the first
+-- row of each hash and point the references to the others at it
+CREATE TEMPORARY TABLE "duplicate_transactions" AS
+SELECT "id", first_value("id") OVER (PARTITION BY "chain_id", "tx_hash" ORDER BY "created_at", "id") AS "kept_id"
+FROM "transactions";
+DELETE FROM "duplicate_transactions" WHERE "id" = "kept_id";
+
+UPDATE "transaction_jobs" AS j SET "transaction_id" = d."kept_id" FROM "duplicate_transactions" AS d WHERE j."transaction_id" = d."id";
+UPDATE "transaction_jobs" AS j SET "replaces_id" = d."kept_id" FROM "duplicate_transactions" AS d WHERE j."replaces_id" = d."id";
+UPDATE "transactions" AS t SET "replaces_id" = d."kept_id" FROM "duplicate_transactions" AS d WHERE t."replaces_id" = d."id";
+DELETE FROM "transactions" AS t USING "duplicate_transactions" AS d WHERE t."id" = d."id";
+DROP TABLE "duplicate_transactions";
+
+DROP INDEX "idx_transactions_tx_hash";
+CREATE UNIQUE INDEX "idx_transactions_chain_tx_hash" ON "transactions" ("chain_id", "tx_hash");
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX "idx_transactions_chain_tx_hash";
+CREATE INDEX "idx_transactions_tx_hash" ON "transactions" ("tx_hash");
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
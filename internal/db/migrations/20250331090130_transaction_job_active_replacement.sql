@@ -0,0 +1,21 @@
+-- +goose Up
+-- Only one speed-up or cancel of a transaction is queued or running at a time.
+-- Fail the later of any left over before the index is created.
+UPDATE "transaction_jobs" AS j
+SET "status" = 'failed', "error" = 'another replacement of the transaction is in progress', "ended_at" = now(), "updated_at" = now()
+WHERE j."replaces_id" IS NOT NULL AND j."status" IN ('queued', 'running') AND EXISTS (
+    SELECT 1 FROM "transaction_jobs" AS o
+    WHERE o."replaces_id" = j."replaces_id" AND o."status" IN ('queued', 'running')
+      AND (o."created_at", o."id") < (j."created_at", j."id")
+);
+
+CREATE UNIQUE INDEX "idx_transaction_jobs_active_replacement" ON "transaction_jobs" ("replaces_id") WHERE "status" IN ('queued', 'running');
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX "idx_transaction_jobs_active_replacement";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
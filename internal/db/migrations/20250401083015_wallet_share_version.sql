@@ -0,0 +1,13 @@
+-- +goose Up
+-- Generation of the key shares of a wallet, bumped by every reshare. Backups
+-- carry it, so one made before a refresh is rejected without calling the nodes
+ALTER TABLE "wallets" ADD COLUMN "share_version" INT NOT NULL DEFAULT 1;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE "wallets" DROP COLUMN "share_version";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- API instance that queued a job, and until when it is known to be alive. The
+-- instance renews the lease of its unfinished jobs; other instances fail them
+-- once it expires. Unfinished jobs from before have no lease and are failed
+-- by the first instance that starts.
+ALTER TABLE "transaction_jobs" ADD COLUMN "owner_id" VARCHAR(255);
+ALTER TABLE "transaction_jobs" ADD COLUMN "lease_expires_at" TIMESTAMP;
+CREATE INDEX "idx_transaction_jobs_unfinished_owner" ON "transaction_jobs" ("owner_id") WHERE "status" IN ('queued', 'running');
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS "idx_transaction_jobs_unfinished_owner";
+ALTER TABLE "transaction_jobs" DROP COLUMN "lease_expires_at";
+ALTER TABLE "transaction_jobs" DROP COLUMN "owner_id";
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1 AND key = $2 AND expires_at >= $3;
+
+-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    user_id,
+    key,
+    fingerprint,
+    status,
+    expires_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, 'processing', $4, $5, $5
+)
+ON CONFLICT (user_id, key) DO UPDATE
+SET fingerprint = $3, status = 'processing', batch_id = NULL, job_ids = '{}', expires_at = $4, created_at = $5, updated_at = $5
+WHERE idempotency_keys.expires_at < $5
+RETURNING *;
+
+-- name: SetIdempotencyKeyJobs :exec
+UPDATE idempotency_keys
+SET batch_id = $3, job_ids = $4, updated_at = $5
+WHERE user_id = $1 AND key = $2 AND status = 'processing';
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = 'completed', batch_id = $3, job_ids = $4, expires_at = $5, updated_at = $6
+WHERE user_id = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2 AND status = 'processing';
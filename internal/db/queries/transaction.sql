@@ -1,6 +1,26 @@
 -- name: CreateTransaction :one
-INSERT INTO transactions (chain_id, from_address, to_address, tx_hash, created_at, updated_at) 
-VALUES ($1, $2, $3, $4, $5, $6) 
+INSERT INTO transactions (
+    chain_id,
+    from_address,
+    to_address,
+    tx_hash,
+    token_id,
+    status,
+    nonce,
+    value,
+    amount,
+    block_number,
+    replaces_id,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (chain_id, tx_hash) DO UPDATE
+SET token_id = COALESCE(transactions.token_id, EXCLUDED.token_id),
+    amount = COALESCE(transactions.amount, EXCLUDED.amount),
+    replaces_id = COALESCE(transactions.replaces_id, EXCLUDED.replaces_id),
+    updated_at = EXCLUDED.updated_at
 RETURNING *;
 
 -- name: GetTransactionsByWalletAddress :many
@@ -14,8 +34,45 @@ OFFSET $4;
 -- name: GetTransactionByID :one
 SELECT * FROM transactions WHERE id = $1;
 
+-- name: GetTransactionByTxHash :one
+SELECT * FROM transactions
+WHERE chain_id = $1 AND tx_hash = $2
+LIMIT 1;
+
 -- name: GetTransactionCount :one
 SELECT COUNT(*) 
 FROM transactions 
 WHERE (from_address = $1 OR to_address = $1)
-  AND ($2::int IS NULL OR chain_id = $2);
\ No newline at end of file
+  AND ($2::int IS NULL OR chain_id = $2);;
+
+-- name: GetTrackedTransactions :many
+SELECT * FROM transactions
+WHERE status IN ('submitted', 'pending')
+  AND (created_at > $1 OR (created_at = $1 AND id > $2))
+ORDER BY created_at, id
+LIMIT $3;
+
+-- name: UpdateTransactionStatus :exec
+UPDATE transactions
+SET status = $2,
+    block_number = $3,
+    block_hash = $4,
+    gas_used = $5,
+    effective_gas_price = $6,
+    fee = $7,
+    confirmations = $8,
+    nonce_used_block = $9,
+    updated_at = $10
+WHERE id = $1;
+
+-- name: MarkTransactionReplaced :exec
+UPDATE transactions
+SET status = 'replaced',
+    updated_at = $2
+WHERE id = $1 AND status IN ('submitted', 'pending');
+
+-- name: RestoreReplacedTransaction :exec
+UPDATE transactions
+SET status = 'submitted',
+    updated_at = $2
+WHERE id = $1 AND status = 'replaced';
@@ -0,0 +1,87 @@
+-- name: CreateTransactionJob :one
+INSERT INTO transaction_jobs (
+    id,
+    user_id,
+    wallet_id,
+    status,
+    chain_id,
+    from_address,
+    to_address,
+    symbol,
+    amount,
+    batch_id,
+    type,
+    replaces_id,
+    owner_id,
+    lease_expires_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+) RETURNING *;
+
+-- name: CreateReplacementJob :one
+INSERT INTO transaction_jobs (
+    id,
+    user_id,
+    wallet_id,
+    status,
+    chain_id,
+    from_address,
+    to_address,
+    symbol,
+    amount,
+    batch_id,
+    type,
+    replaces_id,
+    owner_id,
+    lease_expires_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+)
+ON CONFLICT (replaces_id) WHERE status IN ('queued', 'running') DO NOTHING
+RETURNING *;
+
+-- name: GetTransactionJobByID :one
+SELECT * FROM transaction_jobs
+WHERE id = $1 LIMIT 1;
+
+-- name: StartTransactionJob :one
+UPDATE transaction_jobs SET
+    status = $2,
+    started_at = $3,
+    updated_at = $4
+WHERE id = $1 AND status = 'queued' RETURNING *;
+
+-- name: FinishTransactionJob :one
+UPDATE transaction_jobs SET
+    status = $2,
+    transaction_id = $3,
+    error = $4,
+    error_code = $5,
+    ended_at = $6,
+    updated_at = $7
+WHERE id = $1 AND status IN ('queued', 'running') RETURNING *;
+
+-- name: RenewTransactionJobLeases :exec
+UPDATE transaction_jobs SET
+    lease_expires_at = $2
+WHERE owner_id = $1 AND status IN ('queued', 'running');
+
+-- name: FailOwnedTransactionJobs :exec
+UPDATE transaction_jobs SET
+    status = $2,
+    error = $3,
+    ended_at = $4,
+    updated_at = $4
+WHERE owner_id = $1 AND status IN ('queued', 'running');
+
+-- name: FailExpiredTransactionJobs :exec
+UPDATE transaction_jobs SET
+    status = $1,
+    error = $2,
+    ended_at = $3,
+    updated_at = $3
+WHERE status IN ('queued', 'running') AND (lease_expires_at IS NULL OR lease_expires_at < $3);
@@ -0,0 +1,35 @@
+-- name: CreateTSSSession :one
+INSERT INTO tss_sessions (
+    id,
+    user_id,
+    wallet_id,
+    type,
+    status,
+    message_hash,
+    parties,
+    threshold,
+    started_at,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: GetTSSSessionByID :one
+SELECT * FROM tss_sessions
+WHERE id = $1 LIMIT 1;
+
+-- name: FinishTSSSession :one
+UPDATE tss_sessions SET
+    status = $2,
+    error = $3,
+    error_code = $4,
+    ended_at = $5,
+    updated_at = $6
+WHERE id = $1 RETURNING *;
+
+-- name: SetTSSSessionWallet :exec
+UPDATE tss_sessions SET
+    wallet_id = $2,
+    updated_at = $3
+WHERE id = $1;
@@ -5,10 +5,13 @@ INSERT INTO wallets (
     encrypted_private_key,
     name,
     status,
+    parties,
+    threshold,
+    key_id,
     created_at,
     updated_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 ) RETURNING *;
 
 -- name: GetWalletsByUserID :many
@@ -32,5 +35,12 @@ UPDATE wallets SET
     updated_at = $6
 WHERE id = $1 RETURNING *;
 
+-- name: RotateWalletShare :one
+UPDATE wallets SET
+    encrypted_private_key = $2,
+    share_version = share_version + 1,
+    updated_at = $3
+WHERE id = $1 RETURNING *;
+
 -- name: GetAllAddresses :many
 SELECT address FROM wallets;
\ No newline at end of file
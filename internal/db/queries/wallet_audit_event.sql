@@ -0,0 +1,22 @@
+-- name: CreateWalletAuditEvent :one
+INSERT INTO wallet_audit_events (
+    id,
+    user_id,
+    wallet_id,
+    action,
+    status,
+    error,
+    error_code,
+    ip_address,
+    user_agent,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetWalletAuditEventsByWalletID :many
+SELECT * FROM wallet_audit_events
+WHERE wallet_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,45 @@
+-- name: GetWalletNonces :many
+SELECT * FROM wallet_nonces
+WHERE chain_id = $1 AND address = $2
+ORDER BY nonce;
+
+-- name: ReserveWalletNonce :one
+INSERT INTO wallet_nonces (
+    chain_id,
+    address,
+    nonce,
+    status,
+    reserved_until,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, 'reserved', $4, $5, $5
+)
+ON CONFLICT (chain_id, address, nonce) DO UPDATE
+SET status = 'reserved', tx_hash = NULL, reserved_until = $4, updated_at = $5
+RETURNING *;
+
+-- name: MarkWalletNonceSent :exec
+UPDATE wallet_nonces
+SET status = 'sent', tx_hash = $4, reserved_until = NULL, updated_at = $5
+WHERE chain_id = $1 AND address = $2 AND nonce = $3;
+
+-- name: ReleaseWalletNonce :exec
+UPDATE wallet_nonces
+SET status = 'released', reserved_until = NULL, updated_at = $4
+WHERE chain_id = $1 AND address = $2 AND nonce = $3 AND status = 'reserved';
+
+-- name: ReleaseDroppedWalletNonce :exec
+UPDATE wallet_nonces
+SET status = 'released', updated_at = $5
+WHERE chain_id = $1 AND address = $2 AND nonce = $3 AND status = 'sent' AND tx_hash = $4;
+
+-- name: ExpireWalletNonces :exec
+UPDATE wallet_nonces
+SET status = 'released', reserved_until = NULL, updated_at = $3
+WHERE chain_id = $1 AND address = $2 AND status = 'reserved' AND reserved_until < $3;
+
+-- name: PruneWalletNonces :exec
+DELETE FROM wallet_nonces
+WHERE chain_id = $1 AND address = $2
+AND (nonce < $3 OR (status = 'released' AND nonce < $4));
@@ -20,6 +20,18 @@ type Chain struct {
 	UpdatedAt      pgtype.Timestamp
 }
 
+type IdempotencyKey struct {
+	UserID      pgtype.UUID
+	Key         string
+	Fingerprint string
+	Status      string
+	BatchID     pgtype.UUID
+	JobIds      []pgtype.UUID
+	ExpiresAt   pgtype.Timestamp
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+}
+
 type Token struct {
 	ID              pgtype.UUID
 	ChainID         pgtype.UUID
@@ -35,13 +47,67 @@ type Token struct {
 }
 
 type Transaction struct {
+	ID                pgtype.UUID
+	ChainID           int32
+	FromAddress       string
+	ToAddress         string
+	TxHash            string
+	CreatedAt         pgtype.Timestamp
+	UpdatedAt         pgtype.Timestamp
+	TokenID           pgtype.UUID
+	Status            string
+	Nonce             pgtype.Int8
+	Value             pgtype.Text
+	BlockNumber       pgtype.Int8
+	BlockHash         pgtype.Text
+	GasUsed           pgtype.Int8
+	EffectiveGasPrice pgtype.Text
+	Confirmations     int32
+	ReplacesID        pgtype.UUID
+	Amount            pgtype.Text
+	Fee               pgtype.Text
+	NonceUsedBlock    pgtype.Int8
+}
+
+type TransactionJob struct {
+	ID             pgtype.UUID
+	UserID         pgtype.UUID
+	WalletID       pgtype.UUID
+	Status         string
+	ChainID        int32
+	FromAddress    string
+	ToAddress      string
+	Symbol         string
+	Amount         string
+	TransactionID  pgtype.UUID
+	Error          pgtype.Text
+	StartedAt      pgtype.Timestamp
+	EndedAt        pgtype.Timestamp
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
+	ErrorCode      pgtype.Text
+	BatchID        pgtype.UUID
+	Type           string
+	ReplacesID     pgtype.UUID
+	OwnerID        pgtype.Text
+	LeaseExpiresAt pgtype.Timestamp
+}
+
+type TssSession struct {
 	ID          pgtype.UUID
-	ChainID     int32
-	FromAddress string
-	ToAddress   string
-	TxHash      string
+	UserID      pgtype.UUID
+	WalletID    pgtype.UUID
+	Type        string
+	Status      string
+	MessageHash pgtype.Text
+	Parties     []int32
+	Threshold   int32
+	Error       pgtype.Text
+	StartedAt   pgtype.Timestamp
+	EndedAt     pgtype.Timestamp
 	CreatedAt   pgtype.Timestamp
 	UpdatedAt   pgtype.Timestamp
+	ErrorCode   pgtype.Text
 }
 
 type User struct {
@@ -62,4 +128,32 @@ type Wallet struct {
 	Status              string
 	CreatedAt           pgtype.Timestamp
 	UpdatedAt           pgtype.Timestamp
+	Parties             []int32
+	Threshold           int32
+	KeyID               string
+	ShareVersion        int32
+}
+
+type WalletAuditEvent struct {
+	ID        pgtype.UUID
+	UserID    pgtype.UUID
+	WalletID  pgtype.UUID
+	Action    string
+	Status    string
+	Error     pgtype.Text
+	ErrorCode pgtype.Text
+	IpAddress pgtype.Text
+	UserAgent pgtype.Text
+	CreatedAt pgtype.Timestamp
+}
+
+type WalletNonce struct {
+	ChainID       int32
+	Address       string
+	Nonce         int64
+	Status        string
+	TxHash        pgtype.Text
+	ReservedUntil pgtype.Timestamp
+	CreatedAt     pgtype.Timestamp
+	UpdatedAt     pgtype.Timestamp
 }
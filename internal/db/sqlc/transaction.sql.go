@@ -12,9 +12,29 @@ import (
 )
 
 const createTransaction = `-- name: CreateTransaction :one
-INSERT INTO transactions (chain_id, from_address, to_address, tx_hash, created_at, updated_at) 
-VALUES ($1, $2, $3, $4, $5, $6) 
-RETURNING id, chain_id, from_address, to_address, tx_hash, created_at, updated_at
+INSERT INTO transactions (
+    chain_id,
+    from_address,
+    to_address,
+    tx_hash,
+    token_id,
+    status,
+    nonce,
+    value,
+    amount,
+    block_number,
+    replaces_id,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (chain_id, tx_hash) DO UPDATE
+SET token_id = COALESCE(transactions.token_id, EXCLUDED.token_id),
+    amount = COALESCE(transactions.amount, EXCLUDED.amount),
+    replaces_id = COALESCE(transactions.replaces_id, EXCLUDED.replaces_id),
+    updated_at = EXCLUDED.updated_at
+RETURNING id, chain_id, from_address, to_address, tx_hash, created_at, updated_at, token_id, status, nonce, value, block_number, block_hash, gas_used, effective_gas_price, confirmations, replaces_id, amount, fee, nonce_used_block
 `
 
 type CreateTransactionParams struct {
@@ -22,6 +42,13 @@ type CreateTransactionParams struct {
 	FromAddress string
 	ToAddress   string
 	TxHash      string
+	TokenID     pgtype.UUID
+	Status      string
+	Nonce       pgtype.Int8
+	Value       pgtype.Text
+	Amount      pgtype.Text
+	BlockNumber pgtype.Int8
+	ReplacesID  pgtype.UUID
 	CreatedAt   pgtype.Timestamp
 	UpdatedAt   pgtype.Timestamp
 }
@@ -32,6 +59,13 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.FromAddress,
 		arg.ToAddress,
 		arg.TxHash,
+		arg.TokenID,
+		arg.Status,
+		arg.Nonce,
+		arg.Value,
+		arg.Amount,
+		arg.BlockNumber,
+		arg.ReplacesID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -44,12 +78,80 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.TxHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenID,
+		&i.Status,
+		&i.Nonce,
+		&i.Value,
+		&i.BlockNumber,
+		&i.BlockHash,
+		&i.GasUsed,
+		&i.EffectiveGasPrice,
+		&i.Confirmations,
+		&i.ReplacesID,
+		&i.Amount,
+		&i.Fee,
+		&i.NonceUsedBlock,
 	)
 	return i, err
 }
 
+const getTrackedTransactions = `-- name: GetTrackedTransactions :many
+SELECT id, chain_id, from_address, to_address, tx_hash, created_at, updated_at, token_id, status, nonce, value, block_number, block_hash, gas_used, effective_gas_price, confirmations, replaces_id, amount, fee, nonce_used_block FROM transactions
+WHERE status IN ('submitted', 'pending')
+  AND (created_at > $1 OR (created_at = $1 AND id > $2))
+ORDER BY created_at, id
+LIMIT $3
+`
+
+type GetTrackedTransactionsParams struct {
+	CreatedAt pgtype.Timestamp
+	ID        pgtype.UUID
+	Limit     int32
+}
+
+func (q *Queries) GetTrackedTransactions(ctx context.Context, arg GetTrackedTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTrackedTransactions, arg.CreatedAt, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChainID,
+			&i.FromAddress,
+			&i.ToAddress,
+			&i.TxHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TokenID,
+			&i.Status,
+			&i.Nonce,
+			&i.Value,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.GasUsed,
+			&i.EffectiveGasPrice,
+			&i.Confirmations,
+			&i.ReplacesID,
+			&i.Amount,
+			&i.Fee,
+			&i.NonceUsedBlock,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransactionByID = `-- name: GetTransactionByID :one
-SELECT id, chain_id, from_address, to_address, tx_hash, created_at, updated_at FROM transactions WHERE id = $1
+SELECT id, chain_id, from_address, to_address, tx_hash, created_at, updated_at, token_id, status, nonce, value, block_number, block_hash, gas_used, effective_gas_price, confirmations, replaces_id, amount, fee, nonce_used_block FROM transactions WHERE id = $1
 `
 
 func (q *Queries) GetTransactionByID(ctx context.Context, id pgtype.UUID) (Transaction, error) {
@@ -63,6 +165,58 @@ func (q *Queries) GetTransactionByID(ctx context.Context, id pgtype.UUID) (Trans
 		&i.TxHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenID,
+		&i.Status,
+		&i.Nonce,
+		&i.Value,
+		&i.BlockNumber,
+		&i.BlockHash,
+		&i.GasUsed,
+		&i.EffectiveGasPrice,
+		&i.Confirmations,
+		&i.ReplacesID,
+		&i.Amount,
+		&i.Fee,
+		&i.NonceUsedBlock,
+	)
+	return i, err
+}
+
+const getTransactionByTxHash = `-- name: GetTransactionByTxHash :one
+SELECT id, chain_id, from_address, to_address, tx_hash, created_at, updated_at, token_id, status, nonce, value, block_number, block_hash, gas_used, effective_gas_price, confirmations, replaces_id, amount, fee, nonce_used_block FROM transactions
+WHERE chain_id = $1 AND tx_hash = $2
+LIMIT 1
+`
+
+type GetTransactionByTxHashParams struct {
+	ChainID int32
+	TxHash  string
+}
+
+func (q *Queries) GetTransactionByTxHash(ctx context.Context, arg GetTransactionByTxHashParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByTxHash, arg.ChainID, arg.TxHash)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.ChainID,
+		&i.FromAddress,
+		&i.ToAddress,
+		&i.TxHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokenID,
+		&i.Status,
+		&i.Nonce,
+		&i.Value,
+		&i.BlockNumber,
+		&i.BlockHash,
+		&i.GasUsed,
+		&i.EffectiveGasPrice,
+		&i.Confirmations,
+		&i.ReplacesID,
+		&i.Amount,
+		&i.Fee,
+		&i.NonceUsedBlock,
 	)
 	return i, err
 }
@@ -87,7 +241,7 @@ func (q *Queries) GetTransactionCount(ctx context.Context, arg GetTransactionCou
 }
 
 const getTransactionsByWalletAddress = `-- name: GetTransactionsByWalletAddress :many
-SELECT id, chain_id, from_address, to_address, tx_hash, created_at, updated_at FROM transactions 
+SELECT id, chain_id, from_address, to_address, tx_hash, created_at, updated_at, token_id, status, nonce, value, block_number, block_hash, gas_used, effective_gas_price, confirmations, replaces_id, amount, fee, nonce_used_block FROM transactions 
 WHERE (from_address = $1 OR to_address = $1) 
 AND ($2::int IS NULL OR chain_id = $2)
 ORDER BY created_at DESC
@@ -124,6 +278,19 @@ func (q *Queries) GetTransactionsByWalletAddress(ctx context.Context, arg GetTra
 			&i.TxHash,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TokenID,
+			&i.Status,
+			&i.Nonce,
+			&i.Value,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.GasUsed,
+			&i.EffectiveGasPrice,
+			&i.Confirmations,
+			&i.ReplacesID,
+			&i.Amount,
+			&i.Fee,
+			&i.NonceUsedBlock,
 		); err != nil {
 			return nil, err
 		}
@@ -134,3 +301,80 @@ func (q *Queries) GetTransactionsByWalletAddress(ctx context.Context, arg GetTra
 	}
 	return items, nil
 }
+
+const markTransactionReplaced = `-- name: MarkTransactionReplaced :exec
+UPDATE transactions
+SET status = 'replaced',
+    updated_at = $2
+WHERE id = $1 AND status IN ('submitted', 'pending')
+`
+
+type MarkTransactionReplacedParams struct {
+	ID        pgtype.UUID
+	UpdatedAt pgtype.Timestamp
+}
+
+func (q *Queries) MarkTransactionReplaced(ctx context.Context, arg MarkTransactionReplacedParams) error {
+	_, err := q.db.Exec(ctx, markTransactionReplaced, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const restoreReplacedTransaction = `-- name: RestoreReplacedTransaction :exec
+UPDATE transactions
+SET status = 'submitted',
+    updated_at = $2
+WHERE id = $1 AND status = 'replaced'
+`
+
+type RestoreReplacedTransactionParams struct {
+	ID        pgtype.UUID
+	UpdatedAt pgtype.Timestamp
+}
+
+func (q *Queries) RestoreReplacedTransaction(ctx context.Context, arg RestoreReplacedTransactionParams) error {
+	_, err := q.db.Exec(ctx, restoreReplacedTransaction, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const updateTransactionStatus = `-- name: UpdateTransactionStatus :exec
+UPDATE transactions
+SET status = $2,
+    block_number = $3,
+    block_hash = $4,
+    gas_used = $5,
+    effective_gas_price = $6,
+    fee = $7,
+    confirmations = $8,
+    nonce_used_block = $9,
+    updated_at = $10
+WHERE id = $1
+`
+
+type UpdateTransactionStatusParams struct {
+	ID                pgtype.UUID
+	Status            string
+	BlockNumber       pgtype.Int8
+	BlockHash         pgtype.Text
+	GasUsed           pgtype.Int8
+	EffectiveGasPrice pgtype.Text
+	Fee               pgtype.Text
+	Confirmations     int32
+	NonceUsedBlock    pgtype.Int8
+	UpdatedAt         pgtype.Timestamp
+}
+
+func (q *Queries) UpdateTransactionStatus(ctx context.Context, arg UpdateTransactionStatusParams) error {
+	_, err := q.db.Exec(ctx, updateTransactionStatus,
+		arg.ID,
+		arg.Status,
+		arg.BlockNumber,
+		arg.BlockHash,
+		arg.GasUsed,
+		arg.EffectiveGasPrice,
+		arg.Fee,
+		arg.Confirmations,
+		arg.NonceUsedBlock,
+		arg.UpdatedAt,
+	)
+	return err
+}
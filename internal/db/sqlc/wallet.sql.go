@@ -18,11 +18,14 @@ INSERT INTO wallets (
     encrypted_private_key,
     name,
     status,
+    parties,
+    threshold,
+    key_id,
     created_at,
     updated_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, user_id, address, encrypted_private_key, name, status, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING id, user_id, address, encrypted_private_key, name, status, created_at, updated_at, parties, threshold, key_id, share_version
 `
 
 type CreateWalletParams struct {
@@ -31,6 +34,9 @@ type CreateWalletParams struct {
 	EncryptedPrivateKey []byte
 	Name                pgtype.Text
 	Status              string
+	Parties             []int32
+	Threshold           int32
+	KeyID               string
 	CreatedAt           pgtype.Timestamp
 	UpdatedAt           pgtype.Timestamp
 }
@@ -42,6 +48,9 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		arg.EncryptedPrivateKey,
 		arg.Name,
 		arg.Status,
+		arg.Parties,
+		arg.Threshold,
+		arg.KeyID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -55,6 +64,10 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Parties,
+		&i.Threshold,
+		&i.KeyID,
+		&i.ShareVersion,
 	)
 	return i, err
 }
@@ -84,7 +97,7 @@ func (q *Queries) GetAllAddresses(ctx context.Context) ([]string, error) {
 }
 
 const getWalletByAddress = `-- name: GetWalletByAddress :one
-SELECT id, user_id, address, encrypted_private_key, name, status, created_at, updated_at FROM wallets
+SELECT id, user_id, address, encrypted_private_key, name, status, created_at, updated_at, parties, threshold, key_id, share_version FROM wallets
 WHERE address = $1 LIMIT 1
 `
 
@@ -100,12 +113,16 @@ func (q *Queries) GetWalletByAddress(ctx context.Context, address string) (Walle
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Parties,
+		&i.Threshold,
+		&i.KeyID,
+		&i.ShareVersion,
 	)
 	return i, err
 }
 
 const getWalletByID = `-- name: GetWalletByID :one
-SELECT id, user_id, address, encrypted_private_key, name, status, created_at, updated_at FROM wallets
+SELECT id, user_id, address, encrypted_private_key, name, status, created_at, updated_at, parties, threshold, key_id, share_version FROM wallets
 WHERE id = $1 LIMIT 1
 `
 
@@ -121,12 +138,16 @@ func (q *Queries) GetWalletByID(ctx context.Context, id pgtype.UUID) (Wallet, er
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Parties,
+		&i.Threshold,
+		&i.KeyID,
+		&i.ShareVersion,
 	)
 	return i, err
 }
 
 const getWalletsByUserID = `-- name: GetWalletsByUserID :many
-SELECT id, user_id, address, encrypted_private_key, name, status, created_at, updated_at FROM wallets
+SELECT id, user_id, address, encrypted_private_key, name, status, created_at, updated_at, parties, threshold, key_id, share_version FROM wallets
 WHERE user_id = $1
 `
 
@@ -148,6 +169,10 @@ func (q *Queries) GetWalletsByUserID(ctx context.Context, userID pgtype.UUID) ([
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Parties,
+			&i.Threshold,
+			&i.KeyID,
+			&i.ShareVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -159,6 +184,40 @@ func (q *Queries) GetWalletsByUserID(ctx context.Context, userID pgtype.UUID) ([
 	return items, nil
 }
 
+const rotateWalletShare = `-- name: RotateWalletShare :one
+UPDATE wallets SET
+    encrypted_private_key = $2,
+    share_version = share_version + 1,
+    updated_at = $3
+WHERE id = $1 RETURNING id, user_id, address, encrypted_private_key, name, status, created_at, updated_at, parties, threshold, key_id, share_version
+`
+
+type RotateWalletShareParams struct {
+	ID                  pgtype.UUID
+	EncryptedPrivateKey []byte
+	UpdatedAt           pgtype.Timestamp
+}
+
+func (q *Queries) RotateWalletShare(ctx context.Context, arg RotateWalletShareParams) (Wallet, error) {
+	row := q.db.QueryRow(ctx, rotateWalletShare, arg.ID, arg.EncryptedPrivateKey, arg.UpdatedAt)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Address,
+		&i.EncryptedPrivateKey,
+		&i.Name,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Parties,
+		&i.Threshold,
+		&i.KeyID,
+		&i.ShareVersion,
+	)
+	return i, err
+}
+
 const updateWallet = `-- name: UpdateWallet :one
 UPDATE wallets SET
     address = $2,
@@ -166,7 +225,7 @@ UPDATE wallets SET
     name = $4,
     status = $5,
     updated_at = $6
-WHERE id = $1 RETURNING id, user_id, address, encrypted_private_key, name, status, created_at, updated_at
+WHERE id = $1 RETURNING id, user_id, address, encrypted_private_key, name, status, created_at, updated_at, parties, threshold, key_id, share_version
 `
 
 type UpdateWalletParams struct {
@@ -197,6 +256,10 @@ func (q *Queries) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wal
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Parties,
+		&i.Threshold,
+		&i.KeyID,
+		&i.ShareVersion,
 	)
 	return i, err
 }
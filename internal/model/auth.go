@@ -22,8 +22,10 @@ type AuthResponse struct {
 }
 
 type SignupRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,password"`
+	Email     string   `json:"email" validate:"required,email"`
+	Password  string   `json:"password" validate:"required,password"`
+	Parties   []uint32 `json:"parties,omitempty" example:"1,2"` // Parties holding shares of the wallet, one of TSS_TOPOLOGIES, defaults to TSS_PARTIES
+	Threshold uint32   `json:"threshold,omitempty" example:"2"` // Parties needed to sign, defaults to TSS_THRESHOLD
 }
 
 type SignupResponse struct {
@@ -31,7 +33,7 @@ type SignupResponse struct {
 	Wallet       WalletResponse `json:"wallet"`
 	AccessToken  string         `json:"access_token"`
 	RefreshToken string         `json:"refresh_token"`
-	ShareData    string         `json:"share_data"`
+	ShareData    string         `json:"share_data,omitempty"`
 }
 
 type RefreshResponse struct {
@@ -6,6 +6,11 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ChainStatusActive   = "active"
+	ChainStatusInactive = "inactive"
+)
+
 type Chain struct {
 	ID             uuid.UUID `json:"id"`
 	Name           string    `json:"name"`
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	HealthStatusOK       = "OK"
+	HealthStatusDegraded = "DEGRADED"
+)
+
+type HealthResponse struct {
+	Message string    `json:"message" example:"OK"`
+	TSS     TSSHealth `json:"tss"`
+}
+
+type TSSHealth struct {
+	Quorum    bool          `json:"quorum" example:"true"`
+	Alive     int           `json:"alive" example:"3"`
+	Threshold uint32        `json:"threshold" example:"2"`
+	Parties   []PartyHealth `json:"parties"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type PartyHealth struct {
+	Party    uint32     `json:"party" example:"1"`
+	Alive    bool       `json:"alive" example:"true"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	IdempotencyKeyStatusProcessing = "processing"
+	IdempotencyKeyStatusCompleted  = "completed"
+)
+
+// IdempotencyKey is a client supplied key of a transaction submission. It
+// holds the fingerprint of the request and, once the transfers are queued,
+// the jobs a retry with the same key gets back.
+type IdempotencyKey struct {
+	UserID      uuid.UUID   `json:"user_id"`
+	Key         string      `json:"key"`
+	Fingerprint string      `json:"fingerprint"`
+	Status      string      `json:"status"`
+	BatchID     uuid.UUID   `json:"batch_id"`
+	JobIDs      []uuid.UUID `json:"job_ids"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
@@ -1,7 +1,9 @@
 package model
 
 type GoogleOauth struct {
-	Code string `json:"code" validate:"required"`
+	Code      string   `json:"code" validate:"required"`
+	Parties   []uint32 `json:"parties,omitempty" example:"1,2"` // Parties holding shares of a new user's wallet, one of TSS_TOPOLOGIES, defaults to TSS_PARTIES
+	Threshold uint32   `json:"threshold,omitempty" example:"2"` // Parties needed to sign, defaults to TSS_THRESHOLD
 }
 
 // TokenResponse represents the response from the Google OAuth token endpoint
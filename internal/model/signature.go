@@ -0,0 +1,23 @@
+package model
+
+import "encoding/json"
+
+type SignMessageRequest struct {
+	// Message is signed as UTF-8 text, or as raw bytes when it is 0x prefixed hex
+	Message string `json:"message" validate:"required" example:"Sign in to Example dapp"`
+	// ShareData is required unless the wallet is custodial
+	ShareData string `json:"share_data"`
+}
+
+type SignTypedDataRequest struct {
+	// TypedData is an EIP-712 payload with types, primaryType, domain and message
+	TypedData json.RawMessage `json:"typed_data" validate:"required" swaggertype:"object"`
+	// ShareData is required unless the wallet is custodial
+	ShareData string `json:"share_data"`
+}
+
+type SignatureResponse struct {
+	Address   string `json:"address" example:"0x742d35cc6634c0532925a3b844bc454e4438f44e"`
+	Hash      string `json:"hash" example:"0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"`
+	Signature string `json:"signature" example:"0x6e0d2e0c8c0e5d4f0c1a3b3f...1b"`
+}
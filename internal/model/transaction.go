@@ -6,14 +6,41 @@ import (
 	"github.com/google/uuid"
 )
 
+// Statuses of a transaction. A broadcast transaction is submitted until the
+// node knows it, pending until its block has enough confirmations, then
+// confirmed or failed when it reverted. A transaction that left the mempool
+// without being mined is dropped. A transaction is replaced once its speed-up
+// or cancel is broadcast, and submitted again if the replacement is dropped.
+const (
+	TransactionStatusSubmitted = "submitted"
+	TransactionStatusPending   = "pending"
+	TransactionStatusConfirmed = "confirmed"
+	TransactionStatusFailed    = "failed"
+	TransactionStatusDropped   = "dropped"
+	TransactionStatusReplaced  = "replaced"
+)
+
 type Transaction struct {
-	ID          uuid.UUID `json:"id"`
-	FromAddress string    `json:"from_address"`
-	ToAddress   string    `json:"to_address"`
-	ChainID     int       `json:"chain_id"`
-	TxHash      string    `json:"tx_hash"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                uuid.UUID `json:"id"`
+	FromAddress       string    `json:"from_address"`
+	ToAddress         string    `json:"to_address"`
+	ChainID           int       `json:"chain_id"`
+	TxHash            string    `json:"tx_hash"`
+	TokenID           uuid.UUID `json:"token_id"`
+	Status            string    `json:"status"`
+	Nonce             *uint64   `json:"nonce"`
+	Value             string    `json:"value"`  // Wei sent with the transaction, 0 for a token transfer
+	Amount            string    `json:"amount"` // Base units of the token transferred
+	BlockNumber       *uint64   `json:"block_number"`
+	BlockHash         string    `json:"block_hash"`
+	GasUsed           *uint64   `json:"gas_used"`
+	EffectiveGasPrice string    `json:"effective_gas_price"` // Wei
+	Fee               string    `json:"fee"`                 // Wei paid for gas, once mined
+	Confirmations     int       `json:"confirmations"`
+	ReplacesID        uuid.UUID `json:"replaces_id"` // Transaction a speed-up or cancel replaces
+	NonceUsedBlock    *uint64   `json:"-"`           // Head block when the tracker found the nonce used without a receipt
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 type TransactionFilter struct {
@@ -29,12 +56,31 @@ type Pagination struct {
 	PageSize int `form:"page_size" binding:"required,min=1,max=100"`
 }
 
+// Directions of a transaction relative to the wallet whose history lists it
+const (
+	TransactionDirectionIncoming = "incoming"
+	TransactionDirectionOutgoing = "outgoing"
+	TransactionDirectionSelf     = "self"
+)
+
+// TransactionHistoryItem is a transaction as seen from a wallet, with its
+// amount and fee in whole units
+type TransactionHistoryItem struct {
+	Transaction
+	Direction       string `json:"direction" example:"outgoing"`
+	Symbol          string `json:"symbol" example:"USDT"`
+	FormattedAmount string `json:"formatted_amount" example:"10.5"`
+	NativeSymbol    string `json:"native_symbol" example:"ETH"`
+	FormattedFee    string `json:"formatted_fee,omitempty" example:"0.000021"`
+	ExplorerURL     string `json:"explorer_url,omitempty" example:"https://sepolia.etherscan.io/tx/0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"`
+}
+
 type TransactionListResponse struct {
-	Transactions []Transaction `json:"transactions"`
-	Total        int           `json:"total"`
-	Page         int           `json:"page"`
-	PageSize     int           `json:"page_size"`
-	TotalPages   int           `json:"total_pages"`
+	Transactions []TransactionHistoryItem `json:"transactions"`
+	Total        int                      `json:"total"`
+	Page         int                      `json:"page"`
+	PageSize     int                      `json:"page_size"`
+	TotalPages   int                      `json:"total_pages"`
 }
 
 type CreateAndSubmitTransactionRequest struct {
@@ -43,5 +89,57 @@ type CreateAndSubmitTransactionRequest struct {
 	ChainID     int    `json:"chain_id" validate:"required"`
 	Symbol      string `json:"symbol" validate:"required"`
 	Amount      string `json:"amount" validate:"required"`
-	ShareData   string `json:"share_data" validate:"required"`
+	TxType      string `json:"tx_type" validate:"omitempty,oneof=legacy eip1559"` // Defaults to eip1559
+	Speed       string `json:"speed" validate:"omitempty,oneof=low medium high"`  // Defaults to medium
+	ShareData   string `json:"share_data"`                                        // Required unless the wallet is custodial
+}
+
+// Warnings of a transaction quote
+const (
+	QuoteWarningInsufficientBalance = "INSUFFICIENT_BALANCE"
+	QuoteWarningContractRecipient   = "CONTRACT_RECIPIENT"
+)
+
+// ReplaceTransactionRequest speeds up or cancels a pending transaction
+type ReplaceTransactionRequest struct {
+	Speed     string `json:"speed" validate:"omitempty,oneof=low medium high"` // Defaults to medium, fees rise at least 10% either way
+	ShareData string `json:"share_data"`                                       // Required unless the wallet is custodial
+}
+
+type QuoteTransactionRequest struct {
+	FromAddress string `json:"from_address" validate:"required"`
+	ToAddress   string `json:"to_address" validate:"required"`
+	ChainID     int    `json:"chain_id" validate:"required"`
+	Symbol      string `json:"symbol" validate:"required"`
+	Amount      string `json:"amount" validate:"required"`
+	TxType      string `json:"tx_type" validate:"omitempty,oneof=legacy eip1559"` // Defaults to eip1559
+	Speed       string `json:"speed" validate:"omitempty,oneof=low medium high"`  // Defaults to medium
+}
+
+// TransactionQuoteResponse is the unsigned transaction a send would build and
+// what it would cost. Fees are estimates, the transaction is built again when
+// it is sent.
+type TransactionQuoteResponse struct {
+	ChainID int    `json:"chain_id" example:"11155111"`
+	TxType  string `json:"tx_type" example:"eip1559"`
+	Speed   string `json:"speed" example:"medium"`
+	From    string `json:"from" example:"0x742d35cc6634c0532925a3b844bc454e4438f44e"`
+	// To is the token contract for a token transfer
+	To                   string `json:"to" example:"0x742d35cc6634c0532925a3b844bc454e4438f44e"`
+	Nonce                uint64 `json:"nonce" example:"7"`
+	GasLimit             uint64 `json:"gas_limit" example:"25200"`
+	GasPrice             string `json:"gas_price,omitempty" example:"2000000000"`                // Wei, legacy transactions only
+	MaxFeePerGas         string `json:"max_fee_per_gas,omitempty" example:"3000000000"`          // Wei, eip1559 transactions only
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,omitempty" example:"1000000000"` // Wei, eip1559 transactions only
+	Value                string `json:"value" example:"10000000000000000"`                       // Wei
+	Data                 string `json:"data,omitempty" example:"0xa9059cbb"`
+	UnsignedTx           string `json:"unsigned_tx" example:"0x02f0"` // Hex encoded, as signed by the wallet
+	// Costs in whole units. MaxFee is the gas limit at the max fee, the most
+	// the transaction can pay; TotalNative adds the native value sent.
+	NativeSymbol string   `json:"native_symbol" example:"ETH"`
+	MaxFee       string   `json:"max_fee" example:"0.0000756"`
+	TotalNative  string   `json:"total_native" example:"0.0100756"`
+	Symbol       string   `json:"symbol" example:"USDT"`
+	Amount       string   `json:"amount" example:"10"`
+	Warnings     []string `json:"warnings" example:"INSUFFICIENT_BALANCE"`
 }
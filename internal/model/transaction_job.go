@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TransactionJobStatusQueued    = "queued"
+	TransactionJobStatusRunning   = "running"
+	TransactionJobStatusCompleted = "completed"
+	TransactionJobStatusFailed    = "failed"
+)
+
+// Types of a job. A speed-up or cancel replaces a sent transaction.
+const (
+	TransactionJobTypeTransfer = "transfer"
+	TransactionJobTypeSpeedUp  = "speed_up"
+	TransactionJobTypeCancel   = "cancel"
+)
+
+type TransactionJob struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	WalletID      uuid.UUID  `json:"wallet_id"`
+	Status        string     `json:"status"`
+	ChainID       int        `json:"chain_id"`
+	FromAddress   string     `json:"from_address"`
+	ToAddress     string     `json:"to_address"`
+	Symbol        string     `json:"symbol"`
+	Amount        string     `json:"amount"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Error         string     `json:"error"`
+	ErrorCode     string     `json:"error_code"`
+	BatchID       uuid.UUID  `json:"batch_id"`
+	Type          string     `json:"type"`
+	ReplacesID    uuid.UUID  `json:"replaces_id"` // Transaction a speed-up or cancel replaces
+	StartedAt     *time.Time `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// OwnerID is the API instance holding the job in memory. It renews
+	// LeaseExpiresAt while the job is unfinished.
+	OwnerID        string     `json:"owner_id"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at"`
+}
+
+type TransactionJobResponse struct {
+	ID          uuid.UUID    `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Status      string       `json:"status" example:"queued"`
+	Type        string       `json:"type" example:"transfer"`
+	BatchID     *uuid.UUID   `json:"batch_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ReplacesID  *uuid.UUID   `json:"replaces_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ChainID     int          `json:"chain_id" example:"11155111"`
+	FromAddress string       `json:"from_address" example:"0x742d35cc6634c0532925a3b844bc454e4438f44e"`
+	ToAddress   string       `json:"to_address" example:"0x742d35cc6634c0532925a3b844bc454e4438f44e"`
+	Symbol      string       `json:"symbol" example:"ETH"`
+	Amount      string       `json:"amount" example:"0.01"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	ErrorCode   string       `json:"error_code,omitempty" example:"TSS_PARTY_UNAVAILABLE"`
+	CreatedAt   time.Time    `json:"created_at"`
+	StartedAt   *time.Time   `json:"started_at,omitempty"`
+	EndedAt     *time.Time   `json:"ended_at,omitempty"`
+}
+
+type BatchTransfer struct {
+	ToAddress string `json:"to_address" validate:"required"`
+	Symbol    string `json:"symbol" validate:"required"`
+	Amount    string `json:"amount" validate:"required"`
+}
+
+type CreateBatchTransactionRequest struct {
+	FromAddress string          `json:"from_address" validate:"required"`
+	ChainID     int             `json:"chain_id" validate:"required"`
+	Transfers   []BatchTransfer `json:"transfers" validate:"required,min=1,max=50,dive"`
+	TxType      string          `json:"tx_type" validate:"omitempty,oneof=legacy eip1559"` // Defaults to eip1559
+	Speed       string          `json:"speed" validate:"omitempty,oneof=low medium high"`  // Defaults to medium
+	ShareData   string          `json:"share_data"`                                        // Required unless the wallet is custodial
+}
+
+type TransactionBatchResponse struct {
+	BatchID uuid.UUID                `json:"batch_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Jobs    []TransactionJobResponse `json:"jobs"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TSSSessionTypeKeygen    = "keygen"
+	TSSSessionTypeSign      = "sign"
+	TSSSessionTypeSignBatch = "sign_batch"
+	TSSSessionTypeReshare   = "reshare"
+	// TSSSessionTypeKeyVerify signs a challenge with a new key before its wallet is stored
+	TSSSessionTypeKeyVerify = "key_verify"
+
+	TSSSessionStatusRunning   = "running"
+	TSSSessionStatusCompleted = "completed"
+	TSSSessionStatusFailed    = "failed"
+)
+
+type TSSSession struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	WalletID    uuid.UUID  `json:"wallet_id"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	MessageHash string     `json:"message_hash"`
+	Parties     []uint32   `json:"parties"`
+	Threshold   uint32     `json:"threshold"`
+	Error       string     `json:"error"`
+	ErrorCode   string     `json:"error_code"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type TSSSessionResponse struct {
+	ID          uuid.UUID  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	WalletID    *uuid.UUID `json:"wallet_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Type        string     `json:"type" example:"sign"`
+	Status      string     `json:"status" example:"completed"`
+	MessageHash string     `json:"message_hash,omitempty" example:"0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"`
+	Parties     []uint32   `json:"parties" example:"1,2,3"`
+	Threshold   uint32     `json:"threshold" example:"2"`
+	Error       string     `json:"error,omitempty"`
+	ErrorCode   string     `json:"error_code,omitempty" example:"TSS_PARTY_UNAVAILABLE"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+}
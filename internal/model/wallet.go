@@ -13,13 +13,33 @@ type Wallet struct {
 	EncryptedPrivateKey string    `json:"encrypted_private_key"`
 	Name                string    `json:"name"`
 	Status              string    `json:"status"`
+	Parties             []uint32  `json:"parties"`
+	Threshold           uint32    `json:"threshold"`
+	KeyID               string    `json:"key_id"`
+	ShareVersion        int       `json:"share_version"`
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 type WalletResponse struct {
-	ID      uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
-	UserID  uuid.UUID `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Address string    `json:"address" example:"0x0000000000000000000000000000000000000000"`
-	Name    string    `json:"name" example:"My Wallet"`
+	ID        uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserID    uuid.UUID `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Address   string    `json:"address" example:"0x0000000000000000000000000000000000000000"`
+	Name      string    `json:"name" example:"My Wallet"`
+	Parties   []uint32  `json:"parties" example:"1,2,3"`
+	Threshold uint32    `json:"threshold" example:"2"`
+	Custodial bool      `json:"custodial" example:"false"`
+	// ShareVersion is bumped by every share refresh and recovery. Backups
+	// made for an older version are rejected.
+	ShareVersion int `json:"share_version" example:"1"`
+}
+
+type RefreshSharesRequest struct {
+	// ShareData is required unless the wallet is custodial
+	ShareData string `json:"share_data"`
+}
+
+type RefreshSharesResponse struct {
+	Wallet    WalletResponse `json:"wallet"`
+	ShareData string         `json:"share_data,omitempty"`
 }
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"mpc/pkg/backup"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WalletAuditActionBackupExport = "backup_export"
+	WalletAuditActionRecover      = "recover"
+
+	WalletAuditStatusSucceeded = "succeeded"
+	WalletAuditStatusFailed    = "failed"
+	WalletAuditStatusThrottled = "throttled"
+)
+
+type WalletAuditEvent struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	WalletID  uuid.UUID `json:"wallet_id"`
+	Action    string    `json:"action"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error"`
+	ErrorCode string    `json:"error_code"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RequestMeta identifies the client of an audited request
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
+}
+
+type WalletAuditEventResponse struct {
+	ID        uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Action    string    `json:"action" example:"recover"`
+	Status    string    `json:"status" example:"succeeded"`
+	ErrorCode string    `json:"error_code,omitempty" example:"BACKUP_WRONG_PASSWORD"`
+	IPAddress string    `json:"ip_address,omitempty" example:"203.0.113.7"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportBackupRequest struct {
+	Password  string `json:"password" validate:"required,min=12"`
+	ShareData string `json:"share_data" validate:"required"`
+}
+
+type RecoverWalletRequest struct {
+	Backup   backup.File `json:"backup"`
+	Password string      `json:"password" validate:"required"`
+}
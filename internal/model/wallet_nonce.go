@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	WalletNonceStatusReserved = "reserved"
+	WalletNonceStatusSent     = "sent"
+	WalletNonceStatusReleased = "released"
+)
+
+// WalletNonce is a nonce handed out for a wallet on a chain. Reserved nonces
+// are being signed, sent ones were broadcast and released ones are free to
+// be handed out again.
+type WalletNonce struct {
+	ChainID       int        `json:"chain_id"`
+	Address       string     `json:"address"`
+	Nonce         uint64     `json:"nonce"`
+	Status        string     `json:"status"`
+	TxHash        string     `json:"tx_hash"`
+	ReservedUntil *time.Time `json:"reserved_until"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
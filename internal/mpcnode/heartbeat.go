@@ -0,0 +1,31 @@
+package mpcnode
+
+import (
+	"context"
+	"time"
+
+	"mpc/pkg/logger"
+	"mpc/pkg/tss"
+)
+
+// RunHeartbeats publishes a heartbeat for every party every interval until
+// ctx is cancelled. Heartbeats expire after three intervals, so the parties
+// drop out of the quorum shortly after the node stops.
+func (s *Server) RunHeartbeats(ctx context.Context, parties []uint32, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, party := range parties {
+			if err := tss.PublishHeartbeat(ctx, s.redisClient, party, 3*interval); err != nil {
+				logger.Error("failed to publish heartbeat", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
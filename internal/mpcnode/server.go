@@ -0,0 +1,358 @@
+// Package mpcnode is a development stub of the MPC cluster in a single
+// process, for local development and integration tests. It speaks the same
+// gRPC and Redis result protocol as the real nodes so the API runs against it
+// unchanged.
+//
+// It is NOT threshold ECDSA: keys are split with Shamir secret sharing by a
+// trusted dealer and reconstructed in memory on every sign and reshare. It
+// stands in for a node built on a threshold ECDSA library (GG18/GG20 or
+// CGGMP) until one is integrated. Never use it with real funds.
+//
+// To limit the damage of a leak, the node only accepts a threshold of at
+// least 2 and keeps threshold-1 shares, so neither the node nor its state
+// file can sign without the client share. The shares of the remaining parties
+// are discarded.
+package mpcnode
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	rd "mpc/internal/db/redis"
+	"mpc/pkg/envelope"
+	"mpc/pkg/logger"
+	"mpc/pkg/tss"
+	pb "mpc/proto"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/grpc"
+)
+
+const (
+	// sessionTimeout bounds a single keygen, sign or reshare run
+	sessionTimeout = time.Minute
+
+	minThreshold = 2
+)
+
+// clientShare is the share returned to the API as share_data, base64 encoded
+type clientShare struct {
+	Party uint32 `json:"party"`
+	Value string `json:"value"`
+}
+
+type derSignature struct {
+	R, S *big.Int
+}
+
+// Server implements MPCServiceServer
+type Server struct {
+	pb.UnimplementedMPCServiceServer
+	redisClient *rd.Client
+	signer      *tss.ResultSigner
+	keys        *keyStore
+
+	mu      sync.Mutex
+	streams map[uint32]pb.MPCService_StreamMessagesServer
+}
+
+// NewServer creates a node that publishes results with redisClient, signed
+// with signer when it is not nil. Keys are persisted to stateFile sealed with
+// state, or only kept in memory when it is empty.
+func NewServer(redisClient *rd.Client, signer *tss.ResultSigner, stateFile string, state *envelope.Envelope) (*Server, error) {
+	keys, err := newKeyStore(stateFile, state)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		redisClient: redisClient,
+		signer:      signer,
+		keys:        keys,
+		streams:     make(map[uint32]pb.MPCService_StreamMessagesServer),
+	}, nil
+}
+
+// Register registers the node on a gRPC server
+func (s *Server) Register(grpcServer *grpc.Server) {
+	pb.RegisterMPCServiceServer(grpcServer, s)
+}
+
+// NotifyAction starts a session in the background. The outcome is published
+// to the session result stream, as the real nodes do.
+func (s *Server) NotifyAction(ctx context.Context, req *pb.ActionRequest) (*pb.ActionResponse, error) {
+	topology := tss.Topology{Parties: req.Parties, Threshold: req.Threshold}
+	if err := topology.Validate(); err != nil {
+		return &pb.ActionResponse{Success: false, Error: err.Error()}, nil
+	}
+	// With a threshold of 1 the client share alone would be the key
+	if topology.Threshold < minThreshold {
+		return &pb.ActionResponse{Success: false, Error: fmt.Sprintf("threshold must be at least %d, got %d", minThreshold, topology.Threshold)}, nil
+	}
+
+	var run func(context.Context, *pb.ActionRequest) error
+	switch req.Action {
+	case pb.Action_INIT_KEYGEN:
+		run = s.keygen
+	case pb.Action_INIT_SIGN:
+		run = s.sign
+	case pb.Action_INIT_SIGN_BATCH:
+		run = s.signBatch
+	case pb.Action_INIT_RESHARE:
+		run = s.reshare
+	default:
+		return &pb.ActionResponse{Success: false, Error: fmt.Sprintf("unsupported action %s", req.Action)}, nil
+	}
+
+	logger.Info(fmt.Sprintf("Starting %s session %s", req.Action, req.SessionId))
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sessionTimeout)
+		defer cancel()
+		if err := run(ctx, req); err != nil {
+			logger.Error("failed to publish result for session "+req.SessionId, err)
+		}
+	}()
+	return &pb.ActionResponse{Success: true}, nil
+}
+
+// StreamMessages relays protocol messages between connected parties. The
+// sender of the first message identifies the stream.
+func (s *Server) StreamMessages(stream pb.MPCService_StreamMessagesServer) error {
+	var party uint32
+	defer func() {
+		if party != 0 {
+			s.mu.Lock()
+			delete(s.streams, party)
+			s.mu.Unlock()
+		}
+	}()
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if party == 0 && msg.From != 0 {
+			party = msg.From
+			s.mu.Lock()
+			s.streams[party] = stream
+			s.mu.Unlock()
+		}
+
+		s.mu.Lock()
+		for id, peer := range s.streams {
+			if id == msg.From || (!msg.Broadcast && id != msg.To) {
+				continue
+			}
+			if err := peer.Send(msg); err != nil {
+				logger.Warn(fmt.Sprintf("failed to relay message to party %d", id))
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// keygen generates a key, keeps the node shares and returns the share of the
+// first party to the client
+func (s *Server) keygen(ctx context.Context, req *pb.ActionRequest) error {
+	resultKey := tss.KeygenResultKey(req.SessionId)
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return s.publishKeygenFailure(ctx, resultKey, tss.FailureAborted, err)
+	}
+
+	record, share, err := s.splitKey(privateKey, req.Threshold, req.Parties)
+	if err != nil {
+		return s.publishKeygenFailure(ctx, resultKey, tss.FailureAborted, err)
+	}
+	if err := s.keys.put(ctx, req.KeyId, record); err != nil {
+		return s.publishKeygenFailure(ctx, resultKey, tss.FailureAborted, err)
+	}
+
+	return tss.PublishResult(ctx, s.redisClient, s.signer, resultKey, tss.KeygenResult{
+		ShareData: share,
+		PubKey:    record.PubKey,
+	})
+}
+
+// sign recovers the key from the client share and the node shares and signs
+// the message hash, returning a DER signature
+func (s *Server) sign(ctx context.Context, req *pb.ActionRequest) error {
+	resultKey := tss.SignResultKey(req.SessionId)
+
+	privateKey, err := s.recoverKey(req.KeyId, req.ShareData)
+	if err != nil {
+		return s.publishSignFailure(ctx, resultKey, tss.FailureInvalidShare, err)
+	}
+	der, err := signHash(privateKey, req.MsgHash)
+	if err != nil {
+		return s.publishSignFailure(ctx, resultKey, tss.FailureAborted, err)
+	}
+
+	return tss.PublishResult(ctx, s.redisClient, s.signer, resultKey, tss.SignResult{
+		Signature: base64.StdEncoding.EncodeToString(der),
+	})
+}
+
+// signBatch signs every hash of the request with one key recovery
+func (s *Server) signBatch(ctx context.Context, req *pb.ActionRequest) error {
+	resultKey := tss.SignResultKey(req.SessionId)
+
+	privateKey, err := s.recoverKey(req.KeyId, req.ShareData)
+	if err != nil {
+		return s.publishSignFailure(ctx, resultKey, tss.FailureInvalidShare, err)
+	}
+	if len(req.MsgHashes) == 0 {
+		return s.publishSignFailure(ctx, resultKey, tss.FailureAborted, fmt.Errorf("no message hashes"))
+	}
+
+	signatures := make([]string, len(req.MsgHashes))
+	for i, hash := range req.MsgHashes {
+		der, err := signHash(privateKey, hash)
+		if err != nil {
+			return s.publishSignFailure(ctx, resultKey, tss.FailureAborted, err)
+		}
+		signatures[i] = base64.StdEncoding.EncodeToString(der)
+	}
+
+	return tss.PublishResult(ctx, s.redisClient, s.signer, resultKey, tss.SignResult{
+		Signatures: signatures,
+	})
+}
+
+// reshare splits an existing key again with a fresh polynomial. The address
+// is unchanged and the previous shares stop working.
+func (s *Server) reshare(ctx context.Context, req *pb.ActionRequest) error {
+	resultKey := tss.ReshareResultKey(req.SessionId)
+
+	privateKey, err := s.recoverKey(req.KeyId, req.ShareData)
+	if err != nil {
+		return s.publishKeygenFailure(ctx, resultKey, tss.FailureInvalidShare, err)
+	}
+
+	record, share, err := s.splitKey(privateKey, req.Threshold, req.Parties)
+	if err != nil {
+		return s.publishKeygenFailure(ctx, resultKey, tss.FailureAborted, err)
+	}
+	if err := s.keys.put(ctx, req.KeyId, record); err != nil {
+		return s.publishKeygenFailure(ctx, resultKey, tss.FailureAborted, err)
+	}
+
+	return tss.PublishResult(ctx, s.redisClient, s.signer, resultKey, tss.KeygenResult{
+		ShareData: share,
+		PubKey:    record.PubKey,
+	})
+}
+
+// splitKey shares a private key between the parties. The first party's share
+// goes to the client and the shares of the next threshold-1 parties stay on
+// the node. The rest are discarded, so the key can only be recovered with the
+// client share.
+func (s *Server) splitKey(privateKey *ecdsa.PrivateKey, threshold uint32, parties []uint32) (keyRecord, string, error) {
+	shares, err := splitSecret(privateKey.D, threshold, parties)
+	if err != nil {
+		return keyRecord{}, "", err
+	}
+
+	clientParty := parties[0]
+	record := keyRecord{
+		Address:     crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		PubKey:      hex.EncodeToString(crypto.FromECDSAPub(&privateKey.PublicKey)),
+		Threshold:   threshold,
+		Parties:     parties,
+		ClientParty: clientParty,
+		Shares:      make(map[uint32]string, threshold-1),
+	}
+	for _, party := range parties[1:threshold] {
+		record.Shares[party] = shares[party].Text(16)
+	}
+
+	share, err := json.Marshal(clientShare{Party: clientParty, Value: shares[clientParty].Text(16)})
+	if err != nil {
+		return keyRecord{}, "", fmt.Errorf("failed to encode client share: %w", err)
+	}
+	return record, base64.StdEncoding.EncodeToString(share), nil
+}
+
+// recoverKey combines the client share with threshold-1 node shares and checks
+// the result against the stored address
+func (s *Server) recoverKey(keyID string, shareData []byte) (*ecdsa.PrivateKey, error) {
+	record, ok := s.keys.get(keyID)
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", keyID)
+	}
+
+	var client clientShare
+	if err := json.Unmarshal(shareData, &client); err != nil {
+		return nil, fmt.Errorf("invalid share data: %w", err)
+	}
+	clientValue, ok := new(big.Int).SetString(client.Value, 16)
+	if !ok || client.Party != record.ClientParty {
+		return nil, fmt.Errorf("invalid share for party %d", client.Party)
+	}
+
+	shares := map[uint32]*big.Int{client.Party: clientValue}
+	for _, party := range record.Parties {
+		if uint32(len(shares)) == record.Threshold {
+			break
+		}
+		if value, ok := record.Shares[party]; ok {
+			shares[party], _ = new(big.Int).SetString(value, 16)
+		}
+	}
+
+	privateKey, err := crypto.ToECDSA(padTo32(combineShares(shares).Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid share: %w", err)
+	}
+	if crypto.PubkeyToAddress(privateKey.PublicKey).Hex() != record.Address {
+		return nil, fmt.Errorf("share does not match key %s", keyID)
+	}
+	return privateKey, nil
+}
+
+func (s *Server) publishKeygenFailure(ctx context.Context, key, code string, err error) error {
+	logger.Error("Session failed: "+key, err)
+	return tss.PublishResult(ctx, s.redisClient, s.signer, key, tss.KeygenResult{
+		NodeFailure: tss.NodeFailure{Error: err.Error(), Code: code},
+	})
+}
+
+func (s *Server) publishSignFailure(ctx context.Context, key, code string, err error) error {
+	logger.Error("Session failed: "+key, err)
+	return tss.PublishResult(ctx, s.redisClient, s.signer, key, tss.SignResult{
+		NodeFailure: tss.NodeFailure{Error: err.Error(), Code: code},
+	})
+}
+
+// signHash signs a 32 byte hash and returns a DER signature
+func signHash(privateKey *ecdsa.PrivateKey, hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("message hash must be 32 bytes, got %d", len(hash))
+	}
+	sig, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(derSignature{
+		R: new(big.Int).SetBytes(sig[:32]),
+		S: new(big.Int).SetBytes(sig[32:64]),
+	})
+}
+
+func padTo32(b []byte) []byte {
+	p := make([]byte, 32)
+	copy(p[32-len(b):], b)
+	return p
+}
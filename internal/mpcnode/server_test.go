@@ -0,0 +1,233 @@
+package mpcnode
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mpc/internal/config"
+	rd "mpc/internal/db/redis"
+	"mpc/pkg/envelope"
+	"mpc/pkg/tss"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+)
+
+var testTopology = tss.Topology{Parties: []uint32{1, 2, 3}, Threshold: 2}
+
+// startNode runs the node on a local gRPC listener with Redis served by
+// miniredis and returns a gRPC TSS client connected to it, as the API uses
+func startNode(t *testing.T) *tss.TSS {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	redisClient := &rd.Client{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { redisClient.Close() })
+
+	resultKey := strings.Repeat("ab", 32)
+	signer, err := tss.NewResultSigner("test-node", resultKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := NewServer(redisClient, signer, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	node.Register(grpcServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	// The first heartbeats are published before the client checks the quorum
+	for _, party := range testTopology.Parties {
+		if err := tss.PublishHeartbeat(context.Background(), redisClient, party, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go node.RunHeartbeats(ctx, testTopology.Parties, time.Second)
+
+	client, err := tss.NewTSS(redisClient, &config.TSSConfig{
+		GRPCAddress:      lis.Addr().String(),
+		Parties:          testTopology.Parties,
+		Threshold:        testTopology.Threshold,
+		QuorumCheck:      true,
+		HeartbeatTimeout: 15 * time.Second,
+		TLSMode:          tss.TLSModeInsecure,
+		ResultKeys:       map[string]string{"test-node": resultKey},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestKeygenAndSign(t *testing.T) {
+	client := startNode(t)
+	ctx := context.Background()
+
+	keyID := uuid.NewString()
+	shareData, publicKey, err := client.CreateWallet(ctx, keyID, testTopology)
+	if err != nil {
+		t.Fatalf("keygen: %v", err)
+	}
+
+	hash := crypto.Keccak256([]byte("transfer"))
+	der, err := client.Sign(ctx, uuid.NewString(), keyID, shareData, hash, testTopology)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	verifySignature(t, publicKey, hash, der)
+
+	hashes := [][]byte{crypto.Keccak256([]byte("first")), crypto.Keccak256([]byte("second"))}
+	ders, err := client.SignBatch(ctx, uuid.NewString(), keyID, shareData, hashes, testTopology)
+	if err != nil {
+		t.Fatalf("sign batch: %v", err)
+	}
+	if len(ders) != len(hashes) {
+		t.Fatalf("got %d signatures for %d hashes", len(ders), len(hashes))
+	}
+	for i := range hashes {
+		verifySignature(t, publicKey, hashes[i], ders[i])
+	}
+}
+
+func TestRefreshSharesKeepsKey(t *testing.T) {
+	client := startNode(t)
+	ctx := context.Background()
+
+	keyID := uuid.NewString()
+	oldShare, publicKey, err := client.CreateWallet(ctx, keyID, testTopology)
+	if err != nil {
+		t.Fatalf("keygen: %v", err)
+	}
+
+	newShare, newPublicKey, err := client.RefreshShares(ctx, uuid.NewString(), keyID, oldShare, testTopology)
+	if err != nil {
+		t.Fatalf("reshare: %v", err)
+	}
+	if !newPublicKey.Equal(publicKey) {
+		t.Fatal("reshare changed the public key")
+	}
+
+	hash := crypto.Keccak256([]byte("after reshare"))
+	der, err := client.Sign(ctx, uuid.NewString(), keyID, newShare, hash, testTopology)
+	if err != nil {
+		t.Fatalf("sign with new share: %v", err)
+	}
+	verifySignature(t, publicKey, hash, der)
+
+	// The share before the reshare no longer combines with the node shares
+	_, err = client.Sign(ctx, uuid.NewString(), keyID, oldShare, hash, testTopology)
+	if !errors.Is(err, tss.ErrInvalidShare) {
+		t.Fatalf("sign with old share: got %v, want %v", err, tss.ErrInvalidShare)
+	}
+}
+
+func TestKeygenWithoutQuorum(t *testing.T) {
+	client := startNode(t)
+
+	// Party 4 publishes no heartbeat
+	topology := tss.Topology{Parties: []uint32{1, 4}, Threshold: 2}
+	_, _, err := client.CreateWallet(context.Background(), uuid.NewString(), topology)
+	if !errors.Is(err, tss.ErrNoQuorum) {
+		t.Fatalf("got %v, want %v", err, tss.ErrNoQuorum)
+	}
+}
+
+func TestKeygenRejectsThresholdOne(t *testing.T) {
+	client := startNode(t)
+
+	// The client share alone would be the key
+	topology := tss.Topology{Parties: []uint32{1, 2, 3}, Threshold: 1}
+	_, _, err := client.CreateWallet(context.Background(), uuid.NewString(), topology)
+	if err == nil || !strings.Contains(err.Error(), "threshold must be at least 2") {
+		t.Fatalf("keygen with threshold 1: got %v, want it rejected", err)
+	}
+}
+
+func TestNodeSharesNeedClient(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topology := tss.Topology{Parties: []uint32{1, 2, 3, 4, 5}, Threshold: 3}
+	record, _, err := (&Server{}).splitKey(privateKey, topology.Threshold, topology.Parties)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The node keeps one share less than the threshold, none of them the client's
+	if uint32(len(record.Shares)) != topology.Threshold-1 {
+		t.Fatalf("node keeps %d shares, want %d", len(record.Shares), topology.Threshold-1)
+	}
+	if _, ok := record.Shares[record.ClientParty]; ok {
+		t.Fatal("node keeps the client share")
+	}
+}
+
+func TestStateFileEncrypted(t *testing.T) {
+	kek, err := envelope.NewAESKEK("test", make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := envelope.New(kek)
+	path := filepath.Join(t.TempDir(), "state")
+
+	if _, err := newKeyStore(path, nil); err == nil {
+		t.Fatal("opened a state file without a state key")
+	}
+
+	store, err := newKeyStore(path, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := keyRecord{Address: "0x01", Threshold: 2, Parties: []uint32{1, 2}, ClientParty: 1, Shares: map[uint32]string{2: "5ec2e7"}}
+	if err := store.put(context.Background(), "key", record); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "5ec2e7") {
+		t.Fatal("state file holds a share in plaintext")
+	}
+
+	reopened, err := newKeyStore(path, state)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got, ok := reopened.get("key"); !ok || got.Shares[2] != "5ec2e7" {
+		t.Fatalf("reopened state has %v, want the stored record", got)
+	}
+}
+
+func verifySignature(t *testing.T, publicKey *ecdsa.PublicKey, hash, der []byte) {
+	t.Helper()
+	var sig derSignature
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		t.Fatalf("invalid DER signature: %v", err)
+	}
+	if !ecdsa.Verify(publicKey, hash, sig.R, sig.S) {
+		t.Fatal("signature does not verify with the wallet key")
+	}
+}
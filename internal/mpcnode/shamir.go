@@ -0,0 +1,63 @@
+package mpcnode
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var curveN = crypto.S256().Params().N
+
+// splitSecret splits secret into one share per party so that any threshold
+// shares recover it. Each share is the value of a random polynomial of degree
+// threshold-1 at the party ID.
+func splitSecret(secret *big.Int, threshold uint32, parties []uint32) (map[uint32]*big.Int, error) {
+	coefficients := make([]*big.Int, threshold)
+	coefficients[0] = new(big.Int).Set(secret)
+	for i := 1; i < len(coefficients); i++ {
+		c, err := rand.Int(rand.Reader, curveN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate coefficient: %w", err)
+		}
+		coefficients[i] = c
+	}
+
+	shares := make(map[uint32]*big.Int, len(parties))
+	for _, party := range parties {
+		x := new(big.Int).SetUint64(uint64(party))
+		y := new(big.Int)
+		for i := len(coefficients) - 1; i >= 0; i-- {
+			y.Mul(y, x)
+			y.Add(y, coefficients[i])
+			y.Mod(y, curveN)
+		}
+		shares[party] = y
+	}
+	return shares, nil
+}
+
+// combineShares recovers the secret from shares by Lagrange interpolation at zero
+func combineShares(shares map[uint32]*big.Int) *big.Int {
+	secret := new(big.Int)
+	for i, yi := range shares {
+		xi := new(big.Int).SetUint64(uint64(i))
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			xj := new(big.Int).SetUint64(uint64(j))
+			num.Mul(num, xj)
+			num.Mod(num, curveN)
+			den.Mul(den, new(big.Int).Sub(xj, xi))
+			den.Mod(den, curveN)
+		}
+		term := new(big.Int).Mul(yi, num)
+		term.Mul(term, new(big.Int).ModInverse(den, curveN))
+		secret.Add(secret, term)
+		secret.Mod(secret, curveN)
+	}
+	return secret
+}
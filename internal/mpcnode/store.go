@@ -0,0 +1,89 @@
+package mpcnode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mpc/pkg/envelope"
+	"os"
+	"sync"
+)
+
+// stateAdditionalData binds the sealed state to its purpose
+var stateAdditionalData = []byte("mpcnode state")
+
+// keyRecord holds the node side of a key: threshold-1 shares, so the node
+// never signs without the client share
+type keyRecord struct {
+	Address     string            `json:"address"`
+	PubKey      string            `json:"pub_key"`
+	Threshold   uint32            `json:"threshold"`
+	Parties     []uint32          `json:"parties"`
+	ClientParty uint32            `json:"client_party"`
+	Shares      map[uint32]string `json:"shares"`
+}
+
+// keyStore keeps key records in memory, and in a file sealed with state when
+// path is set so keys survive a restart of the node
+type keyStore struct {
+	mu    sync.RWMutex
+	path  string
+	state *envelope.Envelope
+	keys  map[string]keyRecord
+}
+
+func newKeyStore(path string, state *envelope.Envelope) (*keyStore, error) {
+	store := &keyStore{path: path, state: state, keys: make(map[string]keyRecord)}
+	if path == "" {
+		return store, nil
+	}
+	if state == nil {
+		return nil, errors.New("the state file holds key shares and needs a state key")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	plaintext, err := state.Open(context.Background(), data, stateAdditionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt state file: %w", err)
+	}
+	if err := json.Unmarshal(plaintext, &store.keys); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	return store, nil
+}
+
+func (s *keyStore) get(keyID string) (keyRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.keys[keyID]
+	return record, ok
+}
+
+func (s *keyStore) put(ctx context.Context, keyID string, record keyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[keyID] = record
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s.keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	sealed, err := s.state.Seal(ctx, data, stateAdditionalData)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt state: %w", err)
+	}
+	if err := os.WriteFile(s.path, sealed, 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
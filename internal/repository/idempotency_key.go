@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "mpc/internal/db/sqlc"
+	"mpc/internal/model"
+	"mpc/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyKeyRepository struct {
+	queries *db.Queries
+}
+
+func NewIdempotencyKeyRepository(pool *pgxpool.Pool) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{queries: db.New(pool)}
+}
+
+// GetIdempotencyKey retrieves an unexpired idempotency key of a user. It
+// reports false when there is none.
+func (r *IdempotencyKeyRepository) GetIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) (model.IdempotencyKey, bool, error) {
+	record, err := r.queries.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		UserID:    utils.ToPgUUID(userID),
+		Key:       key,
+		ExpiresAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.IdempotencyKey{}, false, nil
+		}
+		return model.IdempotencyKey{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return toIdempotencyKeyModel(record), true, nil
+}
+
+// ClaimIdempotencyKey records a key as processing until the given time. It
+// reports false when the key is already held by an unexpired record.
+func (r *IdempotencyKeyRepository) ClaimIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, until time.Time) (model.IdempotencyKey, bool, error) {
+	record, err := r.queries.ClaimIdempotencyKey(ctx, db.ClaimIdempotencyKeyParams{
+		UserID:      utils.ToPgUUID(userID),
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   pgtype.Timestamp{Time: until, Valid: true},
+		CreatedAt:   utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.IdempotencyKey{}, false, nil
+		}
+		return model.IdempotencyKey{}, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	return toIdempotencyKeyModel(record), true, nil
+}
+
+// SetIdempotencyKeyJobs records the jobs queued for a key that is still
+// processing
+func (r *IdempotencyKeyRepository) SetIdempotencyKeyJobs(ctx context.Context, userID uuid.UUID, key string, batchID uuid.UUID, jobIDs []uuid.UUID) error {
+	ids := make([]pgtype.UUID, len(jobIDs))
+	for i, id := range jobIDs {
+		ids[i] = utils.ToPgUUID(id)
+	}
+	err := r.queries.SetIdempotencyKeyJobs(ctx, db.SetIdempotencyKeyJobsParams{
+		UserID:    utils.ToPgUUID(userID),
+		Key:       key,
+		BatchID:   toNullablePgUUID(batchID),
+		JobIds:    ids,
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set idempotency key jobs: %w", err)
+	}
+	return nil
+}
+
+// CompleteIdempotencyKey records the jobs queued for a key, kept until the
+// given time
+func (r *IdempotencyKeyRepository) CompleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, batchID uuid.UUID, jobIDs []uuid.UUID, until time.Time) error {
+	ids := make([]pgtype.UUID, len(jobIDs))
+	for i, id := range jobIDs {
+		ids[i] = utils.ToPgUUID(id)
+	}
+
+	err := r.queries.CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+		UserID:    utils.ToPgUUID(userID),
+		Key:       key,
+		BatchID:   toNullablePgUUID(batchID),
+		JobIds:    ids,
+		ExpiresAt: pgtype.Timestamp{Time: until, Valid: true},
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey frees a key that is still processing
+func (r *IdempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error {
+	err := r.queries.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{
+		UserID: utils.ToPgUUID(userID),
+		Key:    key,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// toIdempotencyKeyModel converts a sqlc idempotency key to a model idempotency key
+func toIdempotencyKeyModel(sqlcKey db.IdempotencyKey) model.IdempotencyKey {
+	jobIDs := make([]uuid.UUID, len(sqlcKey.JobIds))
+	for i, id := range sqlcKey.JobIds {
+		jobIDs[i] = utils.ToUUID(id)
+	}
+
+	return model.IdempotencyKey{
+		UserID:      utils.ToUUID(sqlcKey.UserID),
+		Key:         sqlcKey.Key,
+		Fingerprint: sqlcKey.Fingerprint,
+		Status:      sqlcKey.Status,
+		BatchID:     utils.ToUUID(sqlcKey.BatchID),
+		JobIDs:      jobIDs,
+		ExpiresAt:   sqlcKey.ExpiresAt.Time,
+		CreatedAt:   sqlcKey.CreatedAt.Time,
+		UpdatedAt:   sqlcKey.UpdatedAt.Time,
+	}
+}
@@ -6,8 +6,10 @@ import (
 	db "mpc/internal/db/sqlc"
 	"mpc/internal/model"
 	"mpc/pkg/utils"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,13 +21,22 @@ func NewTransactionRepository(pool *pgxpool.Pool) *TransactionRepository {
 	return &TransactionRepository{queries: db.New(pool)}
 }
 
-// CreateTransaction creates a new transaction
+// CreateTransaction creates a new transaction. When the worker or the API
+// already recorded its hash on the chain, the existing row is returned with
+// the token, amount and replaced transaction filled in where it lacked them.
 func (r *TransactionRepository) CreateTransaction(ctx context.Context, transaction model.Transaction) (model.Transaction, error) {
 	tx, err := r.queries.CreateTransaction(ctx, db.CreateTransactionParams{
 		ChainID:     int32(transaction.ChainID),
 		FromAddress: transaction.FromAddress,
 		ToAddress:   transaction.ToAddress,
 		TxHash:      transaction.TxHash,
+		TokenID:     toNullablePgUUID(transaction.TokenID),
+		Status:      transaction.Status,
+		Nonce:       toNullablePgInt8(transaction.Nonce),
+		Value:       toNullablePgText(transaction.Value),
+		Amount:      toNullablePgText(transaction.Amount),
+		BlockNumber: toNullablePgInt8(transaction.BlockNumber),
+		ReplacesID:  toNullablePgUUID(transaction.ReplacesID),
 		CreatedAt:   utils.CurrentPgTimestamp(),
 		UpdatedAt:   utils.CurrentPgTimestamp(),
 	})
@@ -63,6 +74,18 @@ func (r *TransactionRepository) GetTransactionByID(ctx context.Context, id uuid.
 	return toTransactionModel(transaction), nil
 }
 
+// GetTransactionByTxHash retrieves a transaction of a chain by its hash
+func (r *TransactionRepository) GetTransactionByTxHash(ctx context.Context, chainID int, txHash string) (model.Transaction, error) {
+	transaction, err := r.queries.GetTransactionByTxHash(ctx, db.GetTransactionByTxHashParams{
+		ChainID: int32(chainID),
+		TxHash:  txHash,
+	})
+	if err != nil {
+		return model.Transaction{}, fmt.Errorf("failed to get transaction by hash: %w", err)
+	}
+	return toTransactionModel(transaction), nil
+}
+
 // GetTransactionCount retrieves the number of transactions for a given wallet ID and chain ID
 func (r *TransactionRepository) GetTransactionCount(ctx context.Context, walletAddress string, chainID int) (int, error) {
 	count, err := r.queries.GetTransactionCount(ctx, db.GetTransactionCountParams{
@@ -75,15 +98,117 @@ func (r *TransactionRepository) GetTransactionCount(ctx context.Context, walletA
 	return int(count), nil
 }
 
+// GetTrackedTransactions retrieves up to limit transactions that are not
+// final yet, oldest first, starting after the one created at afterCreatedAt
+// with afterID. The zero values start from the oldest.
+func (r *TransactionRepository) GetTrackedTransactions(
+	ctx context.Context,
+	afterCreatedAt time.Time,
+	afterID uuid.UUID,
+	limit int,
+) ([]model.Transaction, error) {
+	transactions, err := r.queries.GetTrackedTransactions(ctx, db.GetTrackedTransactionsParams{
+		CreatedAt: pgtype.Timestamp{Time: afterCreatedAt, Valid: true},
+		ID:        utils.ToPgUUID(afterID),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked transactions: %w", err)
+	}
+
+	var result []model.Transaction
+	for _, tx := range transactions {
+		result = append(result, toTransactionModel(tx))
+	}
+	return result, nil
+}
+
+// UpdateTransactionStatus updates the status of a transaction and the block
+// it was mined in
+func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, transaction model.Transaction) error {
+	err := r.queries.UpdateTransactionStatus(ctx, db.UpdateTransactionStatusParams{
+		ID:                utils.ToPgUUID(transaction.ID),
+		Status:            transaction.Status,
+		BlockNumber:       toNullablePgInt8(transaction.BlockNumber),
+		BlockHash:         toNullablePgText(transaction.BlockHash),
+		GasUsed:           toNullablePgInt8(transaction.GasUsed),
+		EffectiveGasPrice: toNullablePgText(transaction.EffectiveGasPrice),
+		Fee:               toNullablePgText(transaction.Fee),
+		Confirmations:     int32(transaction.Confirmations),
+		NonceUsedBlock:    toNullablePgInt8(transaction.NonceUsedBlock),
+		UpdatedAt:         utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	return nil
+}
+
+// MarkTransactionReplaced marks a submitted or pending transaction replaced
+// by a speed-up or cancel
+func (r *TransactionRepository) MarkTransactionReplaced(ctx context.Context, id uuid.UUID) error {
+	err := r.queries.MarkTransactionReplaced(ctx, db.MarkTransactionReplacedParams{
+		ID:        utils.ToPgUUID(id),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark transaction replaced: %w", err)
+	}
+	return nil
+}
+
+// RestoreReplacedTransaction makes a replaced transaction submitted again, so
+// it is tracked once more
+func (r *TransactionRepository) RestoreReplacedTransaction(ctx context.Context, id uuid.UUID) error {
+	err := r.queries.RestoreReplacedTransaction(ctx, db.RestoreReplacedTransactionParams{
+		ID:        utils.ToPgUUID(id),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore replaced transaction: %w", err)
+	}
+	return nil
+}
+
 // toTransactionModel converts a sqlc transaction to a model transaction
 func toTransactionModel(sqlcTransaction db.Transaction) model.Transaction {
 	return model.Transaction{
-		ID:          utils.ToUUID(sqlcTransaction.ID),
-		ChainID:     int(sqlcTransaction.ChainID),
-		FromAddress: sqlcTransaction.FromAddress,
-		ToAddress:   sqlcTransaction.ToAddress,
-		TxHash:      sqlcTransaction.TxHash,
-		CreatedAt:   sqlcTransaction.CreatedAt.Time,
-		UpdatedAt:   sqlcTransaction.UpdatedAt.Time,
+		ID:                utils.ToUUID(sqlcTransaction.ID),
+		ChainID:           int(sqlcTransaction.ChainID),
+		FromAddress:       sqlcTransaction.FromAddress,
+		ToAddress:         sqlcTransaction.ToAddress,
+		TxHash:            sqlcTransaction.TxHash,
+		TokenID:           utils.ToUUID(sqlcTransaction.TokenID),
+		Status:            sqlcTransaction.Status,
+		Nonce:             toUint64Ptr(sqlcTransaction.Nonce),
+		Value:             utils.ToText(sqlcTransaction.Value),
+		Amount:            utils.ToText(sqlcTransaction.Amount),
+		BlockNumber:       toUint64Ptr(sqlcTransaction.BlockNumber),
+		BlockHash:         utils.ToText(sqlcTransaction.BlockHash),
+		GasUsed:           toUint64Ptr(sqlcTransaction.GasUsed),
+		EffectiveGasPrice: utils.ToText(sqlcTransaction.EffectiveGasPrice),
+		Fee:               utils.ToText(sqlcTransaction.Fee),
+		Confirmations:     int(sqlcTransaction.Confirmations),
+		ReplacesID:        utils.ToUUID(sqlcTransaction.ReplacesID),
+		NonceUsedBlock:    toUint64Ptr(sqlcTransaction.NonceUsedBlock),
+		CreatedAt:         sqlcTransaction.CreatedAt.Time,
+		UpdatedAt:         sqlcTransaction.UpdatedAt.Time,
+	}
+}
+
+// toNullablePgInt8 converts a *uint64 to pgtype.Int8, treating nil as NULL
+func toNullablePgInt8(n *uint64) pgtype.Int8 {
+	if n == nil {
+		return pgtype.Int8{Valid: false}
+	}
+	return pgtype.Int8{Int64: int64(*n), Valid: true}
+}
+
+// toUint64Ptr converts a pgtype.Int8 to *uint64, returning nil for NULL
+func toUint64Ptr(n pgtype.Int8) *uint64 {
+	if !n.Valid {
+		return nil
 	}
+	v := uint64(n.Int64)
+	return &v
 }
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "mpc/internal/db/sqlc"
+	"mpc/internal/model"
+	"mpc/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TransactionJobRepository struct {
+	queries *db.Queries
+}
+
+func NewTransactionJobRepository(pool *pgxpool.Pool) *TransactionJobRepository {
+	return &TransactionJobRepository{queries: db.New(pool)}
+}
+
+// CreateTransactionJob creates a new transaction job
+func (r *TransactionJobRepository) CreateTransactionJob(ctx context.Context, job model.TransactionJob) (model.TransactionJob, error) {
+	created, err := r.queries.CreateTransactionJob(ctx, db.CreateTransactionJobParams{
+		ID:             utils.ToPgUUID(job.ID),
+		UserID:         utils.ToPgUUID(job.UserID),
+		WalletID:       utils.ToPgUUID(job.WalletID),
+		Status:         job.Status,
+		ChainID:        int32(job.ChainID),
+		FromAddress:    job.FromAddress,
+		ToAddress:      job.ToAddress,
+		Symbol:         job.Symbol,
+		Amount:         job.Amount,
+		BatchID:        toNullablePgUUID(job.BatchID),
+		Type:           job.Type,
+		ReplacesID:     toNullablePgUUID(job.ReplacesID),
+		OwnerID:        toNullablePgText(job.OwnerID),
+		LeaseExpiresAt: toNullablePgTimestamp(job.LeaseExpiresAt),
+		CreatedAt:      utils.CurrentPgTimestamp(),
+		UpdatedAt:      utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return model.TransactionJob{}, fmt.Errorf("failed to create transaction job: %w", err)
+	}
+	return toTransactionJobModel(created), nil
+}
+
+// CreateReplacementJob creates the job of a speed-up or cancel. It reports
+// false, and creates nothing, when a replacement of the same transaction is
+// already queued or running.
+func (r *TransactionJobRepository) CreateReplacementJob(ctx context.Context, job model.TransactionJob) (model.TransactionJob, bool, error) {
+	created, err := r.queries.CreateReplacementJob(ctx, db.CreateReplacementJobParams{
+		ID:             utils.ToPgUUID(job.ID),
+		UserID:         utils.ToPgUUID(job.UserID),
+		WalletID:       utils.ToPgUUID(job.WalletID),
+		Status:         job.Status,
+		ChainID:        int32(job.ChainID),
+		FromAddress:    job.FromAddress,
+		ToAddress:      job.ToAddress,
+		Symbol:         job.Symbol,
+		Amount:         job.Amount,
+		BatchID:        toNullablePgUUID(job.BatchID),
+		Type:           job.Type,
+		ReplacesID:     toNullablePgUUID(job.ReplacesID),
+		OwnerID:        toNullablePgText(job.OwnerID),
+		LeaseExpiresAt: toNullablePgTimestamp(job.LeaseExpiresAt),
+		CreatedAt:      utils.CurrentPgTimestamp(),
+		UpdatedAt:      utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.TransactionJob{}, false, nil
+		}
+		return model.TransactionJob{}, false, fmt.Errorf("failed to create replacement job: %w", err)
+	}
+	return toTransactionJobModel(created), true, nil
+}
+
+// GetTransactionJobByID retrieves a transaction job by its ID
+func (r *TransactionJobRepository) GetTransactionJobByID(ctx context.Context, id uuid.UUID) (model.TransactionJob, error) {
+	job, err := r.queries.GetTransactionJobByID(ctx, utils.ToPgUUID(id))
+	if err != nil {
+		return model.TransactionJob{}, fmt.Errorf("failed to get transaction job by ID: %w", err)
+	}
+	return toTransactionJobModel(job), nil
+}
+
+// StartTransactionJob marks a queued transaction job as picked up by a
+// worker. It reports false, and changes nothing, when the job is no longer
+// queued, e.g. because it was failed after its owner's lease expired.
+func (r *TransactionJobRepository) StartTransactionJob(ctx context.Context, id uuid.UUID) (model.TransactionJob, bool, error) {
+	job, err := r.queries.StartTransactionJob(ctx, db.StartTransactionJobParams{
+		ID:        utils.ToPgUUID(id),
+		Status:    model.TransactionJobStatusRunning,
+		StartedAt: utils.CurrentPgTimestamp(),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.TransactionJob{}, false, nil
+		}
+		return model.TransactionJob{}, false, fmt.Errorf("failed to start transaction job: %w", err)
+	}
+	return toTransactionJobModel(job), true, nil
+}
+
+// FinishTransactionJob records the final status of a transaction job. It
+// reports false, and changes nothing, when the job is already finished.
+func (r *TransactionJobRepository) FinishTransactionJob(
+	ctx context.Context,
+	id uuid.UUID,
+	status string,
+	transactionID uuid.UUID,
+	errorMessage, errorCode string,
+) (model.TransactionJob, bool, error) {
+	job, err := r.queries.FinishTransactionJob(ctx, db.FinishTransactionJobParams{
+		ID:            utils.ToPgUUID(id),
+		Status:        status,
+		TransactionID: toNullablePgUUID(transactionID),
+		Error:         toNullablePgText(errorMessage),
+		ErrorCode:     toNullablePgText(errorCode),
+		EndedAt:       utils.CurrentPgTimestamp(),
+		UpdatedAt:     utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.TransactionJob{}, false, nil
+		}
+		return model.TransactionJob{}, false, fmt.Errorf("failed to finish transaction job: %w", err)
+	}
+	return toTransactionJobModel(job), true, nil
+}
+
+// RenewTransactionJobLeases extends the lease of the unfinished jobs of an
+// owner until the given time
+func (r *TransactionJobRepository) RenewTransactionJobLeases(ctx context.Context, ownerID string, until time.Time) error {
+	err := r.queries.RenewTransactionJobLeases(ctx, db.RenewTransactionJobLeasesParams{
+		OwnerID:        utils.ToPgText(ownerID),
+		LeaseExpiresAt: pgtype.Timestamp{Time: until, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to renew transaction job leases: %w", err)
+	}
+	return nil
+}
+
+// FailOwnedTransactionJobs fails the queued and running jobs of an owner
+func (r *TransactionJobRepository) FailOwnedTransactionJobs(ctx context.Context, ownerID, errorMessage string) error {
+	err := r.queries.FailOwnedTransactionJobs(ctx, db.FailOwnedTransactionJobsParams{
+		OwnerID: utils.ToPgText(ownerID),
+		Status:  model.TransactionJobStatusFailed,
+		Error:   toNullablePgText(errorMessage),
+		EndedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fail owned transaction jobs: %w", err)
+	}
+	return nil
+}
+
+// FailExpiredTransactionJobs fails the queued and running jobs whose lease
+// has expired, or that have none
+func (r *TransactionJobRepository) FailExpiredTransactionJobs(ctx context.Context, errorMessage string) error {
+	err := r.queries.FailExpiredTransactionJobs(ctx, db.FailExpiredTransactionJobsParams{
+		Status:  model.TransactionJobStatusFailed,
+		Error:   toNullablePgText(errorMessage),
+		EndedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fail expired transaction jobs: %w", err)
+	}
+	return nil
+}
+
+// toTransactionJobModel converts a sqlc transaction job to a model transaction job
+func toTransactionJobModel(sqlcJob db.TransactionJob) model.TransactionJob {
+	return model.TransactionJob{
+		ID:             utils.ToUUID(sqlcJob.ID),
+		UserID:         utils.ToUUID(sqlcJob.UserID),
+		WalletID:       utils.ToUUID(sqlcJob.WalletID),
+		Status:         sqlcJob.Status,
+		ChainID:        int(sqlcJob.ChainID),
+		FromAddress:    sqlcJob.FromAddress,
+		ToAddress:      sqlcJob.ToAddress,
+		Symbol:         sqlcJob.Symbol,
+		Amount:         sqlcJob.Amount,
+		TransactionID:  utils.ToUUID(sqlcJob.TransactionID),
+		Error:          utils.ToText(sqlcJob.Error),
+		ErrorCode:      utils.ToText(sqlcJob.ErrorCode),
+		BatchID:        utils.ToUUID(sqlcJob.BatchID),
+		Type:           sqlcJob.Type,
+		ReplacesID:     utils.ToUUID(sqlcJob.ReplacesID),
+		StartedAt:      toTimePtr(sqlcJob.StartedAt),
+		EndedAt:        toTimePtr(sqlcJob.EndedAt),
+		CreatedAt:      sqlcJob.CreatedAt.Time,
+		UpdatedAt:      sqlcJob.UpdatedAt.Time,
+		OwnerID:        utils.ToText(sqlcJob.OwnerID),
+		LeaseExpiresAt: toTimePtr(sqlcJob.LeaseExpiresAt),
+	}
+}
+
+// toNullablePgTimestamp converts an optional time to a pgtype.Timestamp
+func toNullablePgTimestamp(t *time.Time) pgtype.Timestamp {
+	if t == nil {
+		return pgtype.Timestamp{Valid: false}
+	}
+	return pgtype.Timestamp{Time: *t, Valid: true}
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	db "mpc/internal/db/sqlc"
+	"mpc/internal/model"
+	"mpc/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TSSSessionRepository struct {
+	queries *db.Queries
+}
+
+func NewTSSSessionRepository(pool *pgxpool.Pool) *TSSSessionRepository {
+	return &TSSSessionRepository{queries: db.New(pool)}
+}
+
+// CreateTSSSession creates a new TSS session
+func (r *TSSSessionRepository) CreateTSSSession(ctx context.Context, session model.TSSSession) (model.TSSSession, error) {
+	created, err := r.queries.CreateTSSSession(ctx, db.CreateTSSSessionParams{
+		ID:          utils.ToPgUUID(session.ID),
+		UserID:      utils.ToPgUUID(session.UserID),
+		WalletID:    toNullablePgUUID(session.WalletID),
+		Type:        session.Type,
+		Status:      session.Status,
+		MessageHash: toNullablePgText(session.MessageHash),
+		Parties:     utils.ToInt32Slice(session.Parties),
+		Threshold:   int32(session.Threshold),
+		StartedAt:   utils.CurrentPgTimestamp(),
+		CreatedAt:   utils.CurrentPgTimestamp(),
+		UpdatedAt:   utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return model.TSSSession{}, fmt.Errorf("failed to create tss session: %w", err)
+	}
+	return toTSSSessionModel(created), nil
+}
+
+// GetTSSSessionByID retrieves a TSS session by its ID
+func (r *TSSSessionRepository) GetTSSSessionByID(ctx context.Context, id uuid.UUID) (model.TSSSession, error) {
+	session, err := r.queries.GetTSSSessionByID(ctx, utils.ToPgUUID(id))
+	if err != nil {
+		return model.TSSSession{}, fmt.Errorf("failed to get tss session by ID: %w", err)
+	}
+	return toTSSSessionModel(session), nil
+}
+
+// FinishTSSSession records the final status of a TSS session
+func (r *TSSSessionRepository) FinishTSSSession(ctx context.Context, id uuid.UUID, status, errorMessage, errorCode string) (model.TSSSession, error) {
+	session, err := r.queries.FinishTSSSession(ctx, db.FinishTSSSessionParams{
+		ID:        utils.ToPgUUID(id),
+		Status:    status,
+		Error:     toNullablePgText(errorMessage),
+		ErrorCode: toNullablePgText(errorCode),
+		EndedAt:   utils.CurrentPgTimestamp(),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return model.TSSSession{}, fmt.Errorf("failed to finish tss session: %w", err)
+	}
+	return toTSSSessionModel(session), nil
+}
+
+// SetTSSSessionWallet links a keygen session to the wallet it created
+func (r *TSSSessionRepository) SetTSSSessionWallet(ctx context.Context, id uuid.UUID, walletID uuid.UUID) error {
+	err := r.queries.SetTSSSessionWallet(ctx, db.SetTSSSessionWalletParams{
+		ID:        utils.ToPgUUID(id),
+		WalletID:  utils.ToPgUUID(walletID),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set tss session wallet: %w", err)
+	}
+	return nil
+}
+
+// toTSSSessionModel converts a sqlc tss session to a model tss session
+func toTSSSessionModel(sqlcSession db.TssSession) model.TSSSession {
+	return model.TSSSession{
+		ID:          utils.ToUUID(sqlcSession.ID),
+		UserID:      utils.ToUUID(sqlcSession.UserID),
+		WalletID:    utils.ToUUID(sqlcSession.WalletID),
+		Type:        sqlcSession.Type,
+		Status:      sqlcSession.Status,
+		MessageHash: utils.ToText(sqlcSession.MessageHash),
+		Parties:     utils.ToUint32Slice(sqlcSession.Parties),
+		Threshold:   uint32(sqlcSession.Threshold),
+		Error:       utils.ToText(sqlcSession.Error),
+		ErrorCode:   utils.ToText(sqlcSession.ErrorCode),
+		StartedAt:   sqlcSession.StartedAt.Time,
+		EndedAt:     toTimePtr(sqlcSession.EndedAt),
+		CreatedAt:   sqlcSession.CreatedAt.Time,
+		UpdatedAt:   sqlcSession.UpdatedAt.Time,
+	}
+}
+
+// toNullablePgUUID converts a UUID to pgtype.UUID, treating the nil UUID as NULL
+func toNullablePgUUID(id uuid.UUID) pgtype.UUID {
+	if id == uuid.Nil {
+		return pgtype.UUID{Valid: false}
+	}
+	return utils.ToPgUUID(id)
+}
+
+// toNullablePgText converts a string to pgtype.Text, treating "" as NULL
+func toNullablePgText(text string) pgtype.Text {
+	if text == "" {
+		return pgtype.Text{Valid: false}
+	}
+	return utils.ToPgText(text)
+}
+
+// toTimePtr converts a pgtype.Timestamp to *time.Time, returning nil for NULL
+func toTimePtr(ts pgtype.Timestamp) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
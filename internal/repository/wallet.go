@@ -21,20 +21,23 @@ func NewWalletRepository(pool *pgxpool.Pool) *WalletRepository {
 }
 
 // CreateWallet creates a new wallet
-func (r *WalletRepository) CreateWallet(ctx context.Context, userID uuid.UUID, address string, encryptedPrivateKey []byte, name string) (model.Wallet, error) {
-	wallet, err := r.queries.CreateWallet(ctx, db.CreateWalletParams{
-		UserID:              utils.ToPgUUID(userID),
-		Address:             address,
-		EncryptedPrivateKey: encryptedPrivateKey,
-		Name:                utils.ToPgText(name),
+func (r *WalletRepository) CreateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	created, err := r.queries.CreateWallet(ctx, db.CreateWalletParams{
+		UserID:              utils.ToPgUUID(wallet.UserID),
+		Address:             wallet.Address,
+		EncryptedPrivateKey: []byte(wallet.EncryptedPrivateKey),
+		Name:                utils.ToPgText(wallet.Name),
 		Status:              "active",
+		Parties:             utils.ToInt32Slice(wallet.Parties),
+		Threshold:           int32(wallet.Threshold),
+		KeyID:               wallet.KeyID,
 		CreatedAt:           utils.CurrentPgTimestamp(),
 		UpdatedAt:           utils.CurrentPgTimestamp(),
 	})
 	if err != nil {
 		return model.Wallet{}, fmt.Errorf("failed to create wallet: %w", err)
 	}
-	return toWalletModel(wallet), nil
+	return toWalletModel(created), nil
 }
 
 // GetWalletByID retrieves a wallet by its ID
@@ -68,6 +71,36 @@ func (r *WalletRepository) GetWalletByAddress(ctx context.Context, address strin
 	return toWalletModel(wallet), nil
 }
 
+// UpdateWallet updates a wallet
+func (r *WalletRepository) UpdateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	updatedWallet, err := r.queries.UpdateWallet(ctx, db.UpdateWalletParams{
+		ID:                  utils.ToPgUUID(wallet.ID),
+		Address:             wallet.Address,
+		EncryptedPrivateKey: []byte(wallet.EncryptedPrivateKey),
+		Name:                utils.ToPgText(wallet.Name),
+		Status:              wallet.Status,
+		UpdatedAt:           utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return model.Wallet{}, fmt.Errorf("failed to update wallet: %w", err)
+	}
+	return toWalletModel(updatedWallet), nil
+}
+
+// RotateWalletShare stores the encrypted share of a wallet after a reshare,
+// empty for non-custodial wallets, and bumps its share version
+func (r *WalletRepository) RotateWalletShare(ctx context.Context, id uuid.UUID, encryptedShare string) (model.Wallet, error) {
+	wallet, err := r.queries.RotateWalletShare(ctx, db.RotateWalletShareParams{
+		ID:                  utils.ToPgUUID(id),
+		EncryptedPrivateKey: []byte(encryptedShare),
+		UpdatedAt:           utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return model.Wallet{}, fmt.Errorf("failed to rotate wallet share: %w", err)
+	}
+	return toWalletModel(wallet), nil
+}
+
 // GetAllAddresses retrieves all addresses
 func (r *WalletRepository) GetAllAddresses(ctx context.Context) ([]string, error) {
 	addresses, err := r.queries.GetAllAddresses(ctx)
@@ -86,6 +119,10 @@ func toWalletModel(sqlcWallet db.Wallet) model.Wallet {
 		EncryptedPrivateKey: string(sqlcWallet.EncryptedPrivateKey),
 		Name:                utils.ToText(sqlcWallet.Name),
 		Status:              sqlcWallet.Status,
+		Parties:             utils.ToUint32Slice(sqlcWallet.Parties),
+		Threshold:           uint32(sqlcWallet.Threshold),
+		KeyID:               sqlcWallet.KeyID,
+		ShareVersion:        int(sqlcWallet.ShareVersion),
 		CreatedAt:           sqlcWallet.CreatedAt.Time,
 		UpdatedAt:           sqlcWallet.UpdatedAt.Time,
 	}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	db "mpc/internal/db/sqlc"
+	"mpc/internal/model"
+	"mpc/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WalletAuditEventRepository struct {
+	queries *db.Queries
+}
+
+func NewWalletAuditEventRepository(pool *pgxpool.Pool) *WalletAuditEventRepository {
+	return &WalletAuditEventRepository{queries: db.New(pool)}
+}
+
+// CreateWalletAuditEvent records an audited wallet operation
+func (r *WalletAuditEventRepository) CreateWalletAuditEvent(ctx context.Context, event model.WalletAuditEvent) (model.WalletAuditEvent, error) {
+	created, err := r.queries.CreateWalletAuditEvent(ctx, db.CreateWalletAuditEventParams{
+		ID:        utils.ToPgUUID(event.ID),
+		UserID:    utils.ToPgUUID(event.UserID),
+		WalletID:  utils.ToPgUUID(event.WalletID),
+		Action:    event.Action,
+		Status:    event.Status,
+		Error:     toNullablePgText(event.Error),
+		ErrorCode: toNullablePgText(event.ErrorCode),
+		IpAddress: toNullablePgText(event.IPAddress),
+		UserAgent: toNullablePgText(event.UserAgent),
+		CreatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return model.WalletAuditEvent{}, fmt.Errorf("failed to create wallet audit event: %w", err)
+	}
+	return toWalletAuditEventModel(created), nil
+}
+
+// GetWalletAuditEventsByWalletID lists the audit events of a wallet, newest first
+func (r *WalletAuditEventRepository) GetWalletAuditEventsByWalletID(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]model.WalletAuditEvent, error) {
+	events, err := r.queries.GetWalletAuditEventsByWalletID(ctx, db.GetWalletAuditEventsByWalletIDParams{
+		WalletID: utils.ToPgUUID(walletID),
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet audit events: %w", err)
+	}
+
+	result := make([]model.WalletAuditEvent, len(events))
+	for i, event := range events {
+		result[i] = toWalletAuditEventModel(event)
+	}
+	return result, nil
+}
+
+func toWalletAuditEventModel(sqlcEvent db.WalletAuditEvent) model.WalletAuditEvent {
+	return model.WalletAuditEvent{
+		ID:        utils.ToUUID(sqlcEvent.ID),
+		UserID:    utils.ToUUID(sqlcEvent.UserID),
+		WalletID:  utils.ToUUID(sqlcEvent.WalletID),
+		Action:    sqlcEvent.Action,
+		Status:    sqlcEvent.Status,
+		Error:     utils.ToText(sqlcEvent.Error),
+		ErrorCode: utils.ToText(sqlcEvent.ErrorCode),
+		IPAddress: utils.ToText(sqlcEvent.IpAddress),
+		UserAgent: utils.ToText(sqlcEvent.UserAgent),
+		CreatedAt: sqlcEvent.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	db "mpc/internal/db/sqlc"
+	"mpc/internal/model"
+	"mpc/pkg/utils"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WalletNonceRepository struct {
+	queries *db.Queries
+}
+
+func NewWalletNonceRepository(pool *pgxpool.Pool) *WalletNonceRepository {
+	return &WalletNonceRepository{queries: db.New(pool)}
+}
+
+// GetWalletNonces lists the nonces handed out for an address, lowest first
+func (r *WalletNonceRepository) GetWalletNonces(ctx context.Context, chainID int, address string) ([]model.WalletNonce, error) {
+	nonces, err := r.queries.GetWalletNonces(ctx, db.GetWalletNoncesParams{
+		ChainID: int32(chainID),
+		Address: address,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet nonces: %w", err)
+	}
+
+	result := make([]model.WalletNonce, len(nonces))
+	for i, nonce := range nonces {
+		result[i] = toWalletNonceModel(nonce)
+	}
+	return result, nil
+}
+
+// ReserveWalletNonce records a nonce as reserved until the given time
+func (r *WalletNonceRepository) ReserveWalletNonce(ctx context.Context, chainID int, address string, nonce uint64, until time.Time) (model.WalletNonce, error) {
+	reserved, err := r.queries.ReserveWalletNonce(ctx, db.ReserveWalletNonceParams{
+		ChainID:       int32(chainID),
+		Address:       address,
+		Nonce:         int64(nonce),
+		ReservedUntil: pgtype.Timestamp{Time: until, Valid: true},
+		CreatedAt:     utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return model.WalletNonce{}, fmt.Errorf("failed to reserve wallet nonce: %w", err)
+	}
+	return toWalletNonceModel(reserved), nil
+}
+
+// MarkWalletNonceSent records the transaction broadcast with a nonce
+func (r *WalletNonceRepository) MarkWalletNonceSent(ctx context.Context, chainID int, address string, nonce uint64, txHash string) error {
+	err := r.queries.MarkWalletNonceSent(ctx, db.MarkWalletNonceSentParams{
+		ChainID:   int32(chainID),
+		Address:   address,
+		Nonce:     int64(nonce),
+		TxHash:    toNullablePgText(txHash),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark wallet nonce sent: %w", err)
+	}
+	return nil
+}
+
+// ReleaseWalletNonce frees a reserved nonce that was not broadcast
+func (r *WalletNonceRepository) ReleaseWalletNonce(ctx context.Context, chainID int, address string, nonce uint64) error {
+	err := r.queries.ReleaseWalletNonce(ctx, db.ReleaseWalletNonceParams{
+		ChainID:   int32(chainID),
+		Address:   address,
+		Nonce:     int64(nonce),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release wallet nonce: %w", err)
+	}
+	return nil
+}
+
+// ReleaseDroppedWalletNonce frees a sent nonce whose transaction was dropped
+// without being mined
+func (r *WalletNonceRepository) ReleaseDroppedWalletNonce(ctx context.Context, chainID int, address string, nonce uint64, txHash string) error {
+	err := r.queries.ReleaseDroppedWalletNonce(ctx, db.ReleaseDroppedWalletNonceParams{
+		ChainID:   int32(chainID),
+		Address:   address,
+		Nonce:     int64(nonce),
+		TxHash:    utils.ToPgText(txHash),
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release dropped wallet nonce: %w", err)
+	}
+	return nil
+}
+
+// ExpireWalletNonces frees the reservations that outlived their deadline
+func (r *WalletNonceRepository) ExpireWalletNonces(ctx context.Context, chainID int, address string) error {
+	err := r.queries.ExpireWalletNonces(ctx, db.ExpireWalletNoncesParams{
+		ChainID:   int32(chainID),
+		Address:   address,
+		UpdatedAt: utils.CurrentPgTimestamp(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to expire wallet nonces: %w", err)
+	}
+	return nil
+}
+
+// PruneWalletNonces deletes the nonces below confirmed, which are mined, and
+// the released nonces below pending, which were taken by another transaction
+func (r *WalletNonceRepository) PruneWalletNonces(ctx context.Context, chainID int, address string, confirmed, pending uint64) error {
+	err := r.queries.PruneWalletNonces(ctx, db.PruneWalletNoncesParams{
+		ChainID: int32(chainID),
+		Address: address,
+		Nonce:   int64(confirmed),
+		Nonce_2: int64(pending),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune wallet nonces: %w", err)
+	}
+	return nil
+}
+
+func toWalletNonceModel(sqlcNonce db.WalletNonce) model.WalletNonce {
+	return model.WalletNonce{
+		ChainID:       int(sqlcNonce.ChainID),
+		Address:       sqlcNonce.Address,
+		Nonce:         uint64(sqlcNonce.Nonce),
+		Status:        sqlcNonce.Status,
+		TxHash:        utils.ToText(sqlcNonce.TxHash),
+		ReservedUntil: toTimePtr(sqlcNonce.ReservedUntil),
+		CreatedAt:     sqlcNonce.CreatedAt.Time,
+		UpdatedAt:     sqlcNonce.UpdatedAt.Time,
+	}
+}
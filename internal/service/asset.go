@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"mpc/internal/db/redis"
 	"mpc/internal/model"
-	"mpc/internal/repository"
 	"mpc/pkg/cache"
 	"mpc/pkg/errors"
 	"mpc/pkg/utils"
@@ -14,12 +13,12 @@ import (
 )
 
 type AssetService struct {
-	chainRepo *repository.ChainRepository
-	tokenRepo *repository.TokenRepository
+	chainRepo ChainStore
+	tokenRepo TokenStore
 	cache     *cache.Cache
 }
 
-func NewAssetService(chainRepo *repository.ChainRepository, tokenRepo *repository.TokenRepository, redisClient *redis.Client) *AssetService {
+func NewAssetService(chainRepo ChainStore, tokenRepo TokenStore, redisClient *redis.Client) *AssetService {
 	return &AssetService{
 		chainRepo: chainRepo,
 		tokenRepo: tokenRepo,
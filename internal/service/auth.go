@@ -52,6 +52,11 @@ func (s *AuthService) GoogleOauth(ctx context.Context, req *model.GoogleOauth) (
 		logger.Error("Failed to fetch user info", err)
 	}
 	if user.ID == uuid.Nil {
+		topology, err := s.walletService.ResolveTopology(req.Parties, req.Threshold)
+		if err != nil {
+			return model.AuthResponse{}, err
+		}
+
 		// Create user and wallet
 		user, err = s.userService.CreateUser(ctx, userInfo.Email, "")
 		if err != nil {
@@ -59,7 +64,7 @@ func (s *AuthService) GoogleOauth(ctx context.Context, req *model.GoogleOauth) (
 			return model.AuthResponse{}, err
 		}
 
-		wallet, _, err = s.walletService.CreateWallet(ctx, user.ID)
+		wallet, _, err = s.walletService.CreateWallet(ctx, user.ID, topology)
 		if err != nil {
 			logger.Error("Service:GoogleOauth", err)
 			return model.AuthResponse{}, err
@@ -130,6 +135,11 @@ func (s *AuthService) Signup(ctx context.Context, req *model.SignupRequest) (mod
 		return model.SignupResponse{}, errors.ErrEmailAlreadyInUse
 	}
 
+	topology, err := s.walletService.ResolveTopology(req.Parties, req.Threshold)
+	if err != nil {
+		return model.SignupResponse{}, err
+	}
+
 	// Hash password
 	hashedPassword, err := hashPassword(req.Password)
 	if err != nil {
@@ -144,7 +154,7 @@ func (s *AuthService) Signup(ctx context.Context, req *model.SignupRequest) (mod
 		return model.SignupResponse{}, err
 	}
 
-	wallet, shareData, err := s.walletService.CreateWallet(ctx, user.ID)
+	wallet, shareData, err := s.walletService.CreateWallet(ctx, user.ID, topology)
 	if err != nil {
 		logger.Error("Service:Signup", err)
 		return model.SignupResponse{}, err
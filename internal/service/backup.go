@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"mpc/internal/config"
+	"mpc/internal/model"
+	"mpc/pkg/backup"
+	"mpc/pkg/errors"
+	"mpc/pkg/logger"
+	"mpc/pkg/ratelimit"
+	"mpc/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+type BackupService struct {
+	walletService *WalletService
+	auditRepo     WalletAuditEventStore
+	limiter       *ratelimit.Limiter
+	cfg           *config.BackupConfig
+}
+
+func NewBackupService(
+	walletService *WalletService,
+	auditRepo WalletAuditEventStore,
+	limiter *ratelimit.Limiter,
+	cfg *config.BackupConfig,
+) *BackupService {
+	return &BackupService{
+		walletService: walletService,
+		auditRepo:     auditRepo,
+		limiter:       limiter,
+		cfg:           cfg,
+	}
+}
+
+// ExportBackup encrypts the client share of a wallet with a password. The
+// share is checked against the nodes first, so a backup always restores the
+// wallet.
+func (s *BackupService) ExportBackup(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	req model.ExportBackupRequest,
+	meta model.RequestMeta,
+) (_ backup.File, err error) {
+	wallet, err := s.walletService.GetUserWallet(ctx, userID, walletID)
+	if err != nil {
+		return backup.File{}, err
+	}
+	defer func() { s.audit(ctx, wallet, model.WalletAuditActionBackupExport, meta, err) }()
+
+	if err := s.throttle(ctx, "backup_export:"+userID.String(), s.cfg.ExportLimit, s.cfg.ExportWindow); err != nil {
+		return backup.File{}, err
+	}
+	if isCustodial(wallet) {
+		return backup.File{}, errors.ErrBackupCustodialWallet
+	}
+	if err := s.walletService.VerifyShare(ctx, wallet, req.ShareData); err != nil {
+		return backup.File{}, err
+	}
+
+	file, err := backup.Encrypt(req.ShareData, backupWallet(wallet), req.Password)
+	if err != nil {
+		logger.Error("Service:ExportBackup", err)
+		return backup.File{}, err
+	}
+	return file, nil
+}
+
+// RecoverWallet decrypts a backup and reshares the wallet with the backed up
+// share, returning a fresh client share. The backed up share, and every other
+// backup of it, stops working once the reshare finishes.
+func (s *BackupService) RecoverWallet(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	req model.RecoverWalletRequest,
+	meta model.RequestMeta,
+) (_ model.RefreshSharesResponse, err error) {
+	wallet, err := s.walletService.GetUserWallet(ctx, userID, walletID)
+	if err != nil {
+		return model.RefreshSharesResponse{}, err
+	}
+	defer func() { s.audit(ctx, wallet, model.WalletAuditActionRecover, meta, err) }()
+
+	// Every attempt counts, so passwords cannot be guessed faster than the limit
+	if err := s.throttle(ctx, "wallet_recover:"+userID.String(), s.cfg.RecoveryLimit, s.cfg.RecoveryWindow); err != nil {
+		return model.RefreshSharesResponse{}, err
+	}
+	if isCustodial(wallet) {
+		return model.RefreshSharesResponse{}, errors.ErrBackupCustodialWallet
+	}
+
+	expected := backupWallet(wallet)
+	if req.Backup.WalletID != expected.ID || !strings.EqualFold(req.Backup.Address, expected.Address) || req.Backup.KeyID != expected.KeyID {
+		return model.RefreshSharesResponse{}, errors.ErrBackupWalletMismatch
+	}
+	// The nodes no longer accept the share of a backup made before a refresh
+	if backup.ShareVersionOf(req.Backup) != expected.ShareVersion {
+		return model.RefreshSharesResponse{}, errors.ErrBackupStale
+	}
+
+	shareData, err := backup.Decrypt(req.Backup, req.Password)
+	if err != nil {
+		logger.Warn("Service:RecoverWallet: " + err.Error())
+		if stderrors.Is(err, backup.ErrWrongPassword) {
+			return model.RefreshSharesResponse{}, errors.ErrBackupWrongPassword
+		}
+		return model.RefreshSharesResponse{}, errors.ErrInvalidBackup
+	}
+
+	return s.walletService.reshare(ctx, wallet, shareData)
+}
+
+// GetAuditEvents lists the backup and recovery events of a user's wallet
+func (s *BackupService) GetAuditEvents(ctx context.Context, userID, walletID uuid.UUID, page, pageSize int) ([]model.WalletAuditEventResponse, error) {
+	page, pageSize, err := utils.ValidatePagination(page, pageSize)
+	if err != nil {
+		return nil, errors.ErrInvalidRequest
+	}
+
+	wallet, err := s.walletService.GetUserWallet(ctx, userID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.auditRepo.GetWalletAuditEventsByWalletID(ctx, wallet.ID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		logger.Error("Service:GetAuditEvents", err)
+		return nil, err
+	}
+
+	res := make([]model.WalletAuditEventResponse, len(events))
+	for i, event := range events {
+		res[i] = model.WalletAuditEventResponse{
+			ID:        event.ID,
+			Action:    event.Action,
+			Status:    event.Status,
+			ErrorCode: event.ErrorCode,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	return res, nil
+}
+
+// throttle returns ErrTooManyAttempts once key has used its attempts for the window
+func (s *BackupService) throttle(ctx context.Context, key string, limit int, window time.Duration) error {
+	allowed, err := s.limiter.Allow(ctx, key, limit, window)
+	if err != nil {
+		logger.Error("Service:Throttle", err)
+		return err
+	}
+	if !allowed {
+		return errors.ErrTooManyAttempts
+	}
+	return nil
+}
+
+// audit records the outcome of a wallet operation. Recording errors are only
+// logged so they never mask the outcome.
+func (s *BackupService) audit(ctx context.Context, wallet model.Wallet, action string, meta model.RequestMeta, opErr error) {
+	event := model.WalletAuditEvent{
+		ID:        uuid.New(),
+		UserID:    wallet.UserID,
+		WalletID:  wallet.ID,
+		Action:    action,
+		Status:    model.WalletAuditStatusSucceeded,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+	}
+	switch {
+	case stderrors.Is(opErr, errors.ErrTooManyAttempts):
+		event.Status = model.WalletAuditStatusThrottled
+		event.ErrorCode = errors.ErrTooManyAttempts.Code
+	case opErr != nil:
+		event.Status = model.WalletAuditStatusFailed
+		event.Error = opErr.Error()
+		event.ErrorCode = errorCodeOf(opErr)
+	}
+
+	// The request may already be cancelled, the event is recorded regardless
+	if _, err := s.auditRepo.CreateWalletAuditEvent(context.WithoutCancel(ctx), event); err != nil {
+		logger.Error("Service:Audit", err)
+	}
+}
+
+// backupWallet returns the wallet fields a backup is bound to
+func backupWallet(wallet model.Wallet) backup.Wallet {
+	return backup.Wallet{
+		ID:           wallet.ID.String(),
+		Address:      wallet.Address,
+		KeyID:        wallet.KeyID,
+		ShareVersion: wallet.ShareVersion,
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"mpc/internal/config"
+	rd "mpc/internal/db/redis"
+	"mpc/internal/model"
+	"mpc/pkg/backup"
+	"mpc/pkg/errors"
+	"mpc/pkg/ratelimit"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const backupPassword = "correct horse battery"
+
+func newBackupService(t *testing.T, store *memStore, wallets *WalletService) *BackupService {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	redisClient := &rd.Client{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { redisClient.Close() })
+
+	return NewBackupService(wallets, store, ratelimit.NewLimiter(redisClient), &config.BackupConfig{
+		ExportLimit:    10,
+		ExportWindow:   time.Hour,
+		RecoveryLimit:  10,
+		RecoveryWindow: time.Hour,
+	})
+}
+
+func TestExportBackup(t *testing.T) {
+	store := newMemStore()
+	wallets := newWalletService(t, store, nil)
+	backups := newBackupService(t, store, wallets)
+	ctx := context.Background()
+
+	wallet, share, err := wallets.CreateWallet(ctx, uuid.New(), defaultTopology)
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+
+	file, err := backups.ExportBackup(ctx, wallet.UserID, wallet.ID, model.ExportBackupRequest{ShareData: share, Password: backupPassword}, model.RequestMeta{})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	restored, err := backup.Decrypt(file, backupPassword)
+	if err != nil || restored != share {
+		t.Fatalf("backup does not decrypt to the share: %v", err)
+	}
+
+	// The share was checked by a signing session of the wallet
+	var verified bool
+	for _, session := range store.sessions {
+		if session.Type == model.TSSSessionTypeKeyVerify && session.WalletID == wallet.ID && session.Status == model.TSSSessionStatusCompleted {
+			verified = true
+		}
+	}
+	if !verified {
+		t.Fatal("no completed key verification session for the wallet")
+	}
+}
+
+func TestExportBackupStaleShare(t *testing.T) {
+	store := newMemStore()
+	wallets := newWalletService(t, store, nil)
+	backups := newBackupService(t, store, wallets)
+	ctx := context.Background()
+
+	wallet, oldShare, err := wallets.CreateWallet(ctx, uuid.New(), defaultTopology)
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	if _, err := wallets.RefreshShares(ctx, wallet.UserID, wallet.ID, oldShare); err != nil {
+		t.Fatalf("refresh shares: %v", err)
+	}
+
+	// A share replaced by the refresh would make a backup that cannot restore the wallet
+	_, err = backups.ExportBackup(ctx, wallet.UserID, wallet.ID, model.ExportBackupRequest{ShareData: oldShare, Password: backupPassword}, model.RequestMeta{})
+	if !stderrors.Is(err, errors.ErrTSSInvalidShare) {
+		t.Fatalf("export with the old share: got %v, want %v", err, errors.ErrTSSInvalidShare)
+	}
+	if len(store.auditEvents) != 1 || store.auditEvents[0].Status != model.WalletAuditStatusFailed {
+		t.Fatalf("audit events %v, want one failed export", store.auditEvents)
+	}
+}
+
+func TestRecoverWalletStaleBackup(t *testing.T) {
+	store := newMemStore()
+	wallets := newWalletService(t, store, nil)
+	backups := newBackupService(t, store, wallets)
+	ctx := context.Background()
+
+	wallet, share, err := wallets.CreateWallet(ctx, uuid.New(), defaultTopology)
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	stale, err := backups.ExportBackup(ctx, wallet.UserID, wallet.ID, model.ExportBackupRequest{ShareData: share, Password: backupPassword}, model.RequestMeta{})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	refreshed, err := wallets.RefreshShares(ctx, wallet.UserID, wallet.ID, share)
+	if err != nil {
+		t.Fatalf("refresh shares: %v", err)
+	}
+	if refreshed.Wallet.ShareVersion != stale.ShareVersion+1 {
+		t.Fatalf("share version %d after the refresh, want %d", refreshed.Wallet.ShareVersion, stale.ShareVersion+1)
+	}
+
+	// The backup of the replaced share is rejected before a reshare starts
+	reshares := func() int {
+		var n int
+		for _, session := range store.sessions {
+			if session.Type == model.TSSSessionTypeReshare {
+				n++
+			}
+		}
+		return n
+	}
+	before := reshares()
+	_, err = backups.RecoverWallet(ctx, wallet.UserID, wallet.ID, model.RecoverWalletRequest{Backup: stale, Password: backupPassword}, model.RequestMeta{})
+	if !stderrors.Is(err, errors.ErrBackupStale) {
+		t.Fatalf("recover with a stale backup: got %v, want %v", err, errors.ErrBackupStale)
+	}
+	if n := reshares(); n != before {
+		t.Fatalf("%d reshare sessions started for a stale backup, want none", n-before)
+	}
+
+	// A backup exported after the refresh recovers the wallet
+	fresh, err := backups.ExportBackup(ctx, wallet.UserID, wallet.ID, model.ExportBackupRequest{ShareData: refreshed.ShareData, Password: backupPassword}, model.RequestMeta{})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	recovered, err := backups.RecoverWallet(ctx, wallet.UserID, wallet.ID, model.RecoverWalletRequest{Backup: fresh, Password: backupPassword}, model.RequestMeta{})
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if recovered.ShareData == "" || recovered.Wallet.ShareVersion != fresh.ShareVersion+1 {
+		t.Fatalf("recovered at share version %d, want a new share at %d", recovered.Wallet.ShareVersion, fresh.ShareVersion+1)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"mpc/internal/model"
+	"mpc/pkg/logger"
+	"mpc/pkg/tss"
+)
+
+type HealthService struct {
+	tssClient tss.HealthChecker
+}
+
+func NewHealthService(tssClient tss.HealthChecker) *HealthService {
+	return &HealthService{
+		tssClient: tssClient,
+	}
+}
+
+// Check reports the liveness of the MPC parties. The API itself is serving,
+// so a missing quorum degrades the status instead of failing the check.
+func (s *HealthService) Check(ctx context.Context) model.HealthResponse {
+	res := model.HealthResponse{Message: model.HealthStatusOK}
+
+	health, err := s.tssClient.Health(ctx)
+	if err != nil {
+		logger.Error("Service:HealthCheck", err)
+		res.Message = model.HealthStatusDegraded
+		res.TSS.Error = err.Error()
+		return res
+	}
+
+	res.TSS = model.TSSHealth{
+		Quorum:    health.Quorum(),
+		Alive:     health.Alive,
+		Threshold: health.Threshold,
+		Parties:   make([]model.PartyHealth, len(health.Parties)),
+	}
+	for i, party := range health.Parties {
+		res.TSS.Parties[i] = model.PartyHealth{
+			Party:    party.Party,
+			Alive:    party.Alive,
+			LastSeen: party.LastSeen,
+		}
+	}
+	if !res.TSS.Quorum {
+		res.Message = model.HealthStatusDegraded
+	}
+	return res
+}
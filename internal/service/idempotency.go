@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"mpc/internal/config"
+	"mpc/internal/db/redis"
+	"mpc/internal/model"
+	"mpc/pkg/cache"
+	"mpc/pkg/errors"
+	"mpc/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyService remembers the Idempotency-Key of transaction submissions,
+// so a retried request gets the jobs of the first one instead of signing
+// again. Keys are claimed in Postgres, which decides between concurrent
+// retries, and completed keys are cached in Redis.
+type IdempotencyService struct {
+	repo  IdempotencyKeyStore
+	cache *cache.Cache
+	cfg   *config.IdempotencyConfig
+}
+
+func NewIdempotencyService(repo IdempotencyKeyStore, redisClient *redis.Client, cfg *config.IdempotencyConfig) *IdempotencyService {
+	return &IdempotencyService{
+		repo:  repo,
+		cache: cache.NewCache(redisClient, "idempotency"),
+		cfg:   cfg,
+	}
+}
+
+// Begin claims key for a request with fingerprint and reports true when the
+// request should run. When the key was already used for the same request it
+// returns the record with the queued jobs and false, even while the first
+// request is still running. A key used for another request fails with
+// ErrIdempotencyKeyMismatch, one whose request has not queued its jobs yet
+// with ErrIdempotencyKeyInProgress.
+func (s *IdempotencyService) Begin(ctx context.Context, userID uuid.UUID, key, fingerprint string) (model.IdempotencyKey, bool, error) {
+	record, found, err := s.get(ctx, userID, key)
+	if err != nil {
+		return model.IdempotencyKey{}, false, err
+	}
+	if found {
+		return s.replay(record, fingerprint)
+	}
+
+	record, claimed, err := s.repo.ClaimIdempotencyKey(ctx, userID, key, fingerprint, time.Now().Add(s.cfg.ClaimTTL))
+	if err != nil {
+		logger.Error("Service:BeginIdempotencyKey", err)
+		return model.IdempotencyKey{}, false, err
+	}
+	if claimed {
+		return record, true, nil
+	}
+
+	// A concurrent retry claimed the key first
+	record, found, err = s.get(ctx, userID, key)
+	if err != nil {
+		return model.IdempotencyKey{}, false, err
+	}
+	if !found {
+		return model.IdempotencyKey{}, false, errors.ErrIdempotencyKeyInProgress
+	}
+	return s.replay(record, fingerprint)
+}
+
+// AttachJobs records the jobs of a key claimed by Begin as soon as they are
+// queued, so a retry gets them while the request is still running. Errors
+// are only logged, retries then get ErrIdempotencyKeyInProgress until
+// Complete.
+func (s *IdempotencyService) AttachJobs(ctx context.Context, record model.IdempotencyKey, batchID uuid.UUID, jobIDs []uuid.UUID) {
+	if err := s.repo.SetIdempotencyKeyJobs(context.WithoutCancel(ctx), record.UserID, record.Key, batchID, jobIDs); err != nil {
+		logger.Error("Service:AttachIdempotencyKeyJobs", err)
+	}
+}
+
+// Complete records the jobs queued for a key claimed by Begin. The jobs are
+// already queued, so errors are only logged. The record is cached first, so
+// retries find it even when Postgres cannot be updated.
+func (s *IdempotencyService) Complete(ctx context.Context, record model.IdempotencyKey, batchID uuid.UUID, jobIDs []uuid.UUID) {
+	ctx = context.WithoutCancel(ctx)
+	record.Status = model.IdempotencyKeyStatusCompleted
+	record.BatchID = batchID
+	record.JobIDs = jobIDs
+	record.ExpiresAt = time.Now().Add(s.cfg.KeyTTL)
+	record.UpdatedAt = time.Now()
+
+	if err := s.cache.Set(ctx, idempotencyCacheKey(record.UserID, record.Key), record, s.cfg.KeyTTL); err != nil {
+		logger.Error("Service:CompleteIdempotencyKey", err)
+	}
+	if err := s.repo.CompleteIdempotencyKey(ctx, record.UserID, record.Key, batchID, jobIDs, record.ExpiresAt); err != nil {
+		logger.Error("Service:CompleteIdempotencyKey", err)
+	}
+}
+
+// Abort frees a claimed key whose request failed before queuing anything,
+// so the client can retry it
+func (s *IdempotencyService) Abort(ctx context.Context, userID uuid.UUID, key string) {
+	if err := s.repo.DeleteIdempotencyKey(context.WithoutCancel(ctx), userID, key); err != nil {
+		logger.Error("Service:AbortIdempotencyKey", err)
+	}
+}
+
+// get returns the unexpired record of a key, from Redis when it is completed
+func (s *IdempotencyService) get(ctx context.Context, userID uuid.UUID, key string) (model.IdempotencyKey, bool, error) {
+	var record model.IdempotencyKey
+	if err := s.cache.Get(ctx, idempotencyCacheKey(userID, key), &record); err == nil {
+		return record, true, nil
+	}
+
+	record, found, err := s.repo.GetIdempotencyKey(ctx, userID, key)
+	if err != nil {
+		logger.Error("Service:GetIdempotencyKey", err)
+		return model.IdempotencyKey{}, false, err
+	}
+	if ttl := time.Until(record.ExpiresAt); found && record.Status == model.IdempotencyKeyStatusCompleted && ttl > 0 {
+		if err := s.cache.Set(ctx, idempotencyCacheKey(userID, key), record, ttl); err != nil {
+			logger.Error("Service:GetIdempotencyKey", err)
+		}
+	}
+	return record, found, nil
+}
+
+// replay returns the record of a key that was already used, once it has jobs
+func (s *IdempotencyService) replay(record model.IdempotencyKey, fingerprint string) (model.IdempotencyKey, bool, error) {
+	if record.Fingerprint != fingerprint {
+		return model.IdempotencyKey{}, false, errors.ErrIdempotencyKeyMismatch
+	}
+	if len(record.JobIDs) == 0 {
+		return model.IdempotencyKey{}, false, errors.ErrIdempotencyKeyInProgress
+	}
+	return record, false, nil
+}
+
+// requestFingerprint hashes a request with the name of its endpoint, so a key
+// reused for another request is detected. Callers clear the client share
+// first, it may differ between retries and is never stored.
+func requestFingerprint(endpoint string, req any) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint request: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(endpoint+":"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func idempotencyCacheKey(userID uuid.UUID, key string) string {
+	return userID.String() + ":" + key
+}
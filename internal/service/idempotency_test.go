@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+func TestIdempotentSubmit(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+
+	first, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", env.transfer("0.01"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	env.waitForJob(t, first.ID)
+
+	// The share may differ between retries, it is not part of the fingerprint
+	req := env.transfer("0.01")
+	req.ShareData = "another share"
+	retry, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", req)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if retry.ID != first.ID || retry.Status != model.TransactionJobStatusCompleted || retry.Transaction == nil {
+		t.Fatalf("retry got job %s %s, want the completed job %s", retry.ID, retry.Status, first.ID)
+	}
+	if sent := env.node.sentTransactions(); len(sent) != 1 {
+		t.Fatalf("node got %d transactions, want 1", len(sent))
+	}
+
+	_, err = env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", env.transfer("0.02"))
+	if !stderrors.Is(err, errors.ErrIdempotencyKeyMismatch) {
+		t.Fatalf("reuse with another body: got %v, want %v", err, errors.ErrIdempotencyKeyMismatch)
+	}
+}
+
+func TestIdempotentSubmitWhileRunning(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	// The first request claimed the key and is still validating
+	req := env.transfer("0.01")
+	fingerprintReq := req
+	fingerprintReq.ShareData = ""
+	fingerprint, err := requestFingerprint("transactions", fingerprintReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, claimed, err := env.txns.idempotency.Begin(ctx, env.wallet.UserID, "key-1", fingerprint)
+	if err != nil || !claimed {
+		t.Fatalf("claim: %v", err)
+	}
+
+	_, err = env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", req)
+	if !stderrors.Is(err, errors.ErrIdempotencyKeyInProgress) {
+		t.Fatalf("retry before queuing: got %v, want %v", err, errors.ErrIdempotencyKeyInProgress)
+	}
+
+	// Once its job is queued a retry gets it, before the first request completes the key
+	jobs, err := env.txns.enqueueJob(ctx, env.wallet, uuid.Nil, []model.CreateAndSubmitTransactionRequest{req}, record)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	retry, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", req)
+	if err != nil {
+		t.Fatalf("retry after queuing: %v", err)
+	}
+	if retry.ID != jobs[0].ID {
+		t.Fatalf("retry got job %s, want %s", retry.ID, jobs[0].ID)
+	}
+	if len(env.store.jobs) != 1 {
+		t.Fatalf("retries queued %d jobs, want 1", len(env.store.jobs))
+	}
+}
+
+func TestIdempotentSubmitWithoutJobs(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	// A completed record without jobs, e.g. written by an older version
+	req := env.transfer("0.01")
+	req.ShareData = ""
+	fingerprint, err := requestFingerprint("transactions", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.store.idempotencyKeys[idempotencyCacheKey(env.wallet.UserID, "key-1")] = model.IdempotencyKey{
+		UserID:      env.wallet.UserID,
+		Key:         "key-1",
+		Fingerprint: fingerprint,
+		Status:      model.IdempotencyKeyStatusCompleted,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	_, err = env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", env.transfer("0.01"))
+	if !stderrors.Is(err, errors.ErrIdempotencyKeyInProgress) {
+		t.Fatalf("got %v, want %v", err, errors.ErrIdempotencyKeyInProgress)
+	}
+}
+
+func TestIdempotentSubmitRejected(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	// A rejected request frees the key, so it can be retried once fixed
+	_, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", env.transfer("2"))
+	if !stderrors.Is(err, errors.ErrInssuficientBalance) {
+		t.Fatalf("got %v, want %v", err, errors.ErrInssuficientBalance)
+	}
+	if _, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "key-1", env.transfer("0.01")); err != nil {
+		t.Fatalf("retry after rejection: %v", err)
+	}
+}
+
+func TestIdempotentBatchSubmit(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+
+	req := model.CreateBatchTransactionRequest{
+		FromAddress: env.wallet.Address,
+		ChainID:     testChainID,
+		Transfers: []model.BatchTransfer{
+			{ToAddress: recipient, Symbol: "ETH", Amount: "0.01"},
+			{ToAddress: recipient, Symbol: "ETH", Amount: "0.02"},
+		},
+		ShareData: env.share,
+	}
+	first, err := env.txns.CreateBatchTransaction(ctx, env.wallet.UserID, "key-1", req)
+	if err != nil {
+		t.Fatalf("submit batch: %v", err)
+	}
+	retry, err := env.txns.CreateBatchTransaction(ctx, env.wallet.UserID, "key-1", req)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if retry.BatchID != first.BatchID || len(retry.Jobs) != len(first.Jobs) {
+		t.Fatalf("retry got batch %s with %d jobs, want %s with %d", retry.BatchID, len(retry.Jobs), first.BatchID, len(first.Jobs))
+	}
+	for i := range first.Jobs {
+		if retry.Jobs[i].ID != first.Jobs[i].ID {
+			t.Fatalf("retry job %d is %s, want %s", i, retry.Jobs[i].ID, first.Jobs[i].ID)
+		}
+		env.waitForJob(t, first.Jobs[i].ID)
+	}
+}
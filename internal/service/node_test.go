@@ -0,0 +1,347 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testChainID = 1337
+
+// errNoAnswer makes the fake node close the connection instead of answering
+var errNoAnswer = errors.New("no answer")
+
+// fakeNode answers the JSON-RPC calls the services make to an Ethereum node.
+// Every address holds one ETH and gas costs one gwei; sent transactions are
+// kept in the mempool until mine is called. A transaction with the nonce of
+// one in the mempool replaces it when its fees are 10% higher.
+type fakeNode struct {
+	URL string
+
+	mu        sync.Mutex
+	pending   map[common.Address]uint64
+	confirmed map[common.Address]uint64
+	sent      []*types.Transaction
+	pool      map[common.Hash]*types.Transaction
+	mined     map[common.Hash]uint64 // Block number of mined transactions
+	lagging   map[common.Hash]bool   // Mined transactions the node answers as unknown
+	head      uint64
+	// sendErr, when set, fails eth_sendRawTransaction with its error, or
+	// closes the connection on errNoAnswer. The transaction is still accepted
+	// when it returns accepted, like a send whose response was lost.
+	sendErr func(tx *types.Transaction) (accepted bool, err error)
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	t.Helper()
+	node := &fakeNode{
+		pending:   make(map[common.Address]uint64),
+		confirmed: make(map[common.Address]uint64),
+		pool:      make(map[common.Hash]*types.Transaction),
+		mined:     make(map[common.Hash]uint64),
+		lagging:   make(map[common.Hash]bool),
+	}
+	server := httptest.NewServer(http.HandlerFunc(node.serve))
+	t.Cleanup(server.Close)
+	node.URL = server.URL
+	return node
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (n *fakeNode) serve(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	result, err := n.call(req)
+	switch {
+	case errors.Is(err, errNoAnswer):
+		if conn, _, hijackErr := w.(http.Hijacker).Hijack(); hijackErr == nil {
+			conn.Close()
+		}
+		return
+	case err != nil:
+		res["error"] = rpcError{Code: -32000, Message: err.Error()}
+	default:
+		res["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (n *fakeNode) call(req rpcRequest) (any, error) {
+	gwei := big.NewInt(1_000_000_000)
+	switch req.Method {
+	case "eth_chainId":
+		return hexutil.Uint64(testChainID), nil
+	case "eth_getBalance":
+		return (*hexutil.Big)(new(big.Int).Mul(gwei, gwei)), nil
+	case "eth_getCode":
+		return hexutil.Bytes{}, nil
+	case "eth_estimateGas":
+		return hexutil.Uint64(21000), nil
+	case "eth_gasPrice", "eth_maxPriorityFeePerGas":
+		return (*hexutil.Big)(gwei), nil
+	case "eth_feeHistory":
+		return map[string]any{
+			"oldestBlock":   (*hexutil.Big)(big.NewInt(1)),
+			"baseFeePerGas": []*hexutil.Big{(*hexutil.Big)(gwei), (*hexutil.Big)(gwei)},
+			"gasUsedRatio":  []float64{0.5},
+			"reward":        [][]*hexutil.Big{{(*hexutil.Big)(gwei)}},
+		}, nil
+	case "eth_getTransactionCount":
+		var address common.Address
+		var block string
+		if err := unmarshalParams(req.Params, &address, &block); err != nil {
+			return nil, err
+		}
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if block == "pending" {
+			return hexutil.Uint64(n.pending[address]), nil
+		}
+		return hexutil.Uint64(n.confirmed[address]), nil
+	case "eth_blockNumber":
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return hexutil.Uint64(n.head), nil
+	case "eth_getTransactionByHash":
+		var hash common.Hash
+		if err := unmarshalParams(req.Params, &hash); err != nil {
+			return nil, err
+		}
+		return n.transaction(hash)
+	case "eth_getTransactionReceipt":
+		var hash common.Hash
+		if err := unmarshalParams(req.Params, &hash); err != nil {
+			return nil, err
+		}
+		return n.receipt(hash), nil
+	case "eth_sendRawTransaction":
+		var raw hexutil.Bytes
+		if err := unmarshalParams(req.Params, &raw); err != nil {
+			return nil, err
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		return tx.Hash(), n.send(tx)
+	}
+	return nil, fmt.Errorf("method %s not supported", req.Method)
+}
+
+func (n *fakeNode) send(tx *types.Transaction) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var sendErr error
+	accepted := true
+	if n.sendErr != nil {
+		accepted, sendErr = n.sendErr(tx)
+	}
+	if accepted {
+		if tx.Nonce() < n.pending[sender] {
+			replaced := n.pooled(sender, tx.Nonce())
+			if replaced == nil {
+				return fmt.Errorf("nonce too low: next nonce %d, tx nonce %d", n.pending[sender], tx.Nonce())
+			}
+			bump := new(big.Int).Div(new(big.Int).Mul(replaced.GasFeeCap(), big.NewInt(110)), big.NewInt(100))
+			if tx.GasFeeCap().Cmp(bump) < 0 {
+				return errors.New("replacement transaction underpriced")
+			}
+			delete(n.pool, replaced.Hash())
+		}
+		n.sent = append(n.sent, tx)
+		n.pool[tx.Hash()] = tx
+		n.pending[sender] = max(n.pending[sender], tx.Nonce()+1)
+	}
+	return sendErr
+}
+
+// pooled returns the transaction of from with nonce in the mempool, or nil.
+// Callers hold n.mu.
+func (n *fakeNode) pooled(from common.Address, nonce uint64) *types.Transaction {
+	for _, tx := range n.pool {
+		if sender, _ := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); sender == from && tx.Nonce() == nonce {
+			return tx
+		}
+	}
+	return nil
+}
+
+// mine includes a sent transaction in a new block, even one that was
+// replaced, like a miner that saw it first. Transactions with its nonce leave
+// the mempool.
+func (n *fakeNode) mine(t *testing.T, hash common.Hash) {
+	t.Helper()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	tx := n.find(hash)
+	if tx == nil {
+		t.Fatalf("transaction %s was not sent", hash.Hex())
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for other := n.pooled(from, tx.Nonce()); other != nil; other = n.pooled(from, tx.Nonce()) {
+		delete(n.pool, other.Hash())
+	}
+	n.head++
+	n.mined[hash] = n.head
+	n.confirmed[from] = max(n.confirmed[from], tx.Nonce()+1)
+}
+
+// advance adds empty blocks to the chain
+func (n *fakeNode) advance(blocks uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.head += blocks
+}
+
+// lag makes the node answer as if it had not seen a mined transaction yet,
+// like a lagging node behind a load balancer, until lag is called with false
+func (n *fakeNode) lag(hash common.Hash, lagging bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lagging[hash] = lagging
+}
+
+// transaction answers eth_getTransactionByHash: the transaction, with its
+// block once mined, or null when the node does not know it
+func (n *fakeNode) transaction(hash common.Hash) (any, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.lagging[hash] {
+		return nil, nil
+	}
+	tx := n.pool[hash]
+	block, mined := n.mined[hash]
+	if mined {
+		tx = n.find(hash)
+	}
+	if tx == nil {
+		return nil, nil
+	}
+
+	raw, err := tx.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]any
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+	if mined {
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, err
+		}
+		res["from"] = from
+		res["blockNumber"] = hexutil.Uint64(block)
+		res["blockHash"] = blockHash(block)
+		res["transactionIndex"] = hexutil.Uint64(0)
+	}
+	return res, nil
+}
+
+// receipt answers eth_getTransactionReceipt: a successful receipt of a mined
+// transaction, or null
+func (n *fakeNode) receipt(hash common.Hash) any {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	block, ok := n.mined[hash]
+	if !ok || n.lagging[hash] {
+		return nil
+	}
+	tx := n.find(hash)
+	return &types.Receipt{
+		Type:              tx.Type(),
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: tx.Gas(),
+		Logs:              []*types.Log{},
+		TxHash:            hash,
+		GasUsed:           tx.Gas(),
+		EffectiveGasPrice: tx.GasFeeCap(),
+		BlockHash:         blockHash(block),
+		BlockNumber:       new(big.Int).SetUint64(block),
+	}
+}
+
+// find returns a sent transaction by hash. Callers hold n.mu.
+func (n *fakeNode) find(hash common.Hash) *types.Transaction {
+	for _, tx := range n.sent {
+		if tx.Hash() == hash {
+			return tx
+		}
+	}
+	return nil
+}
+
+// blockHash returns the hash of a block of the fake chain
+func blockHash(number uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(number + 1000))
+}
+
+// sentTransactions returns the transactions the node accepted
+func (n *fakeNode) sentTransactions() []*types.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*types.Transaction(nil), n.sent...)
+}
+
+func (n *fakeNode) setSendErr(sendErr func(tx *types.Transaction) (accepted bool, err error)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sendErr = sendErr
+}
+
+func unmarshalParams(params []json.RawMessage, values ...any) error {
+	for i, value := range values {
+		if i >= len(params) {
+			return fmt.Errorf("missing param %d", i)
+		}
+		if err := json.Unmarshal(params[i], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sender returns the lower case address that signed tx
+func sender(t *testing.T, tx *types.Transaction) string {
+	t.Helper()
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		t.Fatalf("recover sender: %v", err)
+	}
+	return strings.ToLower(from.Hex())
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mpc/internal/config"
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+	"mpc/pkg/ethereum"
+	"mpc/pkg/lock"
+	"mpc/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// NonceManager hands out the nonces of sending wallets. Reservations for an
+// address on a chain take a Redis lock, so concurrent jobs and API instances
+// never get the same nonce, and every nonce handed out is recorded so the
+// ones that were never broadcast are handed out again.
+type NonceManager struct {
+	repo   WalletNonceStore
+	locker *lock.Locker
+	cfg    *config.NonceConfig
+}
+
+func NewNonceManager(repo WalletNonceStore, locker *lock.Locker, cfg *config.NonceConfig) *NonceManager {
+	return &NonceManager{
+		repo:   repo,
+		locker: locker,
+		cfg:    cfg,
+	}
+}
+
+// nonceState is the free nonces of an address after a sync, lowest first
+type nonceState struct {
+	// released holds nonces below next that can be handed out again
+	released []uint64
+	next     uint64
+}
+
+// take hands out count nonces, the released ones first so a nonce that was
+// never broadcast does not hold back the transactions after it
+func (st *nonceState) take(count int) []uint64 {
+	nonces := make([]uint64, 0, count)
+	for len(nonces) < count && len(st.released) > 0 {
+		nonces = append(nonces, st.released[0])
+		st.released = st.released[1:]
+	}
+	for len(nonces) < count {
+		nonces = append(nonces, st.next)
+		st.next++
+	}
+	return nonces
+}
+
+// Reserve hands out count nonces in ascending order. They stay reserved until
+// they are marked sent or released, or until NONCE_RESERVATION_TTL passes.
+func (m *NonceManager) Reserve(ctx context.Context, client *ethereum.EthClient, chainID int, address string, count int) ([]uint64, error) {
+	address = strings.ToLower(address)
+
+	var nonces []uint64
+	err := m.withLock(ctx, chainID, address, func() error {
+		state, err := m.sync(ctx, client, chainID, address)
+		if err != nil {
+			return err
+		}
+
+		nonces = state.take(count)
+		until := time.Now().Add(m.cfg.ReservationTTL)
+		for _, nonce := range nonces {
+			if _, err := m.repo.ReserveWalletNonce(ctx, chainID, address, nonce, until); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Service:ReserveNonce", err)
+		return nil, err
+	}
+
+	logger.Info("reserved nonces", zap.Int("chain_id", chainID), zap.String("address", address), zap.Uint64s("nonces", nonces))
+	return nonces, nil
+}
+
+// Next returns the nonce the next reservation would get, without reserving it
+func (m *NonceManager) Next(ctx context.Context, client *ethereum.EthClient, chainID int, address string) (uint64, error) {
+	address = strings.ToLower(address)
+
+	var nonce uint64
+	err := m.withLock(ctx, chainID, address, func() error {
+		state, err := m.sync(ctx, client, chainID, address)
+		if err != nil {
+			return err
+		}
+		nonce = state.take(1)[0]
+		return nil
+	})
+	if err != nil {
+		logger.Error("Service:NextNonce", err)
+		return 0, err
+	}
+	return nonce, nil
+}
+
+// Resync reconciles the records of the address with the chain, e.g. after the
+// node rejected a nonce that was used by a transaction sent elsewhere
+func (m *NonceManager) Resync(ctx context.Context, client *ethereum.EthClient, chainID int, address string) error {
+	address = strings.ToLower(address)
+
+	err := m.withLock(ctx, chainID, address, func() error {
+		_, err := m.sync(ctx, client, chainID, address)
+		return err
+	})
+	if err != nil {
+		logger.Error("Service:ResyncNonces", err)
+		return err
+	}
+	return nil
+}
+
+// MarkSent records the broadcast transaction of a reserved nonce. The
+// transaction is already sent, so errors are only logged.
+func (m *NonceManager) MarkSent(ctx context.Context, chainID int, address string, nonce uint64, txHash string) {
+	err := m.repo.MarkWalletNonceSent(context.WithoutCancel(ctx), chainID, strings.ToLower(address), nonce, txHash)
+	if err != nil {
+		logger.Error("Service:MarkNonceSent", err)
+	}
+}
+
+// Release frees reserved nonces that were not broadcast. Errors are only
+// logged, the reservations then expire after NONCE_RESERVATION_TTL.
+func (m *NonceManager) Release(ctx context.Context, chainID int, address string, nonces []uint64) {
+	// The send may have failed because the job timed out
+	ctx = context.WithoutCancel(ctx)
+	for _, nonce := range nonces {
+		if err := m.repo.ReleaseWalletNonce(ctx, chainID, strings.ToLower(address), nonce); err != nil {
+			logger.Error("Service:ReleaseNonce", err)
+		}
+	}
+}
+
+// ReleaseDropped frees the nonce of a sent transaction that was dropped
+// without being mined, so the next reservation fills the gap
+func (m *NonceManager) ReleaseDropped(ctx context.Context, chainID int, address string, nonce uint64, txHash string) {
+	err := m.repo.ReleaseDroppedWalletNonce(ctx, chainID, strings.ToLower(address), nonce, txHash)
+	if err != nil {
+		logger.Error("Service:ReleaseDroppedNonce", err)
+	}
+}
+
+// sync brings the records of the address in line with the chain and returns
+// the free nonces. The caller holds the lock.
+//
+// Nonces below the confirmed nonce are mined and dropped. Expired
+// reservations are released, and released nonces the node already counts as
+// pending were taken by a transaction sent elsewhere, so they are dropped too.
+// Nonces between the pending nonce and the last one handed out that have no
+// record are gaps: they would hold back every later transaction, so they are
+// handed out first.
+func (m *NonceManager) sync(ctx context.Context, client *ethereum.EthClient, chainID int, address string) (nonceState, error) {
+	confirmed, err := client.ConfirmedNonce(ctx, address)
+	if err != nil {
+		return nonceState{}, err
+	}
+	pending, err := client.PendingNonce(ctx, address)
+	if err != nil {
+		return nonceState{}, err
+	}
+
+	if err := m.repo.ExpireWalletNonces(ctx, chainID, address); err != nil {
+		return nonceState{}, err
+	}
+	if err := m.repo.PruneWalletNonces(ctx, chainID, address, confirmed, pending); err != nil {
+		return nonceState{}, err
+	}
+	records, err := m.repo.GetWalletNonces(ctx, chainID, address)
+	if err != nil {
+		return nonceState{}, err
+	}
+
+	state := nonceState{next: pending}
+	recorded := make(map[uint64]bool, len(records))
+	for _, record := range records {
+		recorded[record.Nonce] = true
+		if record.Status == model.WalletNonceStatusReleased {
+			state.released = append(state.released, record.Nonce)
+		}
+		if record.Nonce >= state.next {
+			state.next = record.Nonce + 1
+		}
+	}
+
+	var gaps []uint64
+	for nonce := pending; nonce < state.next; nonce++ {
+		if !recorded[nonce] {
+			gaps = append(gaps, nonce)
+		}
+	}
+	if len(gaps) > 0 {
+		logger.Warn("found nonce gaps", zap.Int("chain_id", chainID), zap.String("address", address), zap.Uint64s("nonces", gaps))
+		state.released = append(state.released, gaps...)
+		sort.Slice(state.released, func(i, j int) bool { return state.released[i] < state.released[j] })
+	}
+	return state, nil
+}
+
+// withLock runs fn holding the nonce lock of the address
+func (m *NonceManager) withLock(ctx context.Context, chainID int, address string, fn func() error) error {
+	release, err := m.locker.Acquire(ctx, fmt.Sprintf("nonce:%d:%s", chainID, address), m.cfg.LockTTL, m.cfg.LockWait)
+	if err != nil {
+		if stderrors.Is(err, lock.ErrNotAcquired) {
+			return errors.ErrNonceLocked
+		}
+		return err
+	}
+	defer release(context.WithoutCancel(ctx))
+
+	return fn()
+}
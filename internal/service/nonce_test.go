@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"mpc/internal/model"
+	"mpc/pkg/ethereum"
+)
+
+func (e *testEnv) client(t *testing.T) *ethereum.EthClient {
+	t.Helper()
+	client, err := e.chains.Client(context.Background(), testChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func (e *testEnv) reserve(t *testing.T, count int) []uint64 {
+	t.Helper()
+	nonces, err := e.nonces.Reserve(context.Background(), e.client(t), testChainID, e.wallet.Address, count)
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	return nonces
+}
+
+// expireReservations moves the deadline of every reservation to the past
+func (e *testEnv) expireReservations() {
+	e.store.mu.Lock()
+	defer e.store.mu.Unlock()
+	past := time.Now().Add(-time.Second)
+	for key, record := range e.store.nonces {
+		if record.Status == model.WalletNonceStatusReserved {
+			record.ReservedUntil = &past
+			e.store.nonces[key] = record
+		}
+	}
+}
+
+func TestReserveNonces(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	if got := env.reserve(t, 2); !slices.Equal(got, []uint64{0, 1}) {
+		t.Fatalf("reserved %v, want [0 1]", got)
+	}
+
+	// A released nonce is handed out again before new ones
+	env.nonces.Release(ctx, testChainID, env.wallet.Address, []uint64{0})
+	if got := env.reserve(t, 2); !slices.Equal(got, []uint64{0, 2}) {
+		t.Fatalf("reserved %v, want [0 2]", got)
+	}
+}
+
+func TestReserveNoncesAfterExpiry(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.reserve(t, 1)
+	env.expireReservations()
+	if got := env.reserve(t, 1); !slices.Equal(got, []uint64{0}) {
+		t.Fatalf("reserved %v, want the expired [0]", got)
+	}
+}
+
+func TestReserveNoncesFillsGaps(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	// Nonce 1 was sent but the node does not count nonce 0, which has no record
+	if _, err := env.store.ReserveWalletNonce(ctx, testChainID, env.wallet.Address, 1, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	env.nonces.MarkSent(ctx, testChainID, env.wallet.Address, 1, "0x01")
+
+	if got := env.reserve(t, 2); !slices.Equal(got, []uint64{0, 2}) {
+		t.Fatalf("reserved %v, want the gap first [0 2]", got)
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+	"mpc/pkg/logger"
+	"mpc/pkg/tss"
+	"mpc/pkg/utils"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+)
+
+// eip712DomainType is the type name of the EIP-712 domain separator
+const eip712DomainType = "EIP712Domain"
+
+type SigningService struct {
+	walletService  *WalletService
+	assetService   *AssetService
+	sessionService *TSSSessionService
+	tssClient      tss.Signer
+}
+
+func NewSigningService(
+	walletService *WalletService,
+	assetService *AssetService,
+	sessionService *TSSSessionService,
+	tssClient tss.Signer,
+) *SigningService {
+	return &SigningService{
+		walletService:  walletService,
+		assetService:   assetService,
+		sessionService: sessionService,
+		tssClient:      tssClient,
+	}
+}
+
+// SignMessage signs a message with the EIP-191 personal_sign prefix
+func (s *SigningService) SignMessage(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	req model.SignMessageRequest,
+) (model.SignatureResponse, error) {
+	message, err := decodeMessage(req.Message)
+	if err != nil {
+		return model.SignatureResponse{}, err
+	}
+
+	wallet, shareData, err := s.resolveWallet(ctx, userID, walletID, req.ShareData)
+	if err != nil {
+		return model.SignatureResponse{}, err
+	}
+
+	return s.signForResponse(ctx, wallet, shareData, common.BytesToHash(accounts.TextHash(message)))
+}
+
+// SignTypedData signs EIP-712 typed data after checking its domain
+func (s *SigningService) SignTypedData(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	req model.SignTypedDataRequest,
+) (model.SignatureResponse, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(req.TypedData, &typedData); err != nil {
+		return model.SignatureResponse{}, errors.ErrInvalidTypedData
+	}
+	if err := s.validateTypedData(ctx, typedData); err != nil {
+		return model.SignatureResponse{}, err
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		logger.Warn("failed to hash typed data: " + err.Error())
+		return model.SignatureResponse{}, errors.ErrInvalidTypedData
+	}
+
+	wallet, shareData, err := s.resolveWallet(ctx, userID, walletID, req.ShareData)
+	if err != nil {
+		return model.SignatureResponse{}, err
+	}
+
+	return s.signForResponse(ctx, wallet, shareData, common.BytesToHash(hash))
+}
+
+// SignHash runs a TSS signing session for the hash and returns the 65 byte
+// Ethereum signature recovered against the wallet address, with v as 0 or 1
+func (s *SigningService) SignHash(ctx context.Context, wallet model.Wallet, shareData string, hash common.Hash) (_ []byte, err error) {
+	session, err := s.sessionService.Start(ctx, wallet.UserID, wallet.ID, model.TSSSessionTypeSign, hash.Hex(), walletTopology(wallet))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { s.sessionService.Finish(ctx, session.ID, err) }()
+
+	derSig, err := s.tssClient.Sign(ctx, session.ID.String(), wallet.KeyID, shareData, hash.Bytes(), walletTopology(wallet))
+	if err != nil {
+		return nil, fmt.Errorf("TSS signing failed: %w", err)
+	}
+
+	sig, err := utils.ConvertDERToEthSignature(derSig, hash.Bytes(), wallet.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert DER signature: %w", err)
+	}
+	return sig, nil
+}
+
+// SignHashes signs several hashes in one TSS session and returns the 65 byte
+// Ethereum signatures in the same order. The session records the keccak hash
+// of the concatenated hashes.
+func (s *SigningService) SignHashes(ctx context.Context, wallet model.Wallet, shareData string, hashes []common.Hash) (_ [][]byte, err error) {
+	messages := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		messages[i] = hash.Bytes()
+	}
+
+	batchHash := crypto.Keccak256Hash(messages...)
+	session, err := s.sessionService.Start(ctx, wallet.UserID, wallet.ID, model.TSSSessionTypeSignBatch, batchHash.Hex(), walletTopology(wallet))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { s.sessionService.Finish(ctx, session.ID, err) }()
+
+	derSigs, err := s.tssClient.SignBatch(ctx, session.ID.String(), wallet.KeyID, shareData, messages, walletTopology(wallet))
+	if err != nil {
+		return nil, fmt.Errorf("TSS batch signing failed: %w", err)
+	}
+	if len(derSigs) != len(hashes) {
+		return nil, fmt.Errorf("TSS batch signing returned %d signatures for %d hashes", len(derSigs), len(hashes))
+	}
+
+	sigs := make([][]byte, len(hashes))
+	for i, derSig := range derSigs {
+		if sigs[i], err = utils.ConvertDERToEthSignature(derSig, messages[i], wallet.Address); err != nil {
+			return nil, fmt.Errorf("failed to convert DER signature %d: %w", i, err)
+		}
+	}
+	return sigs, nil
+}
+
+// resolveWallet returns the user's wallet and the share to sign with
+func (s *SigningService) resolveWallet(ctx context.Context, userID, walletID uuid.UUID, provided string) (model.Wallet, string, error) {
+	wallet, err := s.walletService.GetUserWallet(ctx, userID, walletID)
+	if err != nil {
+		return model.Wallet{}, "", err
+	}
+	shareData, err := s.walletService.ResolveShareData(ctx, wallet, provided)
+	if err != nil {
+		return model.Wallet{}, "", err
+	}
+	return wallet, shareData, nil
+}
+
+// signForResponse signs the hash and formats the signature the way wallets
+// return it from personal_sign and eth_signTypedData, with v as 27 or 28
+func (s *SigningService) signForResponse(ctx context.Context, wallet model.Wallet, shareData string, hash common.Hash) (model.SignatureResponse, error) {
+	sig, err := s.SignHash(ctx, wallet, shareData, hash)
+	if err != nil {
+		logger.Error("Service:SignHash", err)
+		if appErr := toTSSError(err); appErr != err {
+			return model.SignatureResponse{}, appErr
+		}
+		return model.SignatureResponse{}, errors.ErrSignatureRecoveryFailed
+	}
+	sig[64] += 27
+
+	return model.SignatureResponse{
+		Address:   wallet.Address,
+		Hash:      hash.Hex(),
+		Signature: hexutil.Encode(sig),
+	}, nil
+}
+
+// validateTypedData checks the structure of the typed data and that its domain
+// is bound to a chain we support, so a signature cannot be replayed elsewhere
+func (s *SigningService) validateTypedData(ctx context.Context, typedData apitypes.TypedData) error {
+	domainType, ok := typedData.Types[eip712DomainType]
+	if !ok {
+		return errors.ErrInvalidTypedDataDomain
+	}
+	if typedData.PrimaryType == "" || typedData.PrimaryType == eip712DomainType {
+		return errors.ErrInvalidTypedData
+	}
+	if _, ok := typedData.Types[typedData.PrimaryType]; !ok {
+		return errors.ErrInvalidTypedData
+	}
+
+	// Every domain value must be declared, otherwise it is not part of the
+	// hash and the signer would approve something other than what is shown
+	declared := make(map[string]bool, len(domainType))
+	for _, field := range domainType {
+		declared[field.Name] = true
+	}
+	for name := range typedData.Domain.Map() {
+		if !declared[name] {
+			return errors.ErrInvalidTypedDataDomain
+		}
+	}
+
+	if typedData.Domain.ChainId == nil || !declared["chainId"] {
+		return errors.ErrInvalidTypedDataDomain
+	}
+	chainID := (*big.Int)(typedData.Domain.ChainId)
+	if !chainID.IsInt64() {
+		return errors.ErrInvalidTypedDataDomain
+	}
+	if _, err := s.assetService.GetChainByChainID(ctx, int(chainID.Int64())); err != nil {
+		return errors.ErrInvalidTypedDataDomain
+	}
+
+	if contract := typedData.Domain.VerifyingContract; contract != "" && !common.IsHexAddress(contract) {
+		return errors.ErrInvalidTypedDataDomain
+	}
+	return nil
+}
+
+// decodeMessage returns the bytes to sign: hex decoded when the message is 0x
+// prefixed, as personal_sign does, and the UTF-8 text otherwise
+func decodeMessage(message string) ([]byte, error) {
+	if !strings.HasPrefix(message, "0x") {
+		return []byte(message), nil
+	}
+	data, err := hexutil.Decode(message)
+	if err != nil {
+		return nil, errors.ErrInvalidMessage
+	}
+	return data, nil
+}
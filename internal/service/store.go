@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"mpc/internal/model"
+	"mpc/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// The stores are the repository methods the services use. The services depend
+// on them rather than on the repositories, so they can be tested with
+// in-memory stores and without Postgres.
+
+// ChainStore reads the chains table
+type ChainStore interface {
+	GetChains(ctx context.Context) ([]model.Chain, error)
+	GetChainByChainID(ctx context.Context, chainID int) (model.Chain, error)
+}
+
+// TokenStore reads the tokens of a chain
+type TokenStore interface {
+	GetTokensByChainID(ctx context.Context, chainID uuid.UUID) ([]model.Token, error)
+	GetTokenBySymbol(ctx context.Context, chainID uuid.UUID, symbol string) (model.Token, error)
+}
+
+// UserStore stores users
+type UserStore interface {
+	CreateUser(ctx context.Context, email, passwordHash string) (model.User, error)
+	GetUserByEmail(ctx context.Context, email string) (model.User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (model.User, error)
+}
+
+// WalletStore stores wallets
+type WalletStore interface {
+	CreateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error)
+	GetWalletByID(ctx context.Context, id uuid.UUID) (model.Wallet, error)
+	GetWalletsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Wallet, error)
+	GetWalletByAddress(ctx context.Context, address string) (model.Wallet, error)
+	UpdateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error)
+	RotateWalletShare(ctx context.Context, id uuid.UUID, encryptedShare string) (model.Wallet, error)
+}
+
+// WalletAuditEventStore records sensitive wallet operations
+type WalletAuditEventStore interface {
+	CreateWalletAuditEvent(ctx context.Context, event model.WalletAuditEvent) (model.WalletAuditEvent, error)
+	GetWalletAuditEventsByWalletID(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]model.WalletAuditEvent, error)
+}
+
+// TSSSessionStore stores TSS sessions
+type TSSSessionStore interface {
+	CreateTSSSession(ctx context.Context, session model.TSSSession) (model.TSSSession, error)
+	GetTSSSessionByID(ctx context.Context, id uuid.UUID) (model.TSSSession, error)
+	FinishTSSSession(ctx context.Context, id uuid.UUID, status, errorMessage, errorCode string) (model.TSSSession, error)
+	SetTSSSessionWallet(ctx context.Context, id uuid.UUID, walletID uuid.UUID) error
+}
+
+// TransactionStore stores sent and received transactions
+type TransactionStore interface {
+	CreateTransaction(ctx context.Context, transaction model.Transaction) (model.Transaction, error)
+	GetTransactionsByWalletAddress(ctx context.Context, walletAddress string, chainID int, limit int, offset int) ([]model.Transaction, error)
+	GetTransactionByID(ctx context.Context, id uuid.UUID) (model.Transaction, error)
+	GetTransactionCount(ctx context.Context, walletAddress string, chainID int) (int, error)
+	GetTrackedTransactions(ctx context.Context, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]model.Transaction, error)
+	UpdateTransactionStatus(ctx context.Context, transaction model.Transaction) error
+	MarkTransactionReplaced(ctx context.Context, id uuid.UUID) error
+	RestoreReplacedTransaction(ctx context.Context, id uuid.UUID) error
+}
+
+// TransactionJobStore stores queued transfers and replacements
+type TransactionJobStore interface {
+	CreateTransactionJob(ctx context.Context, job model.TransactionJob) (model.TransactionJob, error)
+	CreateReplacementJob(ctx context.Context, job model.TransactionJob) (model.TransactionJob, bool, error)
+	GetTransactionJobByID(ctx context.Context, id uuid.UUID) (model.TransactionJob, error)
+	StartTransactionJob(ctx context.Context, id uuid.UUID) (model.TransactionJob, bool, error)
+	FinishTransactionJob(ctx context.Context, id uuid.UUID, status string, transactionID uuid.UUID, errorMessage, errorCode string) (model.TransactionJob, bool, error)
+	RenewTransactionJobLeases(ctx context.Context, ownerID string, until time.Time) error
+	FailOwnedTransactionJobs(ctx context.Context, ownerID, errorMessage string) error
+	FailExpiredTransactionJobs(ctx context.Context, errorMessage string) error
+}
+
+// WalletNonceStore stores the nonces handed out per wallet and chain
+type WalletNonceStore interface {
+	GetWalletNonces(ctx context.Context, chainID int, address string) ([]model.WalletNonce, error)
+	ReserveWalletNonce(ctx context.Context, chainID int, address string, nonce uint64, until time.Time) (model.WalletNonce, error)
+	MarkWalletNonceSent(ctx context.Context, chainID int, address string, nonce uint64, txHash string) error
+	ReleaseWalletNonce(ctx context.Context, chainID int, address string, nonce uint64) error
+	ReleaseDroppedWalletNonce(ctx context.Context, chainID int, address string, nonce uint64, txHash string) error
+	ExpireWalletNonces(ctx context.Context, chainID int, address string) error
+	PruneWalletNonces(ctx context.Context, chainID int, address string, confirmed, pending uint64) error
+}
+
+// IdempotencyKeyStore stores the Idempotency-Key of transaction submissions
+type IdempotencyKeyStore interface {
+	GetIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) (model.IdempotencyKey, bool, error)
+	ClaimIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, until time.Time) (model.IdempotencyKey, bool, error)
+	SetIdempotencyKeyJobs(ctx context.Context, userID uuid.UUID, key string, batchID uuid.UUID, jobIDs []uuid.UUID) error
+	CompleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, batchID uuid.UUID, jobIDs []uuid.UUID, until time.Time) error
+	DeleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error
+}
+
+var (
+	_ ChainStore            = (*repository.ChainRepository)(nil)
+	_ TokenStore            = (*repository.TokenRepository)(nil)
+	_ UserStore             = (*repository.UserRepository)(nil)
+	_ WalletStore           = (*repository.WalletRepository)(nil)
+	_ WalletAuditEventStore = (*repository.WalletAuditEventRepository)(nil)
+	_ TSSSessionStore       = (*repository.TSSSessionRepository)(nil)
+	_ TransactionStore      = (*repository.TransactionRepository)(nil)
+	_ TransactionJobStore   = (*repository.TransactionJobRepository)(nil)
+	_ WalletNonceStore      = (*repository.WalletNonceRepository)(nil)
+	_ IdempotencyKeyStore   = (*repository.IdempotencyKeyRepository)(nil)
+)
@@ -0,0 +1,664 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"mpc/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// memStore keeps every store in memory, following the queries the
+// repositories run, so the services can be tested without Postgres
+type memStore struct {
+	mu              sync.Mutex
+	chains          []model.Chain
+	tokens          []model.Token
+	users           map[uuid.UUID]model.User
+	wallets         map[uuid.UUID]model.Wallet
+	auditEvents     []model.WalletAuditEvent
+	sessions        map[uuid.UUID]model.TSSSession
+	transactions    map[uuid.UUID]model.Transaction
+	jobs            map[uuid.UUID]model.TransactionJob
+	nonces          map[string]model.WalletNonce
+	idempotencyKeys map[string]model.IdempotencyKey
+}
+
+var (
+	_ ChainStore            = (*memStore)(nil)
+	_ TokenStore            = (*memStore)(nil)
+	_ UserStore             = (*memStore)(nil)
+	_ WalletStore           = (*memStore)(nil)
+	_ WalletAuditEventStore = (*memStore)(nil)
+	_ TSSSessionStore       = (*memStore)(nil)
+	_ TransactionStore      = (*memStore)(nil)
+	_ TransactionJobStore   = (*memStore)(nil)
+	_ WalletNonceStore      = (*memStore)(nil)
+	_ IdempotencyKeyStore   = (*memStore)(nil)
+)
+
+func newMemStore() *memStore {
+	return &memStore{
+		users:           make(map[uuid.UUID]model.User),
+		wallets:         make(map[uuid.UUID]model.Wallet),
+		sessions:        make(map[uuid.UUID]model.TSSSession),
+		transactions:    make(map[uuid.UUID]model.Transaction),
+		jobs:            make(map[uuid.UUID]model.TransactionJob),
+		nonces:          make(map[string]model.WalletNonce),
+		idempotencyKeys: make(map[string]model.IdempotencyKey),
+	}
+}
+
+var errNotFound = fmt.Errorf("no rows in result set")
+
+func (m *memStore) GetChains(ctx context.Context) ([]model.Chain, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.Chain(nil), m.chains...), nil
+}
+
+func (m *memStore) GetChainByChainID(ctx context.Context, chainID int) (model.Chain, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, chain := range m.chains {
+		if chain.ChainID == chainID {
+			return chain, nil
+		}
+	}
+	return model.Chain{}, errNotFound
+}
+
+func (m *memStore) GetTokensByChainID(ctx context.Context, chainID uuid.UUID) ([]model.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var tokens []model.Token
+	for _, token := range m.tokens {
+		if token.ChainID == chainID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *memStore) GetTokenBySymbol(ctx context.Context, chainID uuid.UUID, symbol string) (model.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, token := range m.tokens {
+		if token.ChainID == chainID && token.Symbol == symbol {
+			return token, nil
+		}
+	}
+	return model.Token{}, errNotFound
+}
+
+func (m *memStore) CreateUser(ctx context.Context, email, passwordHash string) (model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, user := range m.users {
+		if user.Email == email {
+			return model.User{}, fmt.Errorf("duplicate email %s", email)
+		}
+	}
+	user := model.User{ID: uuid.New(), Email: email, PasswordHash: passwordHash, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	m.users[user.ID] = user
+	return user, nil
+}
+
+func (m *memStore) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return model.User{}, errNotFound
+}
+
+func (m *memStore) GetUserByID(ctx context.Context, id uuid.UUID) (model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.users[id]
+	if !ok {
+		return model.User{}, errNotFound
+	}
+	return user, nil
+}
+
+func (m *memStore) CreateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wallet.ID = uuid.New()
+	wallet.ShareVersion = 1
+	wallet.CreatedAt, wallet.UpdatedAt = time.Now(), time.Now()
+	m.wallets[wallet.ID] = wallet
+	return wallet, nil
+}
+
+func (m *memStore) GetWalletByID(ctx context.Context, id uuid.UUID) (model.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wallet, ok := m.wallets[id]
+	if !ok {
+		return model.Wallet{}, errNotFound
+	}
+	return wallet, nil
+}
+
+func (m *memStore) GetWalletsByUserID(ctx context.Context, userID uuid.UUID) ([]model.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var wallets []model.Wallet
+	for _, wallet := range m.wallets {
+		if wallet.UserID == userID {
+			wallets = append(wallets, wallet)
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].CreatedAt.Before(wallets[j].CreatedAt) })
+	return wallets, nil
+}
+
+func (m *memStore) GetWalletByAddress(ctx context.Context, address string) (model.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, wallet := range m.wallets {
+		if wallet.Address == address {
+			return wallet, nil
+		}
+	}
+	return model.Wallet{}, errNotFound
+}
+
+func (m *memStore) UpdateWallet(ctx context.Context, wallet model.Wallet) (model.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.wallets[wallet.ID]; !ok {
+		return model.Wallet{}, errNotFound
+	}
+	wallet.UpdatedAt = time.Now()
+	m.wallets[wallet.ID] = wallet
+	return wallet, nil
+}
+
+func (m *memStore) RotateWalletShare(ctx context.Context, id uuid.UUID, encryptedShare string) (model.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wallet, ok := m.wallets[id]
+	if !ok {
+		return model.Wallet{}, errNotFound
+	}
+	wallet.EncryptedPrivateKey = encryptedShare
+	wallet.ShareVersion++
+	wallet.UpdatedAt = time.Now()
+	m.wallets[id] = wallet
+	return wallet, nil
+}
+
+func (m *memStore) CreateWalletAuditEvent(ctx context.Context, event model.WalletAuditEvent) (model.WalletAuditEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+	m.auditEvents = append(m.auditEvents, event)
+	return event, nil
+}
+
+func (m *memStore) GetWalletAuditEventsByWalletID(ctx context.Context, walletID uuid.UUID, limit, offset int) ([]model.WalletAuditEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []model.WalletAuditEvent
+	for i := len(m.auditEvents) - 1; i >= 0; i-- {
+		if m.auditEvents[i].WalletID == walletID {
+			events = append(events, m.auditEvents[i])
+		}
+	}
+	return page(events, limit, offset), nil
+}
+
+func (m *memStore) CreateTSSSession(ctx context.Context, session model.TSSSession) (model.TSSSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session.StartedAt, session.CreatedAt, session.UpdatedAt = time.Now(), time.Now(), time.Now()
+	m.sessions[session.ID] = session
+	return session, nil
+}
+
+func (m *memStore) GetTSSSessionByID(ctx context.Context, id uuid.UUID) (model.TSSSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return model.TSSSession{}, errNotFound
+	}
+	return session, nil
+}
+
+func (m *memStore) FinishTSSSession(ctx context.Context, id uuid.UUID, status, errorMessage, errorCode string) (model.TSSSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return model.TSSSession{}, errNotFound
+	}
+	now := time.Now()
+	session.Status, session.Error, session.ErrorCode, session.EndedAt = status, errorMessage, errorCode, &now
+	m.sessions[id] = session
+	return session, nil
+}
+
+func (m *memStore) SetTSSSessionWallet(ctx context.Context, id uuid.UUID, walletID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return errNotFound
+	}
+	session.WalletID = walletID
+	m.sessions[id] = session
+	return nil
+}
+
+func (m *memStore) CreateTransaction(ctx context.Context, transaction model.Transaction) (model.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Like the unique index on the chain and hash
+	for id, existing := range m.transactions {
+		if existing.ChainID != transaction.ChainID || existing.TxHash != transaction.TxHash {
+			continue
+		}
+		if existing.TokenID == uuid.Nil {
+			existing.TokenID = transaction.TokenID
+		}
+		if existing.Amount == "" {
+			existing.Amount = transaction.Amount
+		}
+		if existing.ReplacesID == uuid.Nil {
+			existing.ReplacesID = transaction.ReplacesID
+		}
+		existing.UpdatedAt = time.Now()
+		m.transactions[id] = existing
+		return existing, nil
+	}
+	transaction.ID = uuid.New()
+	transaction.CreatedAt, transaction.UpdatedAt = time.Now(), time.Now()
+	m.transactions[transaction.ID] = transaction
+	return transaction, nil
+}
+
+func (m *memStore) GetTransactionsByWalletAddress(ctx context.Context, walletAddress string, chainID int, limit int, offset int) ([]model.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var txns []model.Transaction
+	for _, txn := range m.transactions {
+		if txn.ChainID == chainID && (txn.FromAddress == walletAddress || txn.ToAddress == walletAddress) {
+			txns = append(txns, txn)
+		}
+	}
+	sort.Slice(txns, func(i, j int) bool { return txns[i].CreatedAt.After(txns[j].CreatedAt) })
+	return page(txns, limit, offset), nil
+}
+
+func (m *memStore) GetTransactionByID(ctx context.Context, id uuid.UUID) (model.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	txn, ok := m.transactions[id]
+	if !ok {
+		return model.Transaction{}, errNotFound
+	}
+	return txn, nil
+}
+
+func (m *memStore) GetTransactionCount(ctx context.Context, walletAddress string, chainID int) (int, error) {
+	txns, err := m.GetTransactionsByWalletAddress(ctx, walletAddress, chainID, 0, 0)
+	return len(txns), err
+}
+
+func (m *memStore) GetTrackedTransactions(ctx context.Context, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]model.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var txns []model.Transaction
+	for _, txn := range m.transactions {
+		if txn.Status != model.TransactionStatusSubmitted && txn.Status != model.TransactionStatusPending {
+			continue
+		}
+		if txn.CreatedAt.After(afterCreatedAt) || (txn.CreatedAt.Equal(afterCreatedAt) && txn.ID.String() > afterID.String()) {
+			txns = append(txns, txn)
+		}
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		if txns[i].CreatedAt.Equal(txns[j].CreatedAt) {
+			return txns[i].ID.String() < txns[j].ID.String()
+		}
+		return txns[i].CreatedAt.Before(txns[j].CreatedAt)
+	})
+	return page(txns, limit, 0), nil
+}
+
+func (m *memStore) UpdateTransactionStatus(ctx context.Context, transaction model.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.transactions[transaction.ID]; !ok {
+		return errNotFound
+	}
+	transaction.UpdatedAt = time.Now()
+	m.transactions[transaction.ID] = transaction
+	return nil
+}
+
+func (m *memStore) MarkTransactionReplaced(ctx context.Context, id uuid.UUID) error {
+	return m.setTransactionStatus(id, model.TransactionStatusReplaced, model.TransactionStatusSubmitted, model.TransactionStatusPending)
+}
+
+func (m *memStore) RestoreReplacedTransaction(ctx context.Context, id uuid.UUID) error {
+	return m.setTransactionStatus(id, model.TransactionStatusSubmitted, model.TransactionStatusReplaced)
+}
+
+// setTransactionStatus moves a transaction to status when it has one of from
+func (m *memStore) setTransactionStatus(id uuid.UUID, status string, from ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	txn, ok := m.transactions[id]
+	if ok && slices.Contains(from, txn.Status) {
+		txn.Status, txn.UpdatedAt = status, time.Now()
+		m.transactions[id] = txn
+	}
+	return nil
+}
+
+func (m *memStore) CreateTransactionJob(ctx context.Context, job model.TransactionJob) (model.TransactionJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job.CreatedAt, job.UpdatedAt = time.Now(), time.Now()
+	m.jobs[job.ID] = job
+	return job, nil
+}
+
+func (m *memStore) CreateReplacementJob(ctx context.Context, job model.TransactionJob) (model.TransactionJob, bool, error) {
+	m.mu.Lock()
+	for _, other := range m.jobs {
+		if other.ReplacesID == job.ReplacesID &&
+			(other.Status == model.TransactionJobStatusQueued || other.Status == model.TransactionJobStatusRunning) {
+			m.mu.Unlock()
+			return model.TransactionJob{}, false, nil
+		}
+	}
+	m.mu.Unlock()
+	created, err := m.CreateTransactionJob(ctx, job)
+	return created, err == nil, err
+}
+
+func (m *memStore) GetTransactionJobByID(ctx context.Context, id uuid.UUID) (model.TransactionJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return model.TransactionJob{}, errNotFound
+	}
+	return job, nil
+}
+
+func (m *memStore) StartTransactionJob(ctx context.Context, id uuid.UUID) (model.TransactionJob, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return model.TransactionJob{}, false, errNotFound
+	}
+	if job.Status != model.TransactionJobStatusQueued {
+		return model.TransactionJob{}, false, nil
+	}
+	now := time.Now()
+	job.Status, job.StartedAt = model.TransactionJobStatusRunning, &now
+	m.jobs[id] = job
+	return job, true, nil
+}
+
+func (m *memStore) FinishTransactionJob(ctx context.Context, id uuid.UUID, status string, transactionID uuid.UUID, errorMessage, errorCode string) (model.TransactionJob, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return model.TransactionJob{}, false, errNotFound
+	}
+	if !jobUnfinished(job) {
+		return model.TransactionJob{}, false, nil
+	}
+	now := time.Now()
+	job.Status, job.TransactionID, job.Error, job.ErrorCode, job.EndedAt = status, transactionID, errorMessage, errorCode, &now
+	m.jobs[id] = job
+	return job, true, nil
+}
+
+func (m *memStore) RenewTransactionJobLeases(ctx context.Context, ownerID string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, job := range m.jobs {
+		if job.OwnerID == ownerID && jobUnfinished(job) {
+			job.LeaseExpiresAt = &until
+			m.jobs[id] = job
+		}
+	}
+	return nil
+}
+
+func (m *memStore) FailOwnedTransactionJobs(ctx context.Context, ownerID, errorMessage string) error {
+	return m.failJobs(func(job model.TransactionJob) bool { return job.OwnerID == ownerID }, errorMessage)
+}
+
+func (m *memStore) FailExpiredTransactionJobs(ctx context.Context, errorMessage string) error {
+	now := time.Now()
+	return m.failJobs(func(job model.TransactionJob) bool {
+		return job.LeaseExpiresAt == nil || job.LeaseExpiresAt.Before(now)
+	}, errorMessage)
+}
+
+// failJobs fails the unfinished jobs matching match
+func (m *memStore) failJobs(match func(model.TransactionJob) bool, errorMessage string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for id, job := range m.jobs {
+		if jobUnfinished(job) && match(job) {
+			job.Status, job.Error, job.EndedAt = model.TransactionJobStatusFailed, errorMessage, &now
+			m.jobs[id] = job
+		}
+	}
+	return nil
+}
+
+func jobUnfinished(job model.TransactionJob) bool {
+	return job.Status == model.TransactionJobStatusQueued || job.Status == model.TransactionJobStatusRunning
+}
+
+func (m *memStore) GetWalletNonces(ctx context.Context, chainID int, address string) ([]model.WalletNonce, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var nonces []model.WalletNonce
+	for _, nonce := range m.nonces {
+		if nonce.ChainID == chainID && nonce.Address == address {
+			nonces = append(nonces, nonce)
+		}
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i].Nonce < nonces[j].Nonce })
+	return nonces, nil
+}
+
+func (m *memStore) ReserveWalletNonce(ctx context.Context, chainID int, address string, nonce uint64, until time.Time) (model.WalletNonce, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record := model.WalletNonce{
+		ChainID:       chainID,
+		Address:       address,
+		Nonce:         nonce,
+		Status:        model.WalletNonceStatusReserved,
+		ReservedUntil: &until,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	m.nonces[nonceKey(chainID, address, nonce)] = record
+	return record, nil
+}
+
+func (m *memStore) MarkWalletNonceSent(ctx context.Context, chainID int, address string, nonce uint64, txHash string) error {
+	m.updateNonce(chainID, address, nonce, func(record *model.WalletNonce) bool {
+		record.Status, record.TxHash, record.ReservedUntil = model.WalletNonceStatusSent, txHash, nil
+		return true
+	})
+	return nil
+}
+
+func (m *memStore) ReleaseWalletNonce(ctx context.Context, chainID int, address string, nonce uint64) error {
+	m.updateNonce(chainID, address, nonce, func(record *model.WalletNonce) bool {
+		if record.Status != model.WalletNonceStatusReserved {
+			return false
+		}
+		record.Status, record.ReservedUntil = model.WalletNonceStatusReleased, nil
+		return true
+	})
+	return nil
+}
+
+func (m *memStore) ReleaseDroppedWalletNonce(ctx context.Context, chainID int, address string, nonce uint64, txHash string) error {
+	m.updateNonce(chainID, address, nonce, func(record *model.WalletNonce) bool {
+		if record.Status != model.WalletNonceStatusSent || record.TxHash != txHash {
+			return false
+		}
+		record.Status = model.WalletNonceStatusReleased
+		return true
+	})
+	return nil
+}
+
+func (m *memStore) ExpireWalletNonces(ctx context.Context, chainID int, address string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, record := range m.nonces {
+		if record.ChainID == chainID && record.Address == address && record.Status == model.WalletNonceStatusReserved &&
+			record.ReservedUntil != nil && record.ReservedUntil.Before(time.Now()) {
+			record.Status, record.ReservedUntil = model.WalletNonceStatusReleased, nil
+			m.nonces[key] = record
+		}
+	}
+	return nil
+}
+
+func (m *memStore) PruneWalletNonces(ctx context.Context, chainID int, address string, confirmed, pending uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, record := range m.nonces {
+		if record.ChainID != chainID || record.Address != address {
+			continue
+		}
+		if record.Nonce < confirmed || (record.Status == model.WalletNonceStatusReleased && record.Nonce < pending) {
+			delete(m.nonces, key)
+		}
+	}
+	return nil
+}
+
+func (m *memStore) updateNonce(chainID int, address string, nonce uint64, update func(*model.WalletNonce) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := nonceKey(chainID, address, nonce)
+	record, ok := m.nonces[key]
+	if ok && update(&record) {
+		record.UpdatedAt = time.Now()
+		m.nonces[key] = record
+	}
+}
+
+func (m *memStore) GetIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) (model.IdempotencyKey, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.idempotencyKeys[idempotencyCacheKey(userID, key)]
+	if !ok || record.ExpiresAt.Before(time.Now()) {
+		return model.IdempotencyKey{}, false, nil
+	}
+	return record, true, nil
+}
+
+func (m *memStore) ClaimIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, until time.Time) (model.IdempotencyKey, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.idempotencyKeys[idempotencyCacheKey(userID, key)]; ok && !record.ExpiresAt.Before(time.Now()) {
+		return model.IdempotencyKey{}, false, nil
+	}
+	record := model.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      model.IdempotencyKeyStatusProcessing,
+		JobIDs:      []uuid.UUID{},
+		ExpiresAt:   until,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	m.idempotencyKeys[idempotencyCacheKey(userID, key)] = record
+	return record, true, nil
+}
+
+func (m *memStore) SetIdempotencyKeyJobs(ctx context.Context, userID uuid.UUID, key string, batchID uuid.UUID, jobIDs []uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.idempotencyKeys[idempotencyCacheKey(userID, key)]
+	if !ok || record.Status != model.IdempotencyKeyStatusProcessing {
+		return nil
+	}
+	record.BatchID, record.JobIDs, record.UpdatedAt = batchID, jobIDs, time.Now()
+	m.idempotencyKeys[idempotencyCacheKey(userID, key)] = record
+	return nil
+}
+
+func (m *memStore) CompleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, batchID uuid.UUID, jobIDs []uuid.UUID, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.idempotencyKeys[idempotencyCacheKey(userID, key)]
+	if !ok {
+		return nil
+	}
+	record.Status, record.BatchID, record.JobIDs, record.ExpiresAt, record.UpdatedAt = model.IdempotencyKeyStatusCompleted, batchID, jobIDs, until, time.Now()
+	m.idempotencyKeys[idempotencyCacheKey(userID, key)] = record
+	return nil
+}
+
+func (m *memStore) DeleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.idempotencyKeys[idempotencyCacheKey(userID, key)]; ok && record.Status == model.IdempotencyKeyStatusProcessing {
+		delete(m.idempotencyKeys, idempotencyCacheKey(userID, key))
+	}
+	return nil
+}
+
+// noncesByStatus returns the recorded nonces of an address with the status
+func (m *memStore) noncesByStatus(chainID int, address, status string) []uint64 {
+	records, _ := m.GetWalletNonces(context.Background(), chainID, address)
+	var nonces []uint64
+	for _, record := range records {
+		if record.Status == status {
+			nonces = append(nonces, record.Nonce)
+		}
+	}
+	return nonces
+}
+
+func nonceKey(chainID int, address string, nonce uint64) string {
+	return fmt.Sprintf("%d:%s:%d", chainID, address, nonce)
+}
+
+// page applies a limit and offset, a zero limit returns everything
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -2,45 +2,66 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
 
+	"mpc/internal/config"
 	"mpc/internal/model"
-	"mpc/internal/repository"
 	"mpc/pkg/errors"
 	"mpc/pkg/ethereum"
+	"mpc/pkg/lock"
 	"mpc/pkg/logger"
-	"mpc/pkg/tss"
 	"mpc/pkg/utils"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/google/uuid"
 )
 
 type TransactionService struct {
-	txnRepo       *repository.TransactionRepository
-	assetService  *AssetService
-	walletService *WalletService
-	ethClient     *ethereum.EthClient
-	tssClient     *tss.TSS
+	txnRepo        TransactionStore
+	jobRepo        TransactionJobStore
+	assetService   *AssetService
+	walletService  *WalletService
+	signingService *SigningService
+	chains         *ethereum.Registry
+	nonces         *NonceManager
+	idempotency    *IdempotencyService
+	locker         *lock.Locker
+	cfg            *config.TxnConfig
+	jobs           chan transactionJob
+	instanceID     string
 }
 
 func NewTransactionService(
-	txnRepo *repository.TransactionRepository,
+	txnRepo TransactionStore,
+	jobRepo TransactionJobStore,
 	walletService *WalletService,
 	assetService *AssetService,
-	ethClient *ethereum.EthClient,
-	tssClient *tss.TSS,
+	signingService *SigningService,
+	chains *ethereum.Registry,
+	nonces *NonceManager,
+	idempotency *IdempotencyService,
+	locker *lock.Locker,
+	cfg *config.TxnConfig,
 ) *TransactionService {
 	return &TransactionService{
-		txnRepo:       txnRepo,
-		assetService:  assetService,
-		walletService: walletService,
-		ethClient:     ethClient,
-		tssClient:     tssClient,
+		txnRepo:        txnRepo,
+		jobRepo:        jobRepo,
+		assetService:   assetService,
+		walletService:  walletService,
+		signingService: signingService,
+		chains:         chains,
+		nonces:         nonces,
+		idempotency:    idempotency,
+		locker:         locker,
+		cfg:            cfg,
+		jobs:           make(chan transactionJob, cfg.JobQueueSize),
+		instanceID:     jobOwnerID(cfg),
 	}
 }
 
@@ -65,7 +86,8 @@ func (s *TransactionService) GetTransactions(
 		return model.TransactionListResponse{}, errors.ErrWalletNotFound
 	}
 
-	if _, err = s.assetService.chainRepo.GetChainByChainID(ctx, chainID); err != nil {
+	chain, err := s.assetService.chainRepo.GetChainByChainID(ctx, chainID)
+	if err != nil {
 		return model.TransactionListResponse{}, errors.ErrInvalidChainID
 	}
 
@@ -87,8 +109,23 @@ func (s *TransactionService) GetTransactions(
 	// Calculate total pages
 	totalPages := (total + pageSize - 1) / pageSize
 
+	// Amounts are shown with the decimals of their token
+	tokens := make(map[uuid.UUID]model.TokenResponse)
+	chainTokens, err := s.assetService.GetTokensByChainID(ctx, chainID)
+	if err != nil {
+		logger.Error("Service:GetTransactions", err)
+	}
+	for _, token := range chainTokens {
+		tokens[token.ID] = token
+	}
+
+	items := make([]model.TransactionHistoryItem, len(transactions))
+	for i, txn := range transactions {
+		items[i] = toHistoryItem(walletAddress, chain, tokens, txn)
+	}
+
 	return model.TransactionListResponse{
-		Transactions: transactions,
+		Transactions: items,
 		Total:        total,
 		Page:         page,
 		PageSize:     pageSize,
@@ -96,47 +133,395 @@ func (s *TransactionService) GetTransactions(
 	}, nil
 }
 
-// CreateAndSubmitTransaction creates and submits a transaction.
+// CreateAndSubmitTransaction validates a transfer and queues it for signing
+// and broadcasting. The returned job can be polled with GetJob. A retry with
+// the same non-empty idempotency key gets the job of the first request.
 func (s *TransactionService) CreateAndSubmitTransaction(
 	ctx context.Context,
 	userID uuid.UUID,
+	idempotencyKey string,
 	req model.CreateAndSubmitTransactionRequest,
-) (model.Transaction, error) {
+) (model.TransactionJobResponse, error) {
+	if idempotencyKey == "" {
+		return s.createAndSubmitTransaction(ctx, userID, req, model.IdempotencyKey{})
+	}
+
+	fingerprintReq := req
+	fingerprintReq.ShareData = ""
+	fingerprint, err := requestFingerprint("transactions", fingerprintReq)
+	if err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+	record, claimed, err := s.idempotency.Begin(ctx, userID, idempotencyKey, fingerprint)
+	if err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+	if !claimed {
+		if len(record.JobIDs) == 0 {
+			return model.TransactionJobResponse{}, errors.ErrIdempotencyKeyInProgress
+		}
+		return s.GetJob(ctx, userID, record.JobIDs[0])
+	}
+
+	res, err := s.createAndSubmitTransaction(ctx, userID, req, record)
+	if err != nil {
+		s.idempotency.Abort(ctx, userID, idempotencyKey)
+		return model.TransactionJobResponse{}, err
+	}
+	s.idempotency.Complete(ctx, record, uuid.Nil, []uuid.UUID{res.ID})
+	return res, nil
+}
+
+func (s *TransactionService) createAndSubmitTransaction(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.CreateAndSubmitTransactionRequest,
+	idempotencyKey model.IdempotencyKey,
+) (model.TransactionJobResponse, error) {
 	// Validate request
 	if err := s.validateRequest(req); err != nil {
-		return model.Transaction{}, err
+		return model.TransactionJobResponse{}, err
 	}
 
 	req.FromAddress = strings.ToLower(req.FromAddress)
 	req.ToAddress = strings.ToLower(req.ToAddress)
 
+	wallet, shareData, err := s.resolveSender(ctx, userID, req.FromAddress, req.ShareData)
+	if err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+	req.ShareData = shareData
+
+	// Check if the wallet has enough balance for the value and the fees
+	if err := s.checkBalance(ctx, []model.CreateAndSubmitTransactionRequest{req}); err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+
+	jobs, err := s.enqueueJob(ctx, wallet, uuid.Nil, []model.CreateAndSubmitTransactionRequest{req}, idempotencyKey)
+	if err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+	return jobs[0], nil
+}
+
+// CreateBatchTransaction validates several transfers from one wallet and
+// queues them as a batch. They are signed in a single TSS session and
+// broadcast in order with consecutive nonces; every transfer gets its own job.
+// A retry with the same non-empty idempotency key gets the jobs of the first
+// request.
+func (s *TransactionService) CreateBatchTransaction(
+	ctx context.Context,
+	userID uuid.UUID,
+	idempotencyKey string,
+	req model.CreateBatchTransactionRequest,
+) (model.TransactionBatchResponse, error) {
+	if idempotencyKey == "" {
+		return s.createBatchTransaction(ctx, userID, req, model.IdempotencyKey{})
+	}
+
+	fingerprintReq := req
+	fingerprintReq.ShareData = ""
+	fingerprint, err := requestFingerprint("transactions/batch", fingerprintReq)
+	if err != nil {
+		return model.TransactionBatchResponse{}, err
+	}
+	record, claimed, err := s.idempotency.Begin(ctx, userID, idempotencyKey, fingerprint)
+	if err != nil {
+		return model.TransactionBatchResponse{}, err
+	}
+	if !claimed {
+		if len(record.JobIDs) == 0 {
+			return model.TransactionBatchResponse{}, errors.ErrIdempotencyKeyInProgress
+		}
+		res := model.TransactionBatchResponse{BatchID: record.BatchID}
+		for _, jobID := range record.JobIDs {
+			job, err := s.GetJob(ctx, userID, jobID)
+			if err != nil {
+				return model.TransactionBatchResponse{}, err
+			}
+			res.Jobs = append(res.Jobs, job)
+		}
+		return res, nil
+	}
+
+	res, err := s.createBatchTransaction(ctx, userID, req, record)
+	if err != nil {
+		s.idempotency.Abort(ctx, userID, idempotencyKey)
+		return model.TransactionBatchResponse{}, err
+	}
+	jobIDs := make([]uuid.UUID, len(res.Jobs))
+	for i, job := range res.Jobs {
+		jobIDs[i] = job.ID
+	}
+	s.idempotency.Complete(ctx, record, res.BatchID, jobIDs)
+	return res, nil
+}
+
+func (s *TransactionService) createBatchTransaction(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.CreateBatchTransactionRequest,
+	idempotencyKey model.IdempotencyKey,
+) (model.TransactionBatchResponse, error) {
+	reqs := make([]model.CreateAndSubmitTransactionRequest, len(req.Transfers))
+	for i, transfer := range req.Transfers {
+		reqs[i] = model.CreateAndSubmitTransactionRequest{
+			FromAddress: strings.ToLower(req.FromAddress),
+			ToAddress:   strings.ToLower(transfer.ToAddress),
+			ChainID:     req.ChainID,
+			Symbol:      transfer.Symbol,
+			Amount:      transfer.Amount,
+			TxType:      req.TxType,
+			Speed:       req.Speed,
+		}
+		if err := s.validateRequest(reqs[i]); err != nil {
+			return model.TransactionBatchResponse{}, err
+		}
+	}
+
+	wallet, shareData, err := s.resolveSender(ctx, userID, req.FromAddress, req.ShareData)
+	if err != nil {
+		return model.TransactionBatchResponse{}, err
+	}
+	for i := range reqs {
+		reqs[i].ShareData = shareData
+	}
+
+	// The wallet has to cover the whole batch
+	if err := s.checkBalance(ctx, reqs); err != nil {
+		return model.TransactionBatchResponse{}, err
+	}
+
+	batchID := uuid.New()
+	jobs, err := s.enqueueJob(ctx, wallet, batchID, reqs, idempotencyKey)
+	if err != nil {
+		return model.TransactionBatchResponse{}, err
+	}
+	return model.TransactionBatchResponse{
+		BatchID: batchID,
+		Jobs:    jobs,
+	}, nil
+}
+
+// QuoteTransaction builds the unsigned transaction a send would make and
+// prices it, without signing. Problems the send would run into once signed
+// are returned as warnings.
+func (s *TransactionService) QuoteTransaction(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.QuoteTransactionRequest,
+) (model.TransactionQuoteResponse, error) {
+	sendReq := model.CreateAndSubmitTransactionRequest{
+		FromAddress: strings.ToLower(req.FromAddress),
+		ToAddress:   strings.ToLower(req.ToAddress),
+		ChainID:     req.ChainID,
+		Symbol:      req.Symbol,
+		Amount:      req.Amount,
+		TxType:      req.TxType,
+		Speed:       req.Speed,
+	}
+	if err := s.validateRequest(sendReq); err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+	if _, err := s.senderWallet(ctx, userID, sendReq.FromAddress); err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+
+	chain, err := s.assetService.GetChainByChainID(ctx, req.ChainID)
+	if err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+	client, err := s.client(ctx, req.ChainID)
+	if err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+
+	transfers, err := s.resolveTransfers(ctx, []model.CreateAndSubmitTransactionRequest{sendReq})
+	if err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+
+	// The node cannot estimate a token transfer the wallet cannot pay for
+	enough, err := client.IsEnoughTokenBalance(ctx, sendReq.FromAddress, transfers)
+	if err != nil {
+		return model.TransactionQuoteResponse{}, fmt.Errorf("failed to check token balance: %w", err)
+	}
+	if !enough {
+		return model.TransactionQuoteResponse{}, errors.ErrInssuficientBalance
+	}
+
+	// The quote is not sent, so the nonce is not reserved
+	nonce, err := s.nonces.Next(ctx, client, req.ChainID, sendReq.FromAddress)
+	if err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+
+	opts := feeOptions(sendReq).WithDefaults()
+	tx, err := client.CreateTransaction(ctx, sendReq.FromAddress, nonce, transfers[0], opts)
+	if err != nil {
+		return model.TransactionQuoteResponse{}, toEthError(err)
+	}
+	unsignedTx, err := tx.MarshalBinary()
+	if err != nil {
+		return model.TransactionQuoteResponse{}, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	maxFee := new(big.Int).Sub(tx.Cost(), tx.Value())
+	res := model.TransactionQuoteResponse{
+		ChainID:      req.ChainID,
+		TxType:       opts.Type,
+		Speed:        opts.Speed,
+		From:         sendReq.FromAddress,
+		To:           strings.ToLower(tx.To().Hex()),
+		Nonce:        tx.Nonce(),
+		GasLimit:     tx.Gas(),
+		Value:        tx.Value().String(),
+		UnsignedTx:   hexutil.Encode(unsignedTx),
+		NativeSymbol: chain.NativeCurrency,
+		MaxFee:       ethereum.FromBaseUnits(maxFee, ethereum.NativeDecimals),
+		TotalNative:  ethereum.FromBaseUnits(tx.Cost(), ethereum.NativeDecimals),
+		Symbol:       req.Symbol,
+		Amount:       req.Amount,
+		Warnings:     []string{},
+	}
+	if len(tx.Data()) > 0 {
+		res.Data = hexutil.Encode(tx.Data())
+	}
+	if tx.Type() == types.LegacyTxType {
+		res.GasPrice = tx.GasPrice().String()
+	} else {
+		res.MaxFeePerGas = tx.GasFeeCap().String()
+		res.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	}
+
+	balance, err := client.Balance(ctx, sendReq.FromAddress)
+	if err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+	if balance.Cmp(tx.Cost()) < 0 {
+		res.Warnings = append(res.Warnings, model.QuoteWarningInsufficientBalance)
+	}
+
+	// Funds sent to a contract that does not expect them may be lost
+	isContract, err := client.IsContract(ctx, sendReq.ToAddress)
+	if err != nil {
+		return model.TransactionQuoteResponse{}, err
+	}
+	if isContract {
+		res.Warnings = append(res.Warnings, model.QuoteWarningContractRecipient)
+	}
+	return res, nil
+}
+
+// resolveSender returns the user's wallet for the from address and the share
+// to sign with
+func (s *TransactionService) resolveSender(
+	ctx context.Context,
+	userID uuid.UUID,
+	fromAddress, providedShare string,
+) (model.Wallet, string, error) {
+	wallet, err := s.senderWallet(ctx, userID, fromAddress)
+	if err != nil {
+		return model.Wallet{}, "", err
+	}
+
+	shareData, err := s.walletService.ResolveShareData(ctx, wallet, providedShare)
+	if err != nil {
+		return model.Wallet{}, "", err
+	}
+	return wallet, shareData, nil
+}
+
+// senderWallet returns the user's wallet, which must be the from address
+func (s *TransactionService) senderWallet(ctx context.Context, userID uuid.UUID, fromAddress string) (model.Wallet, error) {
 	// Fetch wallet
 	wallet, err := s.walletService.GetWalletByUserID(ctx, userID)
 	if err != nil {
 		logger.Error("failed to get wallet by user ID", err)
-		return model.Transaction{}, errors.ErrInvalidRequest
+		return model.Wallet{}, errors.ErrInvalidRequest
 	}
 
-	if !strings.EqualFold(wallet.Address, req.FromAddress) {
+	if !strings.EqualFold(wallet.Address, fromAddress) {
 		logger.Warn("wallet address does not match the from address in the request")
-		return model.Transaction{}, errors.ErrInvalidRequest
+		return model.Wallet{}, errors.ErrInvalidRequest
 	}
+	return wallet, nil
+}
 
-	// Check if the wallet has enough balance
-	enough, err := s.ethClient.IsEnoughBalance(ctx, req.FromAddress, req.Amount)
+// checkBalance builds the transactions of reqs and returns
+// ErrInssuficientBalance when the sender cannot cover the tokens sent, or the
+// native values and the gas limits at the max fee. The fees are checked again
+// by the node when the transactions are sent.
+func (s *TransactionService) checkBalance(ctx context.Context, reqs []model.CreateAndSubmitTransactionRequest) error {
+	client, err := s.client(ctx, reqs[0].ChainID)
 	if err != nil {
-		return model.Transaction{}, fmt.Errorf("failed to check balance: %w", err)
+		return err
+	}
+	transfers, err := s.resolveTransfers(ctx, reqs)
+	if err != nil {
+		return err
+	}
+
+	// A token transfer without the tokens reverts, so check before estimating gas
+	enough, err := client.IsEnoughTokenBalance(ctx, reqs[0].FromAddress, transfers)
+	if err != nil {
+		return fmt.Errorf("failed to check token balance: %w", err)
 	}
 	if !enough {
-		return model.Transaction{}, errors.ErrInssuficientBalance
+		return errors.ErrInssuficientBalance
+	}
+
+	// Nonces are only reserved when sending, the fees do not depend on them
+	pending, err := client.PendingNonce(ctx, reqs[0].FromAddress)
+	if err != nil {
+		return err
+	}
+	nonces := make([]uint64, len(transfers))
+	for i := range nonces {
+		nonces[i] = pending + uint64(i)
+	}
+
+	txs, err := client.CreateTransactions(ctx, reqs[0].FromAddress, nonces, transfers, feeOptions(reqs[0]))
+	if err != nil {
+		return toEthError(err)
 	}
 
-	hash, err := s.handleTxn(ctx, userID.String(), req)
+	enough, err = client.IsEnoughBalance(ctx, reqs[0].FromAddress, txs)
 	if err != nil {
-		return model.Transaction{}, err
+		return fmt.Errorf("failed to check balance: %w", err)
 	}
-	// Create transaction record in the database
-	return s.createTransactionRecord(ctx, req.FromAddress, req.ToAddress, hash, req.ChainID)
+	if !enough {
+		return errors.ErrInssuficientBalance
+	}
+	return nil
+}
+
+// resolveTransfers looks up the token of every request by symbol. Amounts are
+// converted with the token decimals, and ERC20 tokens are sent through their
+// contract.
+func (s *TransactionService) resolveTransfers(ctx context.Context, reqs []model.CreateAndSubmitTransactionRequest) ([]ethereum.Transfer, error) {
+	transfers := make([]ethereum.Transfer, len(reqs))
+	for i, req := range reqs {
+		token, err := s.assetService.GetTokenBySymbol(ctx, req.ChainID, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers[i] = ethereum.Transfer{
+			To:       req.ToAddress,
+			Amount:   req.Amount,
+			Decimals: token.Decimals,
+		}
+		switch token.Type {
+		case model.TokenTypeNative:
+		case model.TokenTypeERC20:
+			transfers[i].Token = token.ContractAddress
+		default:
+			logger.Warn("unsupported token type " + token.Type + " for " + req.Symbol)
+			return nil, errors.ErrUnsupportedTokenType
+		}
+	}
+	return transfers, nil
 }
 
 // validateRequest validates the transaction request.
@@ -156,13 +541,32 @@ func (s *TransactionService) validateRequest(req model.CreateAndSubmitTransactio
 // createTransactionRecord creates a new transaction record in the database.
 func (s *TransactionService) createTransactionRecord(
 	ctx context.Context,
-	fromAddress, toAddress, txHash string, chainID int,
+	req model.CreateAndSubmitTransactionRequest,
+	tx *types.Transaction,
 ) (model.Transaction, error) {
+	nonce := tx.Nonce()
 	txn := model.Transaction{
-		FromAddress: fromAddress,
-		ToAddress:   toAddress,
-		TxHash:      txHash,
-		ChainID:     chainID,
+		FromAddress: req.FromAddress,
+		ToAddress:   req.ToAddress,
+		TxHash:      strings.ToLower(tx.Hash().Hex()),
+		ChainID:     req.ChainID,
+		Status:      model.TransactionStatusSubmitted,
+		Nonce:       &nonce,
+		Value:       tx.Value().String(),
+	}
+
+	// The transaction is already broadcast, so it is recorded even without its token
+	token, err := s.assetService.GetTokenBySymbol(ctx, req.ChainID, req.Symbol)
+	if err != nil {
+		logger.Error("Service:CreateTransactionRecord", err)
+	} else {
+		txn.TokenID = token.ID
+		amount, err := ethereum.ToBaseUnits(req.Amount, token.Decimals)
+		if err != nil {
+			logger.Error("Service:CreateTransactionRecord", err)
+		} else {
+			txn.Amount = amount.String()
+		}
 	}
 
 	// Save transaction in the repository
@@ -174,48 +578,251 @@ func (s *TransactionService) createTransactionRecord(
 	return createdTxn, nil
 }
 
-func (s *TransactionService) handleTxn(ctx context.Context, userID string, req model.CreateAndSubmitTransactionRequest) (string, error) {
-	// Validate chain ID (Sepolia testnet: 11155111)
-	chainID := big.NewInt(11155111)
-	if req.ChainID != 0 && req.ChainID != int(chainID.Int64()) {
-		return "", fmt.Errorf("invalid chain ID: got %d, want %d", req.ChainID, chainID.Uint64())
+func (s *TransactionService) handleTxn(ctx context.Context, wallet model.Wallet, req model.CreateAndSubmitTransactionRequest) (*types.Transaction, error) {
+	client, err := s.client(ctx, req.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate addresses
 	if !common.IsHexAddress(req.FromAddress) || !common.IsHexAddress(req.ToAddress) {
-		return "", fmt.Errorf("invalid address format")
+		return nil, fmt.Errorf("invalid address format")
+	}
+
+	transfers, err := s.resolveTransfers(ctx, []model.CreateAndSubmitTransactionRequest{req})
+	if err != nil {
+		return nil, err
+	}
+
+	nonces, err := s.nonces.Reserve(ctx, client, req.ChainID, req.FromAddress, 1)
+	if err != nil {
+		return nil, err
 	}
+	// Nonces that may have reached the node stay reserved, see broadcast
+	var broadcastCount int
+	defer func() {
+		s.nonces.Release(ctx, req.ChainID, req.FromAddress, nonces[broadcastCount:])
+	}()
 
 	// Tạo transaction
-	tx, err := s.ethClient.CreateTransaction(ctx, req.FromAddress, req.ToAddress, req.Amount)
+	tx, err := client.CreateTransaction(ctx, req.FromAddress, nonces[0], transfers[0], feeOptions(req))
 	if err != nil {
-		return "", fmt.Errorf("failed to create transaction: %w", err)
+		return nil, toEthError(err)
 	}
 
 	// Lấy transaction hash
-	signer := types.NewEIP155Signer(chainID)
+	signer := types.LatestSignerForChainID(chainID)
 	txHash := signer.Hash(tx)
 
 	// Ký bằng TSS (nhận chữ ký DER)
-	derSig, err := s.tssClient.Sign(ctx, userID, req.ShareData, txHash.Bytes())
+	sig, err := s.signingService.SignHash(ctx, wallet, req.ShareData, txHash)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := tx.WithSignature(signer, sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	// Gửi transaction
+	sent, err := s.broadcast(ctx, client, req, []*types.Transaction{signedTx})
+	broadcastCount = mayHaveBroadcast(sent, err)
 	if err != nil {
-		return "", fmt.Errorf("TSS signing failed: %w", err)
+		return nil, err
 	}
+	return sent[0], nil
+}
 
-	fmt.Print("from address: ", req.FromAddress)
+// handleBatchTxn signs the transfers in one TSS session and broadcasts them in
+// order. It returns the transactions that were sent; when sending
+// fails the later transfers are not sent, since their nonces would leave a gap.
+func (s *TransactionService) handleBatchTxn(ctx context.Context, wallet model.Wallet, reqs []model.CreateAndSubmitTransactionRequest) ([]*types.Transaction, error) {
+	client, err := s.client(ctx, reqs[0].ChainID)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range reqs {
+		if req.ChainID != reqs[0].ChainID {
+			return nil, fmt.Errorf("batch spans chains %d and %d", reqs[0].ChainID, req.ChainID)
+		}
+		if !common.IsHexAddress(req.FromAddress) || !common.IsHexAddress(req.ToAddress) {
+			return nil, fmt.Errorf("invalid address format")
+		}
+	}
 
-	sig, err := utils.ConvertDERToEthSignature(derSig, txHash.Bytes(), req.FromAddress)
+	transfers, err := s.resolveTransfers(ctx, reqs)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert DER signature: %w", err)
+		return nil, err
 	}
-	signedTx, err := tx.WithSignature(signer, sig)
+
+	nonces, err := s.nonces.Reserve(ctx, client, reqs[0].ChainID, reqs[0].FromAddress, len(reqs))
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
+		return nil, err
 	}
-	// Gửi transaction
-	txHashSent, err := s.ethClient.SendTransaction(ctx, signedTx)
+	// Nonces that may have reached the node stay reserved, see broadcast
+	var broadcastCount int
+	defer func() {
+		s.nonces.Release(ctx, reqs[0].ChainID, reqs[0].FromAddress, nonces[broadcastCount:])
+	}()
+
+	txs, err := client.CreateTransactions(ctx, reqs[0].FromAddress, nonces, transfers, feeOptions(reqs[0]))
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return nil, toEthError(err)
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	txHashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		txHashes[i] = signer.Hash(tx)
+	}
+
+	sigs, err := s.signingService.SignHashes(ctx, wallet, reqs[0].ShareData, txHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTxs := make([]*types.Transaction, len(txs))
+	for i, tx := range txs {
+		if signedTxs[i], err = tx.WithSignature(signer, sigs[i]); err != nil {
+			return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		}
+	}
+
+	sent, err := s.broadcast(ctx, client, reqs[0], signedTxs)
+	broadcastCount = mayHaveBroadcast(sent, err)
+	return sent, err
+}
+
+// broadcast sends signed transactions of the sender of req in nonce order and
+// marks their nonces sent. It stops at the first failure and returns the
+// transactions sent before it. A nonce the node already counts
+// was used by a transaction sent elsewhere, so the nonces are resynced with
+// the chain.
+//
+// A send the node did not answer, e.g. on a timeout, may still have reached
+// it. It fails with ErrTransactionSendUncertain and its nonce is not
+// released: the reservation expires, and the next sync drops the nonce when
+// the node counts it or hands it out again when it does not.
+func (s *TransactionService) broadcast(
+	ctx context.Context,
+	client *ethereum.EthClient,
+	req model.CreateAndSubmitTransactionRequest,
+	signedTxs []*types.Transaction,
+) ([]*types.Transaction, error) {
+	sent := make([]*types.Transaction, 0, len(signedTxs))
+	for _, signedTx := range signedTxs {
+		txHashSent, err := client.SendTransaction(ctx, signedTx)
+		if err != nil {
+			if stderrors.Is(err, ethereum.ErrNonceTooLow) {
+				_ = s.nonces.Resync(context.WithoutCancel(ctx), client, req.ChainID, req.FromAddress)
+			}
+			if stderrors.Is(err, ethereum.ErrSendUncertain) {
+				logger.Warn("transaction may have been sent: " + signedTx.Hash().Hex())
+				return sent, fmt.Errorf("%w: %s: %w", errors.ErrTransactionSendUncertain, signedTx.Hash().Hex(), err)
+			}
+			return sent, err
+		}
+		s.nonces.MarkSent(ctx, req.ChainID, req.FromAddress, signedTx.Nonce(), txHashSent)
+		sent = append(sent, signedTx)
+	}
+	return sent, nil
+}
+
+// mayHaveBroadcast returns how many of the transactions passed to broadcast
+// may have reached the node: the ones sent, and the one that failed when the
+// node did not answer
+func mayHaveBroadcast(sent []*types.Transaction, err error) int {
+	if stderrors.Is(err, ethereum.ErrSendUncertain) {
+		return len(sent) + 1
+	}
+	return len(sent)
+}
+
+// client returns the client of a chain of the chains table
+func (s *TransactionService) client(ctx context.Context, chainID int) (*ethereum.EthClient, error) {
+	client, err := s.chains.Client(ctx, chainID)
+	if err != nil {
+		if stderrors.Is(err, ethereum.ErrChainNotSupported) {
+			return nil, errors.ErrChainNotSupported
+		}
+		logger.Error("Service:Client", err)
+		return nil, fmt.Errorf("failed to connect to chain %d: %w", chainID, err)
+	}
+	return client, nil
+}
+
+// toHistoryItem describes a transaction as seen from walletAddress. Rows
+// recorded without a token are native transfers, and rows recorded without an
+// amount show their value.
+func toHistoryItem(walletAddress string, chain model.Chain, tokens map[uuid.UUID]model.TokenResponse, txn model.Transaction) model.TransactionHistoryItem {
+	item := model.TransactionHistoryItem{
+		Transaction:  txn,
+		NativeSymbol: chain.NativeCurrency,
+		Symbol:       chain.NativeCurrency,
+	}
+
+	from := strings.EqualFold(txn.FromAddress, walletAddress)
+	to := strings.EqualFold(txn.ToAddress, walletAddress)
+	switch {
+	case from && to:
+		item.Direction = model.TransactionDirectionSelf
+	case from:
+		item.Direction = model.TransactionDirectionOutgoing
+	default:
+		item.Direction = model.TransactionDirectionIncoming
+	}
+
+	decimals := int32(ethereum.NativeDecimals)
+	native := txn.TokenID == uuid.Nil
+	if token, ok := tokens[txn.TokenID]; ok {
+		item.Symbol = token.Symbol
+		decimals = token.Decimals
+		native = token.Type == model.TokenTypeNative
+	} else if !native {
+		// The token is no longer listed, so its amount cannot be formatted
+		item.Symbol = ""
+	}
+
+	amount := txn.Amount
+	if amount == "" && native {
+		amount = txn.Value
+	}
+	if baseUnits, ok := new(big.Int).SetString(amount, 10); ok && item.Symbol != "" {
+		item.FormattedAmount = ethereum.FromBaseUnits(baseUnits, decimals)
+	}
+	if fee, ok := new(big.Int).SetString(txn.Fee, 10); ok {
+		item.FormattedFee = ethereum.FromBaseUnits(fee, ethereum.NativeDecimals)
+	}
+
+	if chain.ExplorerURL != "" {
+		item.ExplorerURL = strings.TrimRight(chain.ExplorerURL, "/") + "/tx/" + txn.TxHash
+	}
+	return item
+}
+
+// feeOptions returns the transaction type and speed chosen in the request
+func feeOptions(req model.CreateAndSubmitTransactionRequest) ethereum.FeeOptions {
+	return ethereum.FeeOptions{Type: req.TxType, Speed: req.Speed}
+}
+
+// toEthError maps the errors of building a transaction to app errors
+func toEthError(err error) error {
+	switch {
+	case stderrors.Is(err, ethereum.ErrInsufficientFunds):
+		return errors.ErrInssuficientBalance
+	case stderrors.Is(err, ethereum.ErrGasEstimation):
+		logger.Warn("Service:EstimateGas: " + err.Error())
+		return errors.ErrGasEstimationFailed
+	case stderrors.Is(err, ethereum.ErrDynamicFeeUnsupported):
+		return errors.ErrDynamicFeeUnsupported
+	default:
+		return fmt.Errorf("failed to create transaction: %w", err)
 	}
-	return txHashSent, nil
 }
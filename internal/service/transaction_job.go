@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"mpc/internal/config"
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+	"mpc/pkg/logger"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// transactionJob is one or more queued transfers from a wallet, or the
+// replacement of a sent transaction. The requests keep the client share, so
+// jobs only live in memory and the share is never written to the database.
+// Queued jobs are therefore lost when their instance stops. Every row names
+// the instance that owns it and carries a lease the instance keeps renewing;
+// the rows are failed, so clients know to submit them again, when the
+// instance starts again or by another instance once the lease expires.
+type transactionJob struct {
+	wallet      model.Wallet
+	transfers   []queuedTransfer
+	replacement *queuedReplacement
+}
+
+// queuedTransfer is a transfer and the job row tracking it
+type queuedTransfer struct {
+	id  uuid.UUID
+	req model.CreateAndSubmitTransactionRequest
+}
+
+const (
+	jobInterruptedMessage  = "job interrupted by server restart"
+	jobLeaseExpiredMessage = "job interrupted: its server stopped renewing the job"
+)
+
+// errJobNotQueued stops a job whose rows were failed while it was queued
+var errJobNotQueued = stderrors.New("job is no longer queued")
+
+// StartJobWorkers fails the jobs this instance left unfinished in a previous
+// run and those whose lease expired, then starts the workers that sign and
+// broadcast queued transfers and the heartbeat renewing the leases of this
+// instance, until ctx is cancelled. Jobs of other running instances are left
+// alone.
+func (s *TransactionService) StartJobWorkers(ctx context.Context) {
+	if err := s.jobRepo.FailOwnedTransactionJobs(ctx, s.instanceID, jobInterruptedMessage); err != nil {
+		logger.Error("Service:StartJobWorkers", err)
+	}
+	if err := s.jobRepo.FailExpiredTransactionJobs(ctx, jobLeaseExpiredMessage); err != nil {
+		logger.Error("Service:StartJobWorkers", err)
+	}
+
+	go s.runJobHeartbeat(ctx)
+	for i := 0; i < s.cfg.JobWorkers; i++ {
+		go s.runJobWorker(ctx)
+	}
+}
+
+// runJobHeartbeat renews the leases of the jobs of this instance, and fails
+// the jobs of instances that stopped renewing theirs, until ctx is cancelled
+func (s *TransactionService) runJobHeartbeat(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.JobLease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.heartbeatJobs(ctx)
+		}
+	}
+}
+
+// heartbeatJobs runs one heartbeat of the job leases
+func (s *TransactionService) heartbeatJobs(ctx context.Context) {
+	if err := s.jobRepo.RenewTransactionJobLeases(ctx, s.instanceID, time.Now().Add(s.cfg.JobLease)); err != nil {
+		logger.Error("Service:HeartbeatJobs", err)
+	}
+	if err := s.jobRepo.FailExpiredTransactionJobs(ctx, jobLeaseExpiredMessage); err != nil {
+		logger.Error("Service:HeartbeatJobs", err)
+	}
+}
+
+// jobLeaseExpiry returns the lease of a job created now
+func (s *TransactionService) jobLeaseExpiry() *time.Time {
+	until := time.Now().Add(s.cfg.JobLease)
+	return &until
+}
+
+// jobOwnerID returns the configured instance ID, or the hostname. Without
+// either a random ID is used, so the jobs of a previous run are only failed
+// once their lease expires.
+func jobOwnerID(cfg *config.TxnConfig) string {
+	if cfg.InstanceID != "" {
+		return cfg.InstanceID
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return uuid.NewString()
+}
+
+// GetJob returns a transaction job owned by the user
+func (s *TransactionService) GetJob(ctx context.Context, userID, jobID uuid.UUID) (model.TransactionJobResponse, error) {
+	job, err := s.jobRepo.GetTransactionJobByID(ctx, jobID)
+	if err != nil {
+		logger.Error("Service:GetJob", err)
+		return model.TransactionJobResponse{}, errors.ErrTransactionJobNotFound
+	}
+	if job.UserID != userID {
+		return model.TransactionJobResponse{}, errors.ErrTransactionJobNotFound
+	}
+
+	res := toTransactionJobResponse(job)
+	if job.TransactionID != uuid.Nil {
+		txn, err := s.txnRepo.GetTransactionByID(ctx, job.TransactionID)
+		if err != nil {
+			logger.Error("Service:GetJob", err)
+			return model.TransactionJobResponse{}, errors.ErrTransactionNotFound
+		}
+		res.Transaction = &txn
+	}
+	return res, nil
+}
+
+// enqueueJob records a queued job for every request and hands them to the
+// workers together. Requests sharing a non-nil batch ID are signed in one
+// session and broadcast with consecutive nonces. The jobs are attached to
+// idempotencyKey, unless it is the zero value.
+func (s *TransactionService) enqueueJob(
+	ctx context.Context,
+	wallet model.Wallet,
+	batchID uuid.UUID,
+	reqs []model.CreateAndSubmitTransactionRequest,
+	idempotencyKey model.IdempotencyKey,
+) ([]model.TransactionJobResponse, error) {
+	job := transactionJob{wallet: wallet}
+	res := make([]model.TransactionJobResponse, 0, len(reqs))
+	jobIDs := make([]uuid.UUID, 0, len(reqs))
+	for _, req := range reqs {
+		created, err := s.jobRepo.CreateTransactionJob(ctx, model.TransactionJob{
+			ID:             uuid.New(),
+			UserID:         wallet.UserID,
+			WalletID:       wallet.ID,
+			Status:         model.TransactionJobStatusQueued,
+			Type:           model.TransactionJobTypeTransfer,
+			ChainID:        req.ChainID,
+			FromAddress:    req.FromAddress,
+			ToAddress:      req.ToAddress,
+			Symbol:         req.Symbol,
+			Amount:         req.Amount,
+			BatchID:        batchID,
+			OwnerID:        s.instanceID,
+			LeaseExpiresAt: s.jobLeaseExpiry(),
+		})
+		if err != nil {
+			logger.Error("Service:EnqueueJob", err)
+			s.failJobs(ctx, job, err)
+			return nil, err
+		}
+		job.transfers = append(job.transfers, queuedTransfer{id: created.ID, req: req})
+		res = append(res, toTransactionJobResponse(created))
+		jobIDs = append(jobIDs, created.ID)
+	}
+
+	// A retry with the same key gets these jobs from now on
+	if idempotencyKey.Key != "" {
+		s.idempotency.AttachJobs(ctx, idempotencyKey, batchID, jobIDs)
+	}
+
+	if err := s.queue(ctx, job); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// queue hands a job to the workers. When the queue is full its rows are
+// failed and ErrTransactionQueueFull is returned.
+func (s *TransactionService) queue(ctx context.Context, job transactionJob) error {
+	select {
+	case s.jobs <- job:
+		return nil
+	default:
+		s.failJobs(ctx, job, errors.ErrTransactionQueueFull)
+		return errors.ErrTransactionQueueFull
+	}
+}
+
+// runJobWorker processes queued jobs one at a time
+func (s *TransactionService) runJobWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.jobs:
+			s.processJob(ctx, job)
+		}
+	}
+}
+
+// processJob signs and broadcasts the queued transfers, or the replacement,
+// and records the outcome
+func (s *TransactionService) processJob(ctx context.Context, job transactionJob) {
+	// The outcome is recorded even when the signing round hit the job timeout
+	recordCtx := context.WithoutCancel(ctx)
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.JobTimeout)
+	defer cancel()
+
+	for _, id := range job.ids() {
+		_, started, err := s.jobRepo.StartTransactionJob(ctx, id)
+		if err != nil {
+			logger.Error("Service:ProcessJob", err)
+			continue
+		}
+		if !started {
+			// The rows were failed while queued, e.g. after the lease of this
+			// instance expired, and the client may have submitted them again
+			logger.Warn(fmt.Sprintf("Service:ProcessJob: job %s is no longer queued, skipping it", id))
+			s.failJobs(recordCtx, job, errJobNotQueued)
+			return
+		}
+	}
+
+	if job.replacement != nil {
+		txn, err := s.sendReplacement(ctx, job.wallet, *job.replacement)
+		if err != nil {
+			logger.Error("Service:ProcessJob", err)
+		}
+		s.finishJob(recordCtx, job.replacement.id, txn.ID, err)
+		return
+	}
+
+	var sent []*types.Transaction
+	var err error
+	if len(job.transfers) == 1 {
+		var tx *types.Transaction
+		if tx, err = s.handleTxn(ctx, job.wallet, job.transfers[0].req); err == nil {
+			sent = []*types.Transaction{tx}
+		}
+	} else {
+		sent, err = s.handleBatchTxn(ctx, job.wallet, job.requests())
+	}
+	if err != nil {
+		logger.Error("Service:ProcessJob", err)
+	}
+
+	// Transfers before a failure are already broadcast, so record them
+	// regardless of the timeout. The rest fail with the error that stopped
+	// the batch.
+	for i, transfer := range job.transfers {
+		if i >= len(sent) {
+			s.finishJob(recordCtx, transfer.id, uuid.Nil, err)
+			continue
+		}
+
+		txn, recordErr := s.createTransactionRecord(recordCtx, transfer.req, sent[i])
+		if recordErr != nil {
+			logger.Error("Service:ProcessJob", recordErr)
+			s.finishJob(recordCtx, transfer.id, uuid.Nil, recordErr)
+			continue
+		}
+		s.finishJob(recordCtx, transfer.id, txn.ID, nil)
+	}
+}
+
+// failJobs marks every row of the job failed
+func (s *TransactionService) failJobs(ctx context.Context, job transactionJob, opErr error) {
+	for _, id := range job.ids() {
+		s.finishJob(ctx, id, uuid.Nil, opErr)
+	}
+}
+
+// finishJob marks a job completed, or failed when opErr is not nil. A job
+// already finished, e.g. failed after the lease of this instance expired,
+// keeps its status. Recording errors are only logged so they never mask the
+// outcome of the job.
+func (s *TransactionService) finishJob(ctx context.Context, jobID, transactionID uuid.UUID, opErr error) {
+	status, errorMessage, errorCode := model.TransactionJobStatusCompleted, "", ""
+	if opErr != nil {
+		status, errorMessage, errorCode = model.TransactionJobStatusFailed, opErr.Error(), errorCodeOf(opErr)
+	}
+
+	_, finished, err := s.jobRepo.FinishTransactionJob(ctx, jobID, status, transactionID, errorMessage, errorCode)
+	if err != nil {
+		logger.Error("Service:FinishJob", err)
+		return
+	}
+	if !finished {
+		logger.Warn(fmt.Sprintf("Service:FinishJob: job %s was already finished, not recording it %s with transaction %s", jobID, status, transactionID))
+	}
+}
+
+// toTransactionJobResponse converts a job to its API representation
+func toTransactionJobResponse(job model.TransactionJob) model.TransactionJobResponse {
+	return model.TransactionJobResponse{
+		ID:          job.ID,
+		Status:      job.Status,
+		Type:        job.Type,
+		BatchID:     batchIDOf(job),
+		ReplacesID:  replacesIDOf(job),
+		ChainID:     job.ChainID,
+		FromAddress: job.FromAddress,
+		ToAddress:   job.ToAddress,
+		Symbol:      job.Symbol,
+		Amount:      job.Amount,
+		Error:       job.Error,
+		ErrorCode:   job.ErrorCode,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		EndedAt:     job.EndedAt,
+	}
+}
+
+// batchIDOf returns the batch of the job, or nil for a single transfer
+func batchIDOf(job model.TransactionJob) *uuid.UUID {
+	if job.BatchID == uuid.Nil {
+		return nil
+	}
+	return &job.BatchID
+}
+
+// replacesIDOf returns the transaction a speed-up or cancel replaces, or nil
+// for a transfer
+func replacesIDOf(job model.TransactionJob) *uuid.UUID {
+	if job.ReplacesID == uuid.Nil {
+		return nil
+	}
+	return &job.ReplacesID
+}
+
+// ids returns the job rows of the job
+func (j transactionJob) ids() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(j.transfers)+1)
+	for _, transfer := range j.transfers {
+		ids = append(ids, transfer.id)
+	}
+	if j.replacement != nil {
+		ids = append(ids, j.replacement.id)
+	}
+	return ids
+}
+
+// requests returns the requests of the job in order
+func (j transactionJob) requests() []model.CreateAndSubmitTransactionRequest {
+	reqs := make([]model.CreateAndSubmitTransactionRequest, len(j.transfers))
+	for i, transfer := range j.transfers {
+		reqs[i] = transfer.req
+	}
+	return reqs
+}
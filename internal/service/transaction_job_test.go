@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+
+	// The node holds the broadcast until the test has seen the job running
+	sending, release := make(chan struct{}), make(chan struct{})
+	var released sync.Once
+	t.Cleanup(func() { released.Do(func() { close(release) }) })
+	env.node.setSendErr(func(tx *types.Transaction) (bool, error) {
+		sending <- struct{}{}
+		<-release
+		return true, nil
+	})
+
+	queued, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "", env.transfer("0.01"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if queued.Status != model.TransactionJobStatusQueued || queued.Type != model.TransactionJobTypeTransfer || queued.StartedAt != nil {
+		t.Fatalf("submitted a %s job that is %s, want a queued transfer", queued.Type, queued.Status)
+	}
+
+	select {
+	case <-sending:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the job was not broadcast")
+	}
+	running, err := env.txns.GetJob(ctx, env.wallet.UserID, queued.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if running.Status != model.TransactionJobStatusRunning || running.StartedAt == nil || running.EndedAt != nil {
+		t.Fatalf("job is %s while broadcasting, want %s", running.Status, model.TransactionJobStatusRunning)
+	}
+
+	released.Do(func() { close(release) })
+	job := env.waitForJob(t, queued.ID)
+	if job.Status != model.TransactionJobStatusCompleted || job.Transaction == nil || job.EndedAt == nil || job.Error != "" {
+		t.Fatalf("job finished %s: %s", job.Status, job.Error)
+	}
+
+	// Jobs are only visible to their owner
+	if _, err := env.txns.GetJob(ctx, uuid.New(), queued.ID); !stderrors.Is(err, errors.ErrTransactionJobNotFound) {
+		t.Fatalf("get the job of another user: got %v, want %v", err, errors.ErrTransactionJobNotFound)
+	}
+}
+
+func TestJobQueueFull(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	// Without workers the queue fills up
+	for i := 0; i < env.txns.cfg.JobQueueSize; i++ {
+		if _, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "", env.transfer("0.01")); err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+	}
+	_, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "", env.transfer("0.01"))
+	if !stderrors.Is(err, errors.ErrTransactionQueueFull) {
+		t.Fatalf("submit to a full queue: got %v, want %v", err, errors.ErrTransactionQueueFull)
+	}
+
+	var failed []model.TransactionJob
+	for _, job := range env.store.jobs {
+		if job.Status == model.TransactionJobStatusFailed {
+			failed = append(failed, job)
+		}
+	}
+	if len(failed) != 1 || failed[0].ErrorCode != errors.ErrTransactionQueueFull.Code {
+		t.Fatalf("%d jobs failed, want the rejected one failed with %s", len(failed), errors.ErrTransactionQueueFull.Code)
+	}
+}
+
+func TestStartJobWorkersFailsUnfinished(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	queued, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "", env.transfer("0.01"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	running, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "", env.transfer("0.02"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, _, err := env.store.StartTransactionJob(ctx, running.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Another instance is still working on one job and stopped renewing the other
+	live := env.otherInstanceJob(t, time.Now().Add(time.Minute))
+	expired := env.otherInstanceJob(t, time.Now().Add(-time.Second))
+
+	// A restart loses the queue, only the rows are left
+	env.txns.jobs = make(chan transactionJob, env.txns.cfg.JobQueueSize)
+	env.startWorkers(t)
+
+	for _, left := range []model.TransactionJobResponse{queued, running} {
+		job := env.waitForJob(t, left.ID)
+		if job.Status != model.TransactionJobStatusFailed || job.Error != jobInterruptedMessage {
+			t.Fatalf("job %s left from before the restart finished %s: %s", left.ID, job.Status, job.Error)
+		}
+	}
+	if job := env.store.jobs[expired.ID]; job.Status != model.TransactionJobStatusFailed || job.Error != jobLeaseExpiredMessage {
+		t.Fatalf("job of a stopped instance is %s: %s, want it failed", job.Status, job.Error)
+	}
+	if job := env.store.jobs[live.ID]; job.Status != model.TransactionJobStatusQueued {
+		t.Fatalf("job of a running instance is %s, want it left %s", job.Status, model.TransactionJobStatusQueued)
+	}
+	if sent := env.node.sentTransactions(); len(sent) != 0 {
+		t.Fatalf("node got %d transactions, want none", len(sent))
+	}
+
+	// Jobs queued after the restart run as usual
+	txn := env.send(t, "0.01")
+	if txn.Nonce == nil || *txn.Nonce != 0 {
+		t.Fatalf("sent with nonce %v, want 0", txn.Nonce)
+	}
+}
+
+func TestHeartbeatJobs(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	queued, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "", env.transfer("0.01"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	leased := *env.store.jobs[queued.ID].LeaseExpiresAt
+	live := env.otherInstanceJob(t, time.Now().Add(time.Minute))
+	expired := env.otherInstanceJob(t, time.Now().Add(-time.Second))
+
+	time.Sleep(10 * time.Millisecond)
+	env.txns.heartbeatJobs(ctx)
+
+	if job := env.store.jobs[queued.ID]; job.Status != model.TransactionJobStatusQueued || !job.LeaseExpiresAt.After(leased) {
+		t.Fatalf("own job is %s with lease %v, want it queued with the lease renewed after %v", job.Status, job.LeaseExpiresAt, leased)
+	}
+	if job := env.store.jobs[live.ID]; job.Status != model.TransactionJobStatusQueued || !job.LeaseExpiresAt.Equal(*live.LeaseExpiresAt) {
+		t.Fatalf("job of another running instance is %s, want it untouched", job.Status)
+	}
+	if job := env.store.jobs[expired.ID]; job.Status != model.TransactionJobStatusFailed || job.Error != jobLeaseExpiredMessage {
+		t.Fatalf("job with an expired lease is %s: %s, want it failed", job.Status, job.Error)
+	}
+}
+
+func TestJobFailedWhileQueued(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	queued, err := env.txns.CreateAndSubmitTransaction(ctx, env.wallet.UserID, "", env.transfer("0.01"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// The lease ran out before a worker got to the job, and another instance
+	// failed it, so the client may already have submitted it again
+	env.store.mu.Lock()
+	job := env.store.jobs[queued.ID]
+	past := time.Now().Add(-time.Second)
+	job.LeaseExpiresAt = &past
+	env.store.jobs[queued.ID] = job
+	env.store.mu.Unlock()
+	if err := env.store.FailExpiredTransactionJobs(ctx, jobLeaseExpiredMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	// With one worker, the failed job has been picked up once the next one is sent
+	env.startWorkers(t)
+	env.send(t, "0.02")
+
+	if sent := env.node.sentTransactions(); len(sent) != 1 {
+		t.Fatalf("node got %d transactions, want only the later job", len(sent))
+	}
+	failed, err := env.txns.GetJob(ctx, env.wallet.UserID, queued.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if failed.Status != model.TransactionJobStatusFailed || failed.Error != jobLeaseExpiredMessage || failed.StartedAt != nil {
+		t.Fatalf("job failed while queued is %s: %s, want it left failed and never started", failed.Status, failed.Error)
+	}
+}
+
+// otherInstanceJob stores a queued job of another API instance with the given lease
+func (e *testEnv) otherInstanceJob(t *testing.T, leaseExpiresAt time.Time) model.TransactionJob {
+	t.Helper()
+	job, err := e.store.CreateTransactionJob(context.Background(), model.TransactionJob{
+		ID:             uuid.New(),
+		UserID:         e.wallet.UserID,
+		WalletID:       e.wallet.ID,
+		Status:         model.TransactionJobStatusQueued,
+		Type:           model.TransactionJobTypeTransfer,
+		ChainID:        testChainID,
+		FromAddress:    e.wallet.Address,
+		ToAddress:      recipient,
+		Symbol:         "ETH",
+		Amount:         "0.01",
+		OwnerID:        "api-2",
+		LeaseExpiresAt: &leaseExpiresAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+	"mpc/pkg/ethereum"
+	"mpc/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// queuedReplacement is a speed-up or cancel of a sent transaction and the job
+// row tracking it
+type queuedReplacement struct {
+	id       uuid.UUID
+	original model.Transaction
+	req      model.ReplaceTransactionRequest
+	cancel   bool
+}
+
+// SpeedUpTransaction queues a replacement of a pending transaction of the user
+// with the same transfer at higher fees. The returned job can be polled with
+// GetJob; its transaction links to the original.
+func (s *TransactionService) SpeedUpTransaction(
+	ctx context.Context,
+	userID, txnID uuid.UUID,
+	req model.ReplaceTransactionRequest,
+) (model.TransactionJobResponse, error) {
+	return s.replaceTransaction(ctx, userID, txnID, req, false)
+}
+
+// CancelTransaction queues a replacement of a pending transaction of the user
+// with a zero value transfer to the sender itself, so the original is never
+// mined. The returned job can be polled with GetJob; its transaction links to
+// the original.
+func (s *TransactionService) CancelTransaction(
+	ctx context.Context,
+	userID, txnID uuid.UUID,
+	req model.ReplaceTransactionRequest,
+) (model.TransactionJobResponse, error) {
+	return s.replaceTransaction(ctx, userID, txnID, req, true)
+}
+
+// replaceTransaction checks that the user can replace a transaction and
+// queues the replacement. Whether the node still has the original in its
+// pool is checked by the worker, right before the replacement is signed.
+func (s *TransactionService) replaceTransaction(
+	ctx context.Context,
+	userID, txnID uuid.UUID,
+	req model.ReplaceTransactionRequest,
+	cancel bool,
+) (model.TransactionJobResponse, error) {
+	original, err := s.txnRepo.GetTransactionByID(ctx, txnID)
+	if err != nil {
+		logger.Error("Service:ReplaceTransaction", err)
+		return model.TransactionJobResponse{}, errors.ErrTransactionNotFound
+	}
+
+	wallet, err := s.walletService.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("Service:ReplaceTransaction", err)
+		return model.TransactionJobResponse{}, errors.ErrTransactionNotFound
+	}
+	if !strings.EqualFold(wallet.Address, original.FromAddress) {
+		return model.TransactionJobResponse{}, errors.ErrTransactionNotFound
+	}
+	if original.Nonce == nil ||
+		(original.Status != model.TransactionStatusSubmitted && original.Status != model.TransactionStatusPending) {
+		return model.TransactionJobResponse{}, errors.ErrTransactionNotReplaceable
+	}
+	if req.ShareData, err = s.walletService.ResolveShareData(ctx, wallet, req.ShareData); err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+
+	job, err := s.replacementJob(ctx, wallet, original, cancel)
+	if err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+	// Two replacements would sign for the same nonce, so one waits for the other
+	created, ok, err := s.jobRepo.CreateReplacementJob(ctx, job)
+	if err != nil {
+		logger.Error("Service:ReplaceTransaction", err)
+		return model.TransactionJobResponse{}, err
+	}
+	if !ok {
+		return model.TransactionJobResponse{}, errors.ErrReplacementInProgress
+	}
+
+	if err := s.queue(ctx, transactionJob{
+		wallet:      wallet,
+		replacement: &queuedReplacement{id: created.ID, original: original, req: req, cancel: cancel},
+	}); err != nil {
+		return model.TransactionJobResponse{}, err
+	}
+	return toTransactionJobResponse(created), nil
+}
+
+// replacementJob returns the job row of a speed-up or cancel of original,
+// with the amount in whole units of its token
+func (s *TransactionService) replacementJob(ctx context.Context, wallet model.Wallet, original model.Transaction, cancel bool) (model.TransactionJob, error) {
+	chain, err := s.assetService.chainRepo.GetChainByChainID(ctx, original.ChainID)
+	if err != nil {
+		logger.Error("Service:ReplaceTransaction", err)
+		return model.TransactionJob{}, errors.ErrChainNotSupported
+	}
+
+	job := model.TransactionJob{
+		ID:             uuid.New(),
+		UserID:         wallet.UserID,
+		WalletID:       wallet.ID,
+		Status:         model.TransactionJobStatusQueued,
+		Type:           model.TransactionJobTypeSpeedUp,
+		ChainID:        original.ChainID,
+		FromAddress:    original.FromAddress,
+		ToAddress:      original.ToAddress,
+		ReplacesID:     original.ID,
+		OwnerID:        s.instanceID,
+		LeaseExpiresAt: s.jobLeaseExpiry(),
+	}
+	if cancel {
+		job.Type = model.TransactionJobTypeCancel
+		job.ToAddress = original.FromAddress
+		job.Symbol = chain.NativeCurrency
+		job.Amount = "0"
+		return job, nil
+	}
+
+	tokens := make(map[uuid.UUID]model.TokenResponse)
+	chainTokens, err := s.assetService.GetTokensByChainID(ctx, original.ChainID)
+	if err != nil {
+		logger.Error("Service:ReplaceTransaction", err)
+	}
+	for _, token := range chainTokens {
+		tokens[token.ID] = token
+	}
+	item := toHistoryItem(original.FromAddress, chain, tokens, original)
+	job.Symbol, job.Amount = item.Symbol, item.FormattedAmount
+	return job, nil
+}
+
+// sendReplacement signs and broadcasts a transaction with the nonce of a
+// pending one and fees high enough for the node to take it in its place. The
+// replacement is recorded and the original marked replaced once it is sent.
+func (s *TransactionService) sendReplacement(
+	ctx context.Context,
+	wallet model.Wallet,
+	replacement queuedReplacement,
+) (model.Transaction, error) {
+	original := replacement.original
+	client, err := s.client(ctx, original.ChainID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	// Only a transaction still in the node's pool can be replaced
+	pendingTx, err := client.PendingTransaction(ctx, original.TxHash)
+	if err != nil {
+		if stderrors.Is(err, ethereum.ErrTransactionNotPending) {
+			return model.Transaction{}, errors.ErrTransactionNotReplaceable
+		}
+		return model.Transaction{}, err
+	}
+
+	var tx *types.Transaction
+	if replacement.cancel {
+		tx, err = client.CancelTransaction(ctx, original.FromAddress, pendingTx, replacement.req.Speed)
+	} else {
+		tx, err = client.SpeedUpTransaction(ctx, pendingTx, replacement.req.Speed)
+	}
+	if err != nil {
+		return model.Transaction{}, toEthError(err)
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	sig, err := s.signingService.SignHash(ctx, wallet, replacement.req.ShareData, signer.Hash(tx))
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	signedTx, err := tx.WithSignature(signer, sig)
+	if err != nil {
+		return model.Transaction{}, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	txHash, err := client.SendTransaction(ctx, signedTx)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, ethereum.ErrReplacementUnderpriced):
+			return model.Transaction{}, errors.ErrReplacementUnderpriced
+		case stderrors.Is(err, ethereum.ErrNonceTooLow):
+			// The original was mined meanwhile
+			return model.Transaction{}, errors.ErrTransactionNotReplaceable
+		case stderrors.Is(err, ethereum.ErrInsufficientFunds):
+			return model.Transaction{}, errors.ErrInssuficientBalance
+		case stderrors.Is(err, ethereum.ErrSendUncertain):
+			logger.Warn("transaction may have been sent: " + signedTx.Hash().Hex())
+			return model.Transaction{}, fmt.Errorf("%w: %s: %w", errors.ErrTransactionSendUncertain, signedTx.Hash().Hex(), err)
+		}
+		return model.Transaction{}, err
+	}
+
+	// The replacement is broadcast, so it is recorded regardless of the job timeout
+	ctx = context.WithoutCancel(ctx)
+	s.nonces.MarkSent(ctx, original.ChainID, original.FromAddress, signedTx.Nonce(), txHash)
+
+	record := model.Transaction{
+		FromAddress: original.FromAddress,
+		ToAddress:   original.ToAddress,
+		TxHash:      strings.ToLower(txHash),
+		ChainID:     original.ChainID,
+		TokenID:     original.TokenID,
+		Status:      model.TransactionStatusSubmitted,
+		Nonce:       original.Nonce,
+		Value:       signedTx.Value().String(),
+		Amount:      original.Amount,
+		ReplacesID:  original.ID,
+	}
+	if replacement.cancel {
+		record.ToAddress = original.FromAddress
+		record.TokenID = uuid.Nil
+		record.Amount = "0"
+	}
+	created, err := s.txnRepo.CreateTransaction(ctx, record)
+	if err != nil {
+		logger.Error("Service:SendReplacement", err)
+		return model.Transaction{}, fmt.Errorf("failed to create transaction record: %w", err)
+	}
+
+	// The tracker follows the replacement from now on, see trackTransactions
+	if err := s.txnRepo.MarkTransactionReplaced(ctx, original.ID); err != nil {
+		logger.Error("Service:SendReplacement", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// send submits a transfer and waits for its transaction
+func (e *testEnv) send(t *testing.T, amount string) model.Transaction {
+	t.Helper()
+	queued, err := e.txns.CreateAndSubmitTransaction(context.Background(), e.wallet.UserID, "", e.transfer(amount))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	job := e.waitForJob(t, queued.ID)
+	if job.Transaction == nil {
+		t.Fatalf("job finished %s: %s", job.Status, job.Error)
+	}
+	return *job.Transaction
+}
+
+// transaction returns the stored transaction
+func (e *testEnv) transaction(t *testing.T, txn model.Transaction) model.Transaction {
+	t.Helper()
+	stored, err := e.store.GetTransactionByID(context.Background(), txn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stored
+}
+
+func TestSpeedUpTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+	original := env.send(t, "0.01")
+
+	queued, err := env.txns.SpeedUpTransaction(ctx, env.wallet.UserID, original.ID, model.ReplaceTransactionRequest{ShareData: env.share})
+	if err != nil {
+		t.Fatalf("speed up: %v", err)
+	}
+	if queued.Type != model.TransactionJobTypeSpeedUp || queued.ReplacesID == nil || *queued.ReplacesID != original.ID {
+		t.Fatalf("queued %s job replacing %v, want a speed-up of %s", queued.Type, queued.ReplacesID, original.ID)
+	}
+	if queued.Symbol != "ETH" || queued.Amount != "0.01" || queued.ToAddress != recipient {
+		t.Fatalf("queued %s %s to %s, want the original transfer", queued.Amount, queued.Symbol, queued.ToAddress)
+	}
+
+	job := env.waitForJob(t, queued.ID)
+	if job.Status != model.TransactionJobStatusCompleted || job.Transaction == nil {
+		t.Fatalf("job finished %s: %s", job.Status, job.Error)
+	}
+	replacement := job.Transaction
+	if replacement.ReplacesID != original.ID || *replacement.Nonce != *original.Nonce || replacement.Amount != original.Amount {
+		t.Fatalf("replacement %v does not match the original %v", replacement, original)
+	}
+
+	sent := env.node.sentTransactions()
+	if len(sent) != 2 || sent[1].GasFeeCap().Cmp(sent[0].GasFeeCap()) <= 0 {
+		t.Fatalf("node got %d transactions, want the original and a higher priced replacement", len(sent))
+	}
+	if got := env.transaction(t, original).Status; got != model.TransactionStatusReplaced {
+		t.Fatalf("original is %s, want %s", got, model.TransactionStatusReplaced)
+	}
+
+	// A replaced transaction cannot be replaced again
+	_, err = env.txns.SpeedUpTransaction(ctx, env.wallet.UserID, original.ID, model.ReplaceTransactionRequest{ShareData: env.share})
+	if !stderrors.Is(err, errors.ErrTransactionNotReplaceable) {
+		t.Fatalf("speed up the replaced original: got %v, want %v", err, errors.ErrTransactionNotReplaceable)
+	}
+}
+
+func TestCancelTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	original := env.send(t, "0.01")
+
+	queued, err := env.txns.CancelTransaction(context.Background(), env.wallet.UserID, original.ID, model.ReplaceTransactionRequest{ShareData: env.share})
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if queued.Type != model.TransactionJobTypeCancel || queued.ToAddress != env.wallet.Address || queued.Amount != "0" {
+		t.Fatalf("queued %s job sending %s to %s, want a cancel to the sender", queued.Type, queued.Amount, queued.ToAddress)
+	}
+
+	job := env.waitForJob(t, queued.ID)
+	if job.Status != model.TransactionJobStatusCompleted || job.Transaction == nil {
+		t.Fatalf("job finished %s: %s", job.Status, job.Error)
+	}
+	sent := env.node.sentTransactions()
+	if len(sent) != 2 || *sent[1].To() != common.HexToAddress(env.wallet.Address) || sent[1].Value().Sign() != 0 {
+		t.Fatalf("node got %d transactions, want the original and a zero transfer to the sender", len(sent))
+	}
+	if got := env.transaction(t, original).Status; got != model.TransactionStatusReplaced {
+		t.Fatalf("original is %s, want %s", got, model.TransactionStatusReplaced)
+	}
+}
+
+func TestReplaceMinedTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	original := env.send(t, "0.01")
+	env.node.mine(t, common.HexToHash(original.TxHash))
+
+	// The tracker has not seen the block yet, so only the worker finds out
+	queued, err := env.txns.SpeedUpTransaction(context.Background(), env.wallet.UserID, original.ID, model.ReplaceTransactionRequest{ShareData: env.share})
+	if err != nil {
+		t.Fatalf("speed up: %v", err)
+	}
+	job := env.waitForJob(t, queued.ID)
+	if job.Status != model.TransactionJobStatusFailed || job.ErrorCode != errors.ErrTransactionNotReplaceable.Code {
+		t.Fatalf("job finished %s with %s, want %s", job.Status, job.ErrorCode, errors.ErrTransactionNotReplaceable.Code)
+	}
+	if got := env.transaction(t, original).Status; got != model.TransactionStatusSubmitted {
+		t.Fatalf("original is %s, want %s", got, model.TransactionStatusSubmitted)
+	}
+}
+
+func TestTrackReplacedTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+	original := env.send(t, "0.01")
+
+	queued, err := env.txns.SpeedUpTransaction(ctx, env.wallet.UserID, original.ID, model.ReplaceTransactionRequest{ShareData: env.share})
+	if err != nil {
+		t.Fatalf("speed up: %v", err)
+	}
+	replacement := *env.waitForJob(t, queued.ID).Transaction
+
+	// A miner that saw the original first includes it, so the replacement is dropped
+	env.node.mine(t, common.HexToHash(original.TxHash))
+	env.track(ctx)
+	env.node.advance(env.txns.cfg.DropBlocks)
+	env.track(ctx)
+	if got := env.transaction(t, replacement).Status; got != model.TransactionStatusDropped {
+		t.Fatalf("replacement is %s, want %s", got, model.TransactionStatusDropped)
+	}
+	if got := env.transaction(t, original).Status; got != model.TransactionStatusSubmitted {
+		t.Fatalf("original is %s, want it tracked again as %s", got, model.TransactionStatusSubmitted)
+	}
+
+	env.track(ctx)
+	if got := env.transaction(t, original); got.Status != model.TransactionStatusConfirmed || !strings.EqualFold(got.BlockHash, blockHash(1).Hex()) {
+		t.Fatalf("original is %s in block %s, want it confirmed", got.Status, got.BlockHash)
+	}
+}
+
+func TestReplaceTransactionInProgress(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+	original := env.send(t, "0.01")
+
+	// The node holds the speed-up until the test has tried another replacement
+	sending, release := make(chan struct{}), make(chan struct{})
+	var released sync.Once
+	t.Cleanup(func() { released.Do(func() { close(release) }) })
+	env.node.setSendErr(func(tx *types.Transaction) (bool, error) {
+		sending <- struct{}{}
+		<-release
+		return true, nil
+	})
+
+	queued, err := env.txns.SpeedUpTransaction(ctx, env.wallet.UserID, original.ID, model.ReplaceTransactionRequest{ShareData: env.share})
+	if err != nil {
+		t.Fatalf("speed up: %v", err)
+	}
+	<-sending
+	_, err = env.txns.CancelTransaction(ctx, env.wallet.UserID, original.ID, model.ReplaceTransactionRequest{ShareData: env.share})
+	if !stderrors.Is(err, errors.ErrReplacementInProgress) {
+		t.Fatalf("cancel during the speed-up: got %v, want %v", err, errors.ErrReplacementInProgress)
+	}
+
+	released.Do(func() { close(release) })
+	if job := env.waitForJob(t, queued.ID); job.Status != model.TransactionJobStatusCompleted {
+		t.Fatalf("speed-up finished %s: %s", job.Status, job.Error)
+	}
+	replacements := 0
+	for _, txn := range env.store.transactions {
+		if txn.ReplacesID == original.ID {
+			replacements++
+		}
+	}
+	if replacements != 1 {
+		t.Fatalf("recorded %d replacements, want 1", replacements)
+	}
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"mpc/internal/config"
+	rd "mpc/internal/db/redis"
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+	"mpc/pkg/ethereum"
+	"mpc/pkg/lock"
+	"mpc/pkg/tss"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const recipient = "0x742d35cc6634c0532925a3b844bc454e4438f44e"
+
+// testEnv is a TransactionService signing with tss.Software and sending to a
+// fakeNode, with a wallet to send from
+type testEnv struct {
+	redis  *miniredis.Miniredis
+	store  *memStore
+	node   *fakeNode
+	chains *ethereum.Registry
+	nonces *NonceManager
+	txns   *TransactionService
+	wallet model.Wallet
+	share  string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+	redisClient := &rd.Client{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { redisClient.Close() })
+
+	node := newFakeNode(t)
+	store := newMemStore()
+	chain := model.Chain{ID: uuid.New(), Name: "Devnet", ChainID: testChainID, RPCURL: node.URL, NativeCurrency: "ETH", Status: model.ChainStatusActive}
+	store.chains = []model.Chain{chain}
+	store.tokens = []model.Token{{ID: uuid.New(), ChainID: chain.ID, Name: "Ether", Symbol: "ETH", Decimals: 18, Type: model.TokenTypeNative}}
+
+	sessions := NewTSSSessionService(store)
+	tssClient := tss.NewSoftware(nil, defaultTopology)
+	wallets := NewWalletService(store, sessions, tssClient, testTopologies, nil)
+	assets := NewAssetService(store, store, redisClient)
+	signing := NewSigningService(wallets, assets, sessions, tssClient)
+
+	chains := ethereum.NewRegistry(store, 20)
+	t.Cleanup(chains.Close)
+	locker := lock.NewLocker(redisClient)
+	nonces := NewNonceManager(store, locker, &config.NonceConfig{
+		ReservationTTL: time.Minute,
+		LockTTL:        10 * time.Second,
+		LockWait:       5 * time.Second,
+	})
+	idempotency := NewIdempotencyService(store, redisClient, &config.IdempotencyConfig{KeyTTL: time.Hour, ClaimTTL: time.Minute})
+	txns := NewTransactionService(store, store, wallets, assets, signing, chains, nonces, idempotency, locker, &config.TxnConfig{
+		JobWorkers:    1,
+		JobQueueSize:  10,
+		JobTimeout:    time.Minute,
+		InstanceID:    "api-1",
+		JobLease:      time.Minute,
+		TrackInterval: 15 * time.Second,
+		DropTimeout:   30 * time.Minute,
+		DropBlocks:    3,
+	})
+
+	wallet, share, err := wallets.CreateWallet(ctx, uuid.New(), defaultTopology)
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	return &testEnv{redis: mr, store: store, node: node, chains: chains, nonces: nonces, txns: txns, wallet: wallet, share: share}
+}
+
+// startWorkers runs the job workers until the test ends
+func (e *testEnv) startWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	e.txns.StartJobWorkers(ctx)
+}
+
+func (e *testEnv) transfer(amount string) model.CreateAndSubmitTransactionRequest {
+	return model.CreateAndSubmitTransactionRequest{
+		FromAddress: e.wallet.Address,
+		ToAddress:   recipient,
+		ChainID:     testChainID,
+		Symbol:      "ETH",
+		Amount:      amount,
+		ShareData:   e.share,
+	}
+}
+
+// waitForJob polls a job until it is completed or failed
+func (e *testEnv) waitForJob(t *testing.T, jobID uuid.UUID) model.TransactionJobResponse {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := e.txns.GetJob(context.Background(), e.wallet.UserID, jobID)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if job.Status == model.TransactionJobStatusCompleted || job.Status == model.TransactionJobStatusFailed {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", jobID)
+	return model.TransactionJobResponse{}
+}
+
+func TestSubmitTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+
+	queued, err := env.txns.CreateAndSubmitTransaction(context.Background(), env.wallet.UserID, "", env.transfer("0.01"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if queued.Status != model.TransactionJobStatusQueued {
+		t.Fatalf("job is %s, want %s", queued.Status, model.TransactionJobStatusQueued)
+	}
+
+	job := env.waitForJob(t, queued.ID)
+	if job.Status != model.TransactionJobStatusCompleted || job.Transaction == nil {
+		t.Fatalf("job finished %s: %s", job.Status, job.Error)
+	}
+
+	sent := env.node.sentTransactions()
+	if len(sent) != 1 {
+		t.Fatalf("node got %d transactions, want 1", len(sent))
+	}
+	if from := sender(t, sent[0]); from != env.wallet.Address {
+		t.Fatalf("transaction signed by %s, want %s", from, env.wallet.Address)
+	}
+	if job.Transaction.TxHash != strings.ToLower(sent[0].Hash().Hex()) || job.Transaction.Amount != "10000000000000000" {
+		t.Fatalf("recorded %+v for %s", job.Transaction, sent[0].Hash().Hex())
+	}
+	if got := env.store.noncesByStatus(testChainID, env.wallet.Address, model.WalletNonceStatusSent); len(got) != 1 || got[0] != 0 {
+		t.Fatalf("sent nonces are %v, want [0]", got)
+	}
+}
+
+func TestSubmitBatchTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+
+	res, err := env.txns.CreateBatchTransaction(context.Background(), env.wallet.UserID, "", model.CreateBatchTransactionRequest{
+		FromAddress: env.wallet.Address,
+		ChainID:     testChainID,
+		Transfers: []model.BatchTransfer{
+			{ToAddress: recipient, Symbol: "ETH", Amount: "0.01"},
+			{ToAddress: recipient, Symbol: "ETH", Amount: "0.02"},
+		},
+		ShareData: env.share,
+	})
+	if err != nil {
+		t.Fatalf("submit batch: %v", err)
+	}
+
+	for i, queued := range res.Jobs {
+		job := env.waitForJob(t, queued.ID)
+		if job.Status != model.TransactionJobStatusCompleted {
+			t.Fatalf("job %d finished %s: %s", i, job.Status, job.Error)
+		}
+		if job.Transaction.Nonce == nil || *job.Transaction.Nonce != uint64(i) {
+			t.Fatalf("job %d sent with nonce %v, want %d", i, job.Transaction.Nonce, i)
+		}
+	}
+	if sent := env.node.sentTransactions(); len(sent) != 2 {
+		t.Fatalf("node got %d transactions, want 2", len(sent))
+	}
+}
+
+func TestSubmitTransactionChecks(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		req     func(req *model.CreateAndSubmitTransactionRequest)
+		wantErr error
+	}{
+		{name: "insufficient balance", req: func(req *model.CreateAndSubmitTransactionRequest) { req.Amount = "2" }, wantErr: errors.ErrInssuficientBalance},
+		{name: "invalid amount", req: func(req *model.CreateAndSubmitTransactionRequest) { req.Amount = "-1" }, wantErr: errors.ErrInvalidAmount},
+		{name: "missing share", req: func(req *model.CreateAndSubmitTransactionRequest) { req.ShareData = "" }, wantErr: errors.ErrShareDataRequired},
+		{name: "unknown chain", req: func(req *model.CreateAndSubmitTransactionRequest) { req.ChainID = 1 }, wantErr: errors.ErrChainNotSupported},
+		{name: "another user's wallet", userID: uuid.New(), req: func(req *model.CreateAndSubmitTransactionRequest) {}, wantErr: errors.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := env.wallet.UserID
+			if tt.userID != uuid.Nil {
+				userID = tt.userID
+			}
+			req := env.transfer("0.01")
+			tt.req(&req)
+
+			_, err := env.txns.CreateAndSubmitTransaction(ctx, userID, "", req)
+			if !stderrors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(env.store.jobs) != 0 {
+		t.Fatalf("rejected transfers queued %d jobs", len(env.store.jobs))
+	}
+}
+
+func TestSendFailureNonces(t *testing.T) {
+	tests := []struct {
+		name string
+		// accepted reports whether the node keeps the transaction it failed
+		accepted bool
+		sendErr  error
+		wantCode string
+		// wantReserved is the nonce kept reserved after the failure, if any
+		wantReserved []uint64
+		// wantNext is the nonce reserved once the reservations expired
+		wantNext uint64
+	}{
+		{name: "rejected", sendErr: stderrors.New("transaction rejected"), wantNext: 0},
+		{name: "no answer, not received", sendErr: errNoAnswer, wantCode: errors.ErrTransactionSendUncertain.Code, wantReserved: []uint64{0}, wantNext: 0},
+		{name: "no answer, received", accepted: true, sendErr: errNoAnswer, wantCode: errors.ErrTransactionSendUncertain.Code, wantReserved: []uint64{0}, wantNext: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.startWorkers(t)
+			env.node.setSendErr(func(tx *types.Transaction) (bool, error) { return tt.accepted, tt.sendErr })
+
+			queued, err := env.txns.CreateAndSubmitTransaction(context.Background(), env.wallet.UserID, "", env.transfer("0.01"))
+			if err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+			job := env.waitForJob(t, queued.ID)
+			if job.Status != model.TransactionJobStatusFailed || job.ErrorCode != tt.wantCode {
+				t.Fatalf("job finished %s with %q, want failed with %q", job.Status, job.ErrorCode, tt.wantCode)
+			}
+
+			reserved := env.store.noncesByStatus(testChainID, env.wallet.Address, model.WalletNonceStatusReserved)
+			if !slices.Equal(reserved, tt.wantReserved) {
+				t.Fatalf("reserved nonces are %v, want %v", reserved, tt.wantReserved)
+			}
+
+			env.node.setSendErr(nil)
+			env.expireReservations()
+			if got := env.reserve(t, 1); got[0] != tt.wantNext {
+				t.Fatalf("next nonce is %d, want %d", got[0], tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestBatchSendFailureNonces(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	// The node takes the first transfer and does not answer for the second
+	env.node.setSendErr(func(tx *types.Transaction) (bool, error) {
+		if tx.Nonce() == 1 {
+			return false, errNoAnswer
+		}
+		return true, nil
+	})
+
+	res, err := env.txns.CreateBatchTransaction(context.Background(), env.wallet.UserID, "", model.CreateBatchTransactionRequest{
+		FromAddress: env.wallet.Address,
+		ChainID:     testChainID,
+		Transfers: []model.BatchTransfer{
+			{ToAddress: recipient, Symbol: "ETH", Amount: "0.01"},
+			{ToAddress: recipient, Symbol: "ETH", Amount: "0.01"},
+			{ToAddress: recipient, Symbol: "ETH", Amount: "0.01"},
+		},
+		ShareData: env.share,
+	})
+	if err != nil {
+		t.Fatalf("submit batch: %v", err)
+	}
+
+	wantStatus := []string{model.TransactionJobStatusCompleted, model.TransactionJobStatusFailed, model.TransactionJobStatusFailed}
+	for i, queued := range res.Jobs {
+		if job := env.waitForJob(t, queued.ID); job.Status != wantStatus[i] {
+			t.Fatalf("job %d finished %s, want %s", i, job.Status, wantStatus[i])
+		}
+	}
+
+	for status, want := range map[string][]uint64{
+		model.WalletNonceStatusSent:     {0},
+		model.WalletNonceStatusReserved: {1},
+		model.WalletNonceStatusReleased: {2},
+	} {
+		if got := env.store.noncesByStatus(testChainID, env.wallet.Address, status); !slices.Equal(got, want) {
+			t.Errorf("%s nonces are %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestRecordTransactionSeenByWorker(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	req := env.transfer("0.01")
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 0, Value: big.NewInt(1e16)})
+
+	// The worker found the transaction in a block before the API recorded it
+	nonce, block := uint64(0), uint64(7)
+	seen, err := env.store.CreateTransaction(ctx, model.Transaction{
+		FromAddress: req.FromAddress,
+		ToAddress:   req.ToAddress,
+		TxHash:      strings.ToLower(tx.Hash().Hex()),
+		ChainID:     req.ChainID,
+		Status:      model.TransactionStatusPending,
+		Nonce:       &nonce,
+		Value:       tx.Value().String(),
+		BlockNumber: &block,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded, err := env.txns.createTransactionRecord(ctx, req, tx)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if recorded.ID != seen.ID || len(env.store.transactions) != 1 {
+		t.Fatalf("recorded %s next to the worker's %s, want one row", recorded.ID, seen.ID)
+	}
+	if recorded.Amount != "10000000000000000" || recorded.TokenID == uuid.Nil || *recorded.BlockNumber != block {
+		t.Fatalf("recorded %+v, want the worker's row with the token and amount filled in", recorded)
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"mpc/internal/model"
+	"mpc/pkg/ethereum"
+	"mpc/pkg/lock"
+	"mpc/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// trackBatchSize is the most transactions read at once while polling
+	trackBatchSize = 200
+	// trackLockKey is held by the API instance polling for the interval
+	trackLockKey = "transaction_tracker"
+)
+
+// StartConfirmationTracker starts polling the receipts of submitted and
+// pending transactions every TXN_TRACK_INTERVAL until ctx is cancelled. Every
+// API instance runs it, but only one polls per interval.
+func (s *TransactionService) StartConfirmationTracker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.cfg.TrackInterval)
+		defer ticker.Stop()
+
+		for {
+			s.trackTransactions(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// trackTransactions moves the transactions that are not final yet to the
+// status their receipt gives them. They are read a batch at a time in creation
+// order, so every one of them is checked in each poll.
+//
+// The poll takes a lock that is left to expire just before the next tick, so
+// the other instances skip their polls until the next interval.
+func (s *TransactionService) trackTransactions(ctx context.Context) {
+	ttl := s.cfg.TrackInterval * 9 / 10
+	if _, err := s.locker.Acquire(ctx, trackLockKey, ttl, 0); err != nil {
+		if !stderrors.Is(err, lock.ErrNotAcquired) {
+			logger.Error("Service:TrackTransactions", err)
+		}
+		return
+	}
+
+	// Chains are read once per poll, a chain that fails is skipped until the next
+	clients := make(map[int]*ethereum.EthClient)
+	heads := make(map[int]uint64)
+	failed := make(map[int]bool)
+
+	var afterCreatedAt time.Time
+	afterID := uuid.Nil
+	for {
+		txns, err := s.txnRepo.GetTrackedTransactions(ctx, afterCreatedAt, afterID, trackBatchSize)
+		if err != nil {
+			logger.Error("Service:TrackTransactions", err)
+			return
+		}
+
+		for _, txn := range txns {
+			if failed[txn.ChainID] {
+				continue
+			}
+			client, ok := clients[txn.ChainID]
+			if !ok {
+				if client, err = s.chains.Client(ctx, txn.ChainID); err == nil {
+					heads[txn.ChainID], err = client.BlockNumber(ctx)
+				}
+				if err != nil {
+					logger.Error("Service:TrackTransactions", err)
+					failed[txn.ChainID] = true
+					continue
+				}
+				clients[txn.ChainID] = client
+			}
+
+			tracked, err := s.trackTransaction(ctx, client, heads[txn.ChainID], txn)
+			if err != nil {
+				logger.Error("Service:TrackTransaction", err)
+				continue
+			}
+			if tracked.Status == txn.Status && tracked.Confirmations == txn.Confirmations && tracked.BlockHash == txn.BlockHash &&
+				tracked.Fee == txn.Fee && sameBlock(tracked.NonceUsedBlock, txn.NonceUsedBlock) {
+				continue
+			}
+			if err := s.txnRepo.UpdateTransactionStatus(ctx, tracked); err != nil {
+				logger.Error("Service:TrackTransaction", err)
+				continue
+			}
+			// The original may have been mined in place of a dropped replacement
+			if tracked.Status == model.TransactionStatusDropped && txn.ReplacesID != uuid.Nil {
+				if err := s.txnRepo.RestoreReplacedTransaction(ctx, txn.ReplacesID); err != nil {
+					logger.Error("Service:TrackTransaction", err)
+				}
+			}
+			if tracked.Status != txn.Status {
+				logger.Info("transaction status changed",
+					zap.String("tx_hash", txn.TxHash),
+					zap.String("from", txn.Status),
+					zap.String("to", tracked.Status),
+					zap.Int("confirmations", tracked.Confirmations))
+			}
+		}
+
+		if len(txns) < trackBatchSize {
+			return
+		}
+		last := txns[len(txns)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+}
+
+// trackTransaction returns txn with the status and block of its receipt. A
+// mined transaction is pending until it has TXN_CONFIRMATIONS confirmations,
+// then confirmed, or failed when it reverted. A transaction the node does not
+// know is dropped once its nonce was used by another transaction for
+// TXN_DROP_BLOCKS blocks, or after TXN_DROP_TIMEOUT.
+//
+// Dropped is final, so a lagging or load balanced node answering that it has
+// no receipt is not taken at its word: the receipt is checked again on every
+// poll until the nonce has been used for TXN_DROP_BLOCKS blocks.
+func (s *TransactionService) trackTransaction(
+	ctx context.Context,
+	client *ethereum.EthClient,
+	head uint64,
+	txn model.Transaction,
+) (model.Transaction, error) {
+	receipt, err := client.TransactionReceipt(ctx, txn.TxHash)
+	if err != nil {
+		return txn, err
+	}
+	if receipt != nil {
+		txn.NonceUsedBlock = nil
+		blockNumber := receipt.BlockNumber.Uint64()
+		gasUsed := receipt.GasUsed
+		txn.BlockNumber = &blockNumber
+		txn.BlockHash = strings.ToLower(receipt.BlockHash.Hex())
+		txn.GasUsed = &gasUsed
+		if receipt.EffectiveGasPrice != nil {
+			txn.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+			txn.Fee = new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), receipt.EffectiveGasPrice).String()
+		}
+		txn.Confirmations = 0
+		if head >= blockNumber {
+			txn.Confirmations = int(head - blockNumber + 1)
+		}
+
+		switch {
+		case txn.Confirmations < s.cfg.Confirmations:
+			txn.Status = model.TransactionStatusPending
+		case receipt.Status == types.ReceiptStatusSuccessful:
+			txn.Status = model.TransactionStatusConfirmed
+		default:
+			txn.Status = model.TransactionStatusFailed
+		}
+		return txn, nil
+	}
+
+	// Not mined, or its block was reorganized away
+	txn.BlockNumber = nil
+	txn.BlockHash = ""
+	txn.GasUsed = nil
+	txn.EffectiveGasPrice = ""
+	txn.Fee = ""
+	txn.Confirmations = 0
+
+	known, err := client.IsKnownTransaction(ctx, txn.TxHash)
+	if err != nil {
+		return txn, err
+	}
+	if known {
+		txn.Status = model.TransactionStatusPending
+		txn.NonceUsedBlock = nil
+		return txn, nil
+	}
+
+	if txn.Nonce != nil {
+		confirmed, err := client.ConfirmedNonce(ctx, txn.FromAddress)
+		if err != nil {
+			return txn, err
+		}
+		if confirmed > *txn.Nonce {
+			if txn.NonceUsedBlock == nil {
+				txn.NonceUsedBlock = &head
+			}
+			if head >= *txn.NonceUsedBlock+s.cfg.DropBlocks {
+				txn.Status = model.TransactionStatusDropped
+			}
+			return txn, nil
+		}
+	}
+	// The block that used the nonce was reorganized away
+	txn.NonceUsedBlock = nil
+	if time.Since(txn.CreatedAt) > s.cfg.DropTimeout {
+		txn.Status = model.TransactionStatusDropped
+		// The nonce was not used, hand it out again so later sends are not stuck behind it
+		if txn.Nonce != nil {
+			s.nonces.ReleaseDropped(ctx, txn.ChainID, txn.FromAddress, *txn.Nonce, txn.TxHash)
+		}
+	}
+	return txn, nil
+}
+
+// sameBlock reports whether two optional block numbers are equal
+func sameBlock(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
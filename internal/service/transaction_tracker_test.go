@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"mpc/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// track polls once the tracker lock of the previous poll has expired
+func (e *testEnv) track(ctx context.Context) {
+	e.redis.FastForward(e.txns.cfg.TrackInterval)
+	e.txns.trackTransactions(ctx)
+}
+
+func TestTrackTransactionsPages(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	// More transactions than one batch, all gone from the node after another used their nonce
+	var nonce uint64
+	for i := 0; i < trackBatchSize+1; i++ {
+		_, err := env.store.CreateTransaction(ctx, model.Transaction{
+			FromAddress: env.wallet.Address,
+			ToAddress:   recipient,
+			ChainID:     testChainID,
+			TxHash:      common.BigToHash(big.NewInt(int64(i + 1))).Hex(),
+			Status:      model.TransactionStatusSubmitted,
+			Nonce:       &nonce,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(env.store.transactions) != trackBatchSize+1 {
+		t.Fatalf("stored %d transactions, want %d", len(env.store.transactions), trackBatchSize+1)
+	}
+	env.node.mu.Lock()
+	env.node.confirmed[common.HexToAddress(env.wallet.Address)] = 1
+	env.node.mu.Unlock()
+
+	// The first poll finds the nonces used, the one after TXN_DROP_BLOCKS drops them
+	env.track(ctx)
+	env.node.advance(env.txns.cfg.DropBlocks)
+	env.track(ctx)
+	for _, txn := range env.store.transactions {
+		if txn.Status != model.TransactionStatusDropped {
+			t.Fatalf("transaction created at %s is %s, want every one %s", txn.CreatedAt, txn.Status, model.TransactionStatusDropped)
+		}
+	}
+}
+
+func TestTrackTransactionsLock(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+
+	env.track(ctx)
+	txn := env.send(t, "0.01")
+	env.node.mine(t, common.HexToHash(txn.TxHash))
+
+	// Another instance polled within the interval, so this one skips
+	env.txns.trackTransactions(ctx)
+	if got := env.transaction(t, txn).Status; got != model.TransactionStatusSubmitted {
+		t.Fatalf("transaction is %s, want the poll skipped", got)
+	}
+
+	env.track(ctx)
+	if got := env.transaction(t, txn).Status; got != model.TransactionStatusConfirmed {
+		t.Fatalf("transaction is %s after the next interval, want %s", got, model.TransactionStatusConfirmed)
+	}
+}
+
+func TestTrackLaggingNode(t *testing.T) {
+	env := newTestEnv(t)
+	env.startWorkers(t)
+	ctx := context.Background()
+	txn := env.send(t, "0.01")
+
+	// The node counts the nonce as used but has no receipt for the transaction yet
+	hash := common.HexToHash(txn.TxHash)
+	env.node.mine(t, hash)
+	env.node.lag(hash, true)
+	env.track(ctx)
+	env.node.advance(env.txns.cfg.DropBlocks - 1)
+	env.track(ctx)
+	if got := env.transaction(t, txn).Status; got != model.TransactionStatusSubmitted {
+		t.Fatalf("transaction is %s while the node lags, want it still %s", got, model.TransactionStatusSubmitted)
+	}
+
+	env.node.lag(hash, false)
+	env.node.advance(env.txns.cfg.DropBlocks)
+	env.track(ctx)
+	if got := env.transaction(t, txn); got.Status != model.TransactionStatusConfirmed || got.NonceUsedBlock != nil {
+		t.Fatalf("transaction is %s once the node caught up, want %s", got.Status, model.TransactionStatusConfirmed)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"mpc/internal/model"
+	"mpc/pkg/errors"
+	"mpc/pkg/logger"
+	"mpc/pkg/tss"
+
+	"github.com/google/uuid"
+)
+
+type TSSSessionService struct {
+	sessionRepo TSSSessionStore
+}
+
+func NewTSSSessionService(sessionRepo TSSSessionStore) *TSSSessionService {
+	return &TSSSessionService{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Start records a new running session with a unique ID. walletID is uuid.Nil
+// for keygen, messageHash is empty for anything but signing.
+func (s *TSSSessionService) Start(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	sessionType string,
+	messageHash string,
+	topology tss.Topology,
+) (model.TSSSession, error) {
+	session, err := s.sessionRepo.CreateTSSSession(ctx, model.TSSSession{
+		ID:          uuid.New(),
+		UserID:      userID,
+		WalletID:    walletID,
+		Type:        sessionType,
+		Status:      model.TSSSessionStatusRunning,
+		MessageHash: messageHash,
+		Parties:     topology.Parties,
+		Threshold:   topology.Threshold,
+	})
+	if err != nil {
+		logger.Error("Service:StartTSSSession", err)
+		return model.TSSSession{}, err
+	}
+	return session, nil
+}
+
+// Finish marks a session completed, or failed when opErr is not nil. Recording
+// errors are only logged so they never mask the outcome of the operation.
+func (s *TSSSessionService) Finish(ctx context.Context, sessionID uuid.UUID, opErr error) {
+	status, errorMessage, errorCode := model.TSSSessionStatusCompleted, "", ""
+	if opErr != nil {
+		status, errorMessage, errorCode = model.TSSSessionStatusFailed, opErr.Error(), errorCodeOf(opErr)
+	}
+
+	if _, err := s.sessionRepo.FinishTSSSession(ctx, sessionID, status, errorMessage, errorCode); err != nil {
+		logger.Error("Service:FinishTSSSession", err)
+	}
+}
+
+// AttachWallet links a keygen session to the wallet it produced
+func (s *TSSSessionService) AttachWallet(ctx context.Context, sessionID, walletID uuid.UUID) {
+	if err := s.sessionRepo.SetTSSSessionWallet(ctx, sessionID, walletID); err != nil {
+		logger.Error("Service:AttachTSSSessionWallet", err)
+	}
+}
+
+// GetSession returns a session owned by the user
+func (s *TSSSessionService) GetSession(ctx context.Context, userID, sessionID uuid.UUID) (model.TSSSessionResponse, error) {
+	session, err := s.sessionRepo.GetTSSSessionByID(ctx, sessionID)
+	if err != nil {
+		logger.Error("Service:GetTSSSession", err)
+		return model.TSSSessionResponse{}, errors.ErrTSSSessionNotFound
+	}
+	if session.UserID != userID {
+		return model.TSSSessionResponse{}, errors.ErrTSSSessionNotFound
+	}
+	return toTSSSessionResponse(session), nil
+}
+
+// toTSSSessionResponse converts a session to its API representation
+func toTSSSessionResponse(session model.TSSSession) model.TSSSessionResponse {
+	res := model.TSSSessionResponse{
+		ID:          session.ID,
+		Type:        session.Type,
+		Status:      session.Status,
+		MessageHash: session.MessageHash,
+		Parties:     session.Parties,
+		Threshold:   session.Threshold,
+		Error:       session.Error,
+		ErrorCode:   session.ErrorCode,
+		StartedAt:   session.StartedAt,
+		EndedAt:     session.EndedAt,
+	}
+	if session.WalletID != uuid.Nil {
+		walletID := session.WalletID
+		res.WalletID = &walletID
+	}
+	return res
+}
+
+// toTSSError maps failures reported by pkg/tss to API errors so clients can
+// tell transient failures from ones that need their attention. Other errors
+// are returned unchanged.
+func toTSSError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case stderrors.Is(err, tss.ErrPartyUnavailable):
+		return errors.ErrTSSPartyUnavailable
+	case stderrors.Is(err, tss.ErrNoQuorum):
+		return errors.ErrTSSNoQuorum
+	case stderrors.Is(err, tss.ErrTimeout):
+		return errors.ErrTSSTimeout
+	case stderrors.Is(err, tss.ErrAborted):
+		return errors.ErrTSSAborted
+	case stderrors.Is(err, tss.ErrInvalidShare):
+		return errors.ErrTSSInvalidShare
+	case stderrors.Is(err, tss.ErrCheating):
+		return errors.ErrTSSPartyMisbehaved
+	case stderrors.Is(err, tss.ErrInvalidTopology):
+		return errors.ErrTSSInvalidTopology
+	case stderrors.Is(err, tss.ErrMalformedResult):
+		return errors.ErrTSSMalformedResult
+	case stderrors.Is(err, tss.ErrNodeFailure):
+		return errors.ErrTSSFailed
+	}
+	return err
+}
+
+// errorCodeOf returns the API error code for err, or "" for unexpected errors
+func errorCodeOf(err error) string {
+	var appErr *errors.AppError
+	if stderrors.As(toTSSError(err), &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
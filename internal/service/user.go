@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"mpc/internal/db/redis"
 	"mpc/internal/model"
-	"mpc/internal/repository"
 	"mpc/pkg/cache"
 	"mpc/pkg/errors"
 	"mpc/pkg/logger"
@@ -18,12 +17,12 @@ import (
 )
 
 type UserService struct {
-	userRepo   *repository.UserRepository
-	walletRepo *repository.WalletRepository
+	userRepo   UserStore
+	walletRepo WalletStore
 	cache      *cache.Cache
 }
 
-func NewUserService(userRepo *repository.UserRepository, walletRepo *repository.WalletRepository, redisClient *redis.Client) *UserService {
+func NewUserService(userRepo UserStore, walletRepo WalletStore, redisClient *redis.Client) *UserService {
 	return &UserService{
 		userRepo:   userRepo,
 		walletRepo: walletRepo,
@@ -2,43 +2,122 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"mpc/internal/model"
-	"mpc/internal/repository"
+	"mpc/pkg/envelope"
 	"mpc/pkg/errors"
 	"mpc/pkg/logger"
 	"mpc/pkg/tss"
+	"mpc/pkg/utils"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/uuid"
 )
 
+// keyVerificationPrefix prefixes the key ID in the challenge signed by new keys
+const keyVerificationPrefix = "mpc key verification:"
+
 type WalletService struct {
-	walletRepo *repository.WalletRepository
-	tssClient  *tss.TSS
+	walletRepo     WalletStore
+	sessionService *TSSSessionService
+	tssClient      tss.Client
+	// topologies are the ones new wallets may use, the default one first
+	topologies []tss.Topology
+	// shareVault encrypts client shares kept by the backend, nil when custody is disabled
+	shareVault *envelope.Envelope
 }
 
-func NewWalletService(walletRepo *repository.WalletRepository, tssClient *tss.TSS) *WalletService {
+func NewWalletService(
+	walletRepo WalletStore,
+	sessionService *TSSSessionService,
+	tssClient tss.Client,
+	topologies []tss.Topology,
+	shareVault *envelope.Envelope,
+) *WalletService {
 	return &WalletService{
-		walletRepo: walletRepo,
-		tssClient:  tssClient,
+		walletRepo:     walletRepo,
+		sessionService: sessionService,
+		tssClient:      tssClient,
+		topologies:     topologies,
+		shareVault:     shareVault,
+	}
+}
+
+// DefaultTopology returns the topology used for wallets created at signup
+func (s *WalletService) DefaultTopology() tss.Topology {
+	return s.tssClient.DefaultTopology()
+}
+
+// ResolveTopology returns the topology a new wallet requested, e.g. 2-of-2
+// for retail or 3-of-5 for treasury, or the default one when it requested
+// neither parties nor threshold. Signup calls it for users that are not
+// authenticated yet, so only the topologies of TSS_TOPOLOGIES are accepted.
+func (s *WalletService) ResolveTopology(parties []uint32, threshold uint32) (tss.Topology, error) {
+	if len(parties) == 0 && threshold == 0 {
+		return s.DefaultTopology(), nil
+	}
+	for _, topology := range s.topologies {
+		if topology.Matches(parties, threshold) {
+			return topology, nil
+		}
 	}
+	return tss.Topology{}, errors.ErrTSSInvalidTopology
 }
 
-func (s *WalletService) CreateWallet(ctx context.Context, userID uuid.UUID) (model.Wallet, string, error) {
+func (s *WalletService) CreateWallet(ctx context.Context, userID uuid.UUID, topology tss.Topology) (_ model.Wallet, _ string, err error) {
+	session, err := s.sessionService.Start(ctx, userID, uuid.Nil, model.TSSSessionTypeKeygen, "", topology)
+	if err != nil {
+		return model.Wallet{}, "", err
+	}
+	// Record the node's failure on the session, return the API error
+	defer func() {
+		s.sessionService.Finish(ctx, session.ID, err)
+		err = toTSSError(err)
+	}()
+
 	// Create Ethereum wallet
-	shareData, addressHex, err := s.tssClient.CreateWallet(ctx, userID.String())
+	shareData, publicKey, err := s.tssClient.CreateWallet(ctx, session.ID.String(), topology)
 	if err != nil {
 		logger.Error("Service:CreateWallet", err)
 		return model.Wallet{}, "", err
 	}
-	addressHex = strings.ToLower(addressHex)
+	addressHex := strings.ToLower(crypto.PubkeyToAddress(*publicKey).Hex())
+
+	// Prove the parties can sign with the key before the wallet is stored
+	verifySessionID, err := s.verifyKey(ctx, userID, uuid.Nil, session.ID.String(), shareData, addressHex, topology)
+	if err != nil {
+		logger.Error("Service:CreateWallet", err)
+		return model.Wallet{}, "", err
+	}
+
+	// In custody mode the share is stored encrypted and never returned
+	encryptedShare := []byte("")
+	if s.shareVault != nil {
+		encryptedShare, err = s.sealShare(ctx, addressHex, shareData)
+		if err != nil {
+			logger.Error("Service:CreateWallet", err)
+			return model.Wallet{}, "", err
+		}
+		shareData = ""
+	}
 
 	// Create wallet in repository
-	wallet, err := s.walletRepo.CreateWallet(ctx, userID, addressHex, []byte(""), "Default")
+	wallet, err := s.walletRepo.CreateWallet(ctx, model.Wallet{
+		UserID:              userID,
+		Address:             addressHex,
+		EncryptedPrivateKey: string(encryptedShare),
+		Name:                "Default",
+		Parties:             topology.Parties,
+		Threshold:           topology.Threshold,
+		KeyID:               session.ID.String(),
+	})
 	if err != nil {
 		logger.Error("Service:CreateWallet", err)
 		return model.Wallet{}, "", err
 	}
+	s.sessionService.AttachWallet(ctx, session.ID, wallet.ID)
+	s.sessionService.AttachWallet(ctx, verifySessionID, wallet.ID)
 	return wallet, shareData, nil
 }
 
@@ -53,3 +132,161 @@ func (s *WalletService) GetWalletByUserID(ctx context.Context, userID uuid.UUID)
 	}
 	return wallets[0], nil
 }
+
+// GetUserWallet returns the wallet with the given ID if it belongs to the user
+func (s *WalletService) GetUserWallet(ctx context.Context, userID, walletID uuid.UUID) (model.Wallet, error) {
+	wallet, err := s.walletRepo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		logger.Error("Service:GetUserWallet", err)
+		return model.Wallet{}, errors.ErrWalletNotFound
+	}
+	if wallet.UserID != userID {
+		return model.Wallet{}, errors.ErrWalletNotFound
+	}
+	return wallet, nil
+}
+
+// RefreshShares rotates the key shares of a wallet and returns the new client
+// share. The previous share stops being usable once the nodes finish.
+func (s *WalletService) RefreshShares(ctx context.Context, userID, walletID uuid.UUID, shareData string) (model.RefreshSharesResponse, error) {
+	wallet, err := s.GetUserWallet(ctx, userID, walletID)
+	if err != nil {
+		return model.RefreshSharesResponse{}, err
+	}
+
+	shareData, err = s.ResolveShareData(ctx, wallet, shareData)
+	if err != nil {
+		return model.RefreshSharesResponse{}, err
+	}
+
+	return s.reshare(ctx, wallet, shareData)
+}
+
+// reshare runs a reshare session with the current client share and stores or
+// returns the new one. The share version of the wallet is bumped either way.
+func (s *WalletService) reshare(ctx context.Context, wallet model.Wallet, shareData string) (_ model.RefreshSharesResponse, err error) {
+	session, err := s.sessionService.Start(ctx, wallet.UserID, wallet.ID, model.TSSSessionTypeReshare, "", walletTopology(wallet))
+	if err != nil {
+		return model.RefreshSharesResponse{}, err
+	}
+	defer func() {
+		s.sessionService.Finish(ctx, session.ID, err)
+		err = toTSSError(err)
+	}()
+
+	newShareData, publicKey, err := s.tssClient.RefreshShares(ctx, session.ID.String(), wallet.KeyID, shareData, walletTopology(wallet))
+	if err != nil {
+		logger.Error("Service:RefreshShares", err)
+		return model.RefreshSharesResponse{}, err
+	}
+	if addressHex := crypto.PubkeyToAddress(*publicKey).Hex(); !strings.EqualFold(addressHex, wallet.Address) {
+		logger.Error("Service:RefreshShares", fmt.Errorf("reshare returned %s for wallet %s", addressHex, wallet.Address))
+		return model.RefreshSharesResponse{}, errors.ErrReshareFailed
+	}
+
+	// The new share version makes backups of the old share stale
+	encryptedShare := ""
+	if isCustodial(wallet) {
+		sealed, err := s.sealShare(ctx, wallet.Address, newShareData)
+		if err != nil {
+			logger.Error("Service:RefreshShares", err)
+			return model.RefreshSharesResponse{}, err
+		}
+		encryptedShare = string(sealed)
+		newShareData = ""
+	}
+	if wallet, err = s.walletRepo.RotateWalletShare(ctx, wallet.ID, encryptedShare); err != nil {
+		logger.Error("Service:RefreshShares", err)
+		return model.RefreshSharesResponse{}, err
+	}
+
+	return model.RefreshSharesResponse{
+		Wallet:    utils.ToWalletResponse(wallet),
+		ShareData: newShareData,
+	}, nil
+}
+
+// verifyKey signs a challenge bound to the key ID with a share and checks
+// that the signature recovers to the address of the key. It runs as its own
+// session; walletID is uuid.Nil for a new key, whose session is attached to
+// the wallet once it is stored.
+func (s *WalletService) verifyKey(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	keyID, shareData, address string,
+	topology tss.Topology,
+) (_ uuid.UUID, err error) {
+	challenge := crypto.Keccak256Hash([]byte(keyVerificationPrefix + keyID))
+	session, err := s.sessionService.Start(ctx, userID, walletID, model.TSSSessionTypeKeyVerify, challenge.Hex(), topology)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer func() { s.sessionService.Finish(ctx, session.ID, err) }()
+
+	derSig, err := s.tssClient.Sign(ctx, session.ID.String(), keyID, shareData, challenge.Bytes(), topology)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("key verification signing failed: %w", err)
+	}
+	if _, err := utils.ConvertDERToEthSignature(derSig, challenge.Bytes(), address); err != nil {
+		logger.Error("Service:VerifyKey", err)
+		return uuid.Nil, errors.ErrTSSKeyVerification
+	}
+	return session.ID, nil
+}
+
+// VerifyShare checks that a client share still signs for the wallet with the
+// nodes, e.g. before it is backed up. A share replaced by a reshare fails
+// with ErrTSSInvalidShare.
+func (s *WalletService) VerifyShare(ctx context.Context, wallet model.Wallet, shareData string) error {
+	if _, err := s.verifyKey(ctx, wallet.UserID, wallet.ID, wallet.KeyID, shareData, wallet.Address, walletTopology(wallet)); err != nil {
+		logger.Error("Service:VerifyShare", err)
+		return toTSSError(err)
+	}
+	return nil
+}
+
+// ResolveShareData returns the client share used to sign for the wallet. For
+// custodial wallets the stored share is decrypted and the provided one ignored.
+func (s *WalletService) ResolveShareData(ctx context.Context, wallet model.Wallet, provided string) (string, error) {
+	if !isCustodial(wallet) {
+		if provided == "" {
+			return "", errors.ErrShareDataRequired
+		}
+		return provided, nil
+	}
+
+	if s.shareVault == nil {
+		return "", errors.ErrCustodyUnavailable
+	}
+	shareData, err := s.shareVault.Open(ctx, []byte(wallet.EncryptedPrivateKey), []byte(wallet.Address))
+	if err != nil {
+		logger.Error("Service:ResolveShareData", err)
+		return "", errors.ErrShareDecryptFailure
+	}
+	return string(shareData), nil
+}
+
+// sealShare encrypts a client share, bound to the wallet address
+func (s *WalletService) sealShare(ctx context.Context, address, shareData string) ([]byte, error) {
+	if s.shareVault == nil {
+		return nil, errors.ErrCustodyUnavailable
+	}
+	encrypted, err := s.shareVault.Seal(ctx, []byte(shareData), []byte(address))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt share: %w", err)
+	}
+	return encrypted, nil
+}
+
+// isCustodial reports whether the backend keeps the client share of the wallet
+func isCustodial(wallet model.Wallet) bool {
+	return wallet.EncryptedPrivateKey != ""
+}
+
+// walletTopology returns the topology a wallet's key was generated with
+func walletTopology(wallet model.Wallet) tss.Topology {
+	return tss.Topology{
+		Parties:   wallet.Parties,
+		Threshold: wallet.Threshold,
+	}
+}
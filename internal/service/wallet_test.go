@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"slices"
+	"testing"
+
+	"mpc/internal/model"
+	"mpc/pkg/envelope"
+	"mpc/pkg/errors"
+	"mpc/pkg/tss"
+
+	"github.com/google/uuid"
+)
+
+var defaultTopology = tss.Topology{Parties: []uint32{1, 2}, Threshold: 2}
+
+// testTopologies are the topologies new wallets may request in tests
+var testTopologies = []tss.Topology{
+	defaultTopology,
+	{Parties: []uint32{1, 2, 3}, Threshold: 2},
+	{Parties: []uint32{1, 2, 3, 4, 5}, Threshold: 3},
+}
+
+func newWalletService(t *testing.T, store *memStore, shareVault *envelope.Envelope) *WalletService {
+	t.Helper()
+	return NewWalletService(store, NewTSSSessionService(store), tss.NewSoftware(nil, defaultTopology), testTopologies, shareVault)
+}
+
+func TestCreateWallet(t *testing.T) {
+	store := newMemStore()
+	wallets := newWalletService(t, store, nil)
+	ctx := context.Background()
+
+	topology := tss.Topology{Parties: []uint32{1, 2, 3}, Threshold: 2}
+	userID := uuid.New()
+	wallet, shareData, err := wallets.CreateWallet(ctx, userID, topology)
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	if shareData == "" {
+		t.Fatal("a non-custodial wallet must return its share")
+	}
+	if wallet.UserID != userID || wallet.Threshold != 2 || len(wallet.Parties) != 3 {
+		t.Fatalf("wallet stored with %v, want the requested topology", wallet)
+	}
+
+	// Keygen and the key verification both ran as completed sessions of the wallet
+	sessions := map[string]model.TSSSession{}
+	for _, session := range store.sessions {
+		sessions[session.Type] = session
+	}
+	for _, sessionType := range []string{model.TSSSessionTypeKeygen, model.TSSSessionTypeKeyVerify} {
+		session, ok := sessions[sessionType]
+		if !ok {
+			t.Fatalf("no %s session", sessionType)
+		}
+		if session.Status != model.TSSSessionStatusCompleted || session.WalletID != wallet.ID {
+			t.Errorf("%s session is %s for wallet %s", sessionType, session.Status, session.WalletID)
+		}
+	}
+}
+
+func TestCreateCustodialWallet(t *testing.T) {
+	kek, err := envelope.NewAESKEK("test", make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newMemStore()
+	wallets := newWalletService(t, store, envelope.New(kek))
+	ctx := context.Background()
+
+	wallet, shareData, err := wallets.CreateWallet(ctx, uuid.New(), defaultTopology)
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	if shareData != "" || !isCustodial(wallet) {
+		t.Fatal("a custodial wallet must keep its share encrypted")
+	}
+
+	// The stored share signs, and a share sent by the client is ignored
+	resolved, err := wallets.ResolveShareData(ctx, wallet, "ignored")
+	if err != nil {
+		t.Fatalf("resolve share: %v", err)
+	}
+	if err := wallets.VerifyShare(ctx, wallet, resolved); err != nil {
+		t.Fatalf("sign with the stored share: %v", err)
+	}
+}
+
+func TestResolveTopology(t *testing.T) {
+	wallets := newWalletService(t, newMemStore(), nil)
+
+	tests := []struct {
+		name      string
+		parties   []uint32
+		threshold uint32
+		want      tss.Topology
+		wantErr   error
+	}{
+		{name: "default", want: defaultTopology},
+		{name: "treasury", parties: []uint32{1, 2, 3, 4, 5}, threshold: 3, want: tss.Topology{Parties: []uint32{1, 2, 3, 4, 5}, Threshold: 3}},
+		{name: "threshold above parties", parties: []uint32{1, 2}, threshold: 3, wantErr: errors.ErrTSSInvalidTopology},
+		{name: "threshold without parties", threshold: 2, wantErr: errors.ErrTSSInvalidTopology},
+		{name: "duplicate party", parties: []uint32{1, 1}, threshold: 2, wantErr: errors.ErrTSSInvalidTopology},
+		{name: "parties in another order", parties: []uint32{3, 1, 2}, threshold: 2, want: tss.Topology{Parties: []uint32{1, 2, 3}, Threshold: 2}},
+		{name: "single party", parties: []uint32{1}, threshold: 1, wantErr: errors.ErrTSSInvalidTopology},
+		{name: "not allowed", parties: []uint32{1, 2, 3}, threshold: 3, wantErr: errors.ErrTSSInvalidTopology},
+		{name: "party the deployment does not run", parties: []uint32{1, 9}, threshold: 2, wantErr: errors.ErrTSSInvalidTopology},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wallets.ResolveTopology(tt.parties, tt.threshold)
+			if tt.wantErr != nil {
+				if !stderrors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Threshold != tt.want.Threshold || !slices.Equal(got.Parties, tt.want.Parties) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshShares(t *testing.T) {
+	store := newMemStore()
+	wallets := newWalletService(t, store, nil)
+	ctx := context.Background()
+
+	wallet, oldShare, err := wallets.CreateWallet(ctx, uuid.New(), defaultTopology)
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+
+	res, err := wallets.RefreshShares(ctx, wallet.UserID, wallet.ID, oldShare)
+	if err != nil {
+		t.Fatalf("refresh shares: %v", err)
+	}
+	if res.ShareData == "" || res.ShareData == oldShare {
+		t.Fatal("refresh must return a new share")
+	}
+	if res.Wallet.Address != wallet.Address {
+		t.Fatal("refresh changed the address")
+	}
+
+	// The share before the refresh can no longer sign
+	_, err = wallets.RefreshShares(ctx, wallet.UserID, wallet.ID, oldShare)
+	if !stderrors.Is(err, errors.ErrTSSInvalidShare) {
+		t.Fatalf("refresh with the old share: got %v, want %v", err, errors.ErrTSSInvalidShare)
+	}
+
+	// Another user cannot see the wallet
+	_, err = wallets.RefreshShares(ctx, uuid.New(), wallet.ID, res.ShareData)
+	if !stderrors.Is(err, errors.ErrWalletNotFound) {
+		t.Fatalf("refresh by another user: got %v, want %v", err, errors.ErrWalletNotFound)
+	}
+}
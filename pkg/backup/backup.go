@@ -0,0 +1,219 @@
+// Package backup encrypts client key shares with a password so a user can
+// keep an offline copy and recover the wallet if the share is lost.
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// Version is the current backup format. Version 1 backups carry no share
+	// version and can still be opened.
+	Version = 2
+
+	versionWithoutShareVersion = 1
+
+	kdfArgon2id  = "argon2id"
+	cipherAESGCM = "aes-256-gcm"
+	keySize      = 32
+	saltSize     = 16
+
+	// Parameters used for new backups
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+
+	// Limits on the parameters of a backup being opened, so a crafted file
+	// cannot make the server spend unbounded memory or time
+	maxArgonTime   = 10
+	maxArgonMemory = 256 * 1024 // KiB
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("backup: unsupported version")
+	ErrChecksumMismatch   = errors.New("backup: checksum mismatch")
+	ErrInvalidFormat      = errors.New("backup: invalid format")
+	ErrWrongPassword      = errors.New("backup: wrong password or corrupted backup")
+)
+
+// File is the versioned JSON backup of a client share. The share is encrypted
+// with a key derived from the password; the wallet fields are authenticated
+// with it, so a backup cannot be used for another wallet or another
+// generation of its shares. Checksum catches files damaged in storage before
+// the password is tried.
+type File struct {
+	Version      int       `json:"version" example:"2"`
+	WalletID     string    `json:"wallet_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Address      string    `json:"address" example:"0x742d35cc6634c0532925a3b844bc454e4438f44e"`
+	KeyID        string    `json:"key_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ShareVersion int       `json:"share_version,omitempty" example:"1"`
+	CreatedAt    time.Time `json:"created_at"`
+	KDF          KDF       `json:"kdf"`
+	Cipher       string    `json:"cipher" example:"aes-256-gcm"`
+	Nonce        string    `json:"nonce"`
+	Ciphertext   string    `json:"ciphertext"`
+	Checksum     string    `json:"checksum"`
+}
+
+// KDF holds the password key derivation parameters
+type KDF struct {
+	Name    string `json:"name" example:"argon2id"`
+	Salt    string `json:"salt"`
+	Time    uint32 `json:"time" example:"3"`
+	Memory  uint32 `json:"memory" example:"65536"`
+	Threads uint8  `json:"threads" example:"4"`
+}
+
+// Wallet identifies the wallet and the generation of its shares a share
+// belongs to
+type Wallet struct {
+	ID           string
+	Address      string
+	KeyID        string
+	ShareVersion int
+}
+
+// Encrypt creates a backup of shareData protected by password
+func Encrypt(shareData string, wallet Wallet, password string) (File, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return File{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	file := File{
+		Version:      Version,
+		WalletID:     wallet.ID,
+		Address:      wallet.Address,
+		KeyID:        wallet.KeyID,
+		ShareVersion: wallet.ShareVersion,
+		CreatedAt:    time.Now().UTC(),
+		KDF: KDF{
+			Name:    kdfArgon2id,
+			Salt:    hex.EncodeToString(salt),
+			Time:    argonTime,
+			Memory:  argonMemory,
+			Threads: argonThreads,
+		},
+		Cipher: cipherAESGCM,
+	}
+
+	aead, err := newGCM(deriveKey(password, salt, file.KDF))
+	if err != nil {
+		return File{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return File{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	file.Nonce = hex.EncodeToString(nonce)
+	file.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, []byte(shareData), file.additionalData()))
+
+	if file.Checksum, err = file.checksum(); err != nil {
+		return File{}, err
+	}
+	return file, nil
+}
+
+// Decrypt checks the backup and returns the client share
+func Decrypt(file File, password string) (string, error) {
+	if file.Version != Version && file.Version != versionWithoutShareVersion {
+		return "", fmt.Errorf("%w: %d", ErrUnsupportedVersion, file.Version)
+	}
+	checksum, err := file.checksum()
+	if err != nil {
+		return "", err
+	}
+	if checksum != file.Checksum {
+		return "", ErrChecksumMismatch
+	}
+
+	if file.KDF.Name != kdfArgon2id || file.Cipher != cipherAESGCM {
+		return "", fmt.Errorf("%w: unsupported kdf %q or cipher %q", ErrInvalidFormat, file.KDF.Name, file.Cipher)
+	}
+	if file.KDF.Time == 0 || file.KDF.Time > maxArgonTime ||
+		file.KDF.Memory == 0 || file.KDF.Memory > maxArgonMemory || file.KDF.Threads == 0 {
+		return "", fmt.Errorf("%w: kdf parameters out of range", ErrInvalidFormat)
+	}
+
+	salt, err := hex.DecodeString(file.KDF.Salt)
+	if err != nil || len(salt) < saltSize {
+		return "", fmt.Errorf("%w: invalid salt", ErrInvalidFormat)
+	}
+	nonce, err := hex.DecodeString(file.Nonce)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid nonce", ErrInvalidFormat)
+	}
+	ciphertext, err := hex.DecodeString(file.Ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid ciphertext", ErrInvalidFormat)
+	}
+
+	aead, err := newGCM(deriveKey(password, salt, file.KDF))
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return "", fmt.Errorf("%w: invalid nonce", ErrInvalidFormat)
+	}
+	shareData, err := aead.Open(nil, nonce, ciphertext, file.additionalData())
+	if err != nil {
+		return "", ErrWrongPassword
+	}
+	return string(shareData), nil
+}
+
+// ShareVersionOf returns the share version a backup was made for. Version 1
+// backups predate share versions and count as the first one.
+func ShareVersionOf(file File) int {
+	if file.Version == versionWithoutShareVersion {
+		return 1
+	}
+	return file.ShareVersion
+}
+
+// additionalData binds the ciphertext to the wallet, its share version and
+// the format version
+func (f File) additionalData() []byte {
+	if f.Version == versionWithoutShareVersion {
+		return []byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s", f.Version, f.WalletID, f.Address, f.KeyID))
+	}
+	return []byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s\x00%d", f.Version, f.WalletID, f.Address, f.KeyID, f.ShareVersion))
+}
+
+// checksum is the hex SHA-256 of the JSON encoding of the file without its checksum
+func (f File) checksum() (string, error) {
+	f.Checksum = ""
+	data, err := json.Marshal(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode backup: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func deriveKey(password string, salt []byte, kdf KDF) []byte {
+	return argon2.IDKey([]byte(password), salt, kdf.Time, kdf.Memory, kdf.Threads, keySize)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,85 @@
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const version = 1
+
+// Envelope encrypts data with a fresh data encryption key (DEK) per call and
+// stores the DEK wrapped by the KEK provider next to the ciphertext
+type Envelope struct {
+	kek KEKProvider
+}
+
+// sealed is the serialized form stored in the database
+type sealed struct {
+	Version    int    `json:"v"`
+	Provider   string `json:"provider"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func New(kek KEKProvider) *Envelope {
+	return &Envelope{kek: kek}
+}
+
+// Seal encrypts plaintext. additionalData is authenticated but not stored, so
+// the same value must be passed to Open; use it to bind the ciphertext to its row.
+func (e *Envelope) Seal(ctx context.Context, plaintext, additionalData []byte) ([]byte, error) {
+	dek := make([]byte, kekSize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	aead, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(aead, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := e.kek.Wrap(ctx, dek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return json.Marshal(sealed{
+		Version:    version,
+		Provider:   e.kek.Name(),
+		WrappedKey: wrappedKey,
+		Ciphertext: ciphertext,
+	})
+}
+
+// Open decrypts data produced by Seal
+func (e *Envelope) Open(ctx context.Context, data, additionalData []byte) ([]byte, error) {
+	var s sealed
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	if s.Version != version {
+		return nil, fmt.Errorf("unsupported envelope version %d", s.Version)
+	}
+
+	dek, err := e.kek.Unwrap(ctx, s.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	aead, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(aead, s.Ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt envelope: %w", err)
+	}
+	return plaintext, nil
+}
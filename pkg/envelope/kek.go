@@ -0,0 +1,123 @@
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mpc/internal/config"
+	"os"
+	"strings"
+)
+
+const (
+	ProviderEnv  = "env"
+	ProviderFile = "file"
+
+	kekSize = 32
+)
+
+// KEKProvider wraps and unwraps data encryption keys. Implementations backed by
+// a KMS only need to satisfy this interface.
+type KEKProvider interface {
+	// Name identifies the provider and is stored next to every wrapped key
+	Name() string
+	Wrap(ctx context.Context, dek []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// NewKEKProvider builds the provider selected in the custody config
+func NewKEKProvider(custodyConfig *config.CustodyConfig) (KEKProvider, error) {
+	switch custodyConfig.KEKProvider {
+	case ProviderEnv:
+		key, err := decodeKey(custodyConfig.KEK)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CUSTODY_KEK: %w", err)
+		}
+		return NewAESKEK(ProviderEnv, key)
+	case ProviderFile:
+		data, err := os.ReadFile(custodyConfig.KEKFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read KEK file: %w", err)
+		}
+		key, err := decodeKey(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid KEK file: %w", err)
+		}
+		return NewAESKEK(ProviderFile, key)
+	default:
+		return nil, fmt.Errorf("unknown KEK provider %q", custodyConfig.KEKProvider)
+	}
+}
+
+// AESKEK wraps keys with a local AES-256-GCM key
+type AESKEK struct {
+	name string
+	aead cipher.AEAD
+}
+
+// NewAESKEK creates a provider from a raw 32 byte key
+func NewAESKEK(name string, key []byte) (*AESKEK, error) {
+	if len(key) != kekSize {
+		return nil, fmt.Errorf("KEK must be %d bytes, got %d", kekSize, len(key))
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &AESKEK{name: name, aead: aead}, nil
+}
+
+func (k *AESKEK) Name() string {
+	return k.name
+}
+
+// Wrap encrypts the DEK, the nonce is prepended to the output
+func (k *AESKEK) Wrap(_ context.Context, dek []byte) ([]byte, error) {
+	return seal(k.aead, dek, nil)
+}
+
+// Unwrap decrypts a DEK produced by Wrap
+func (k *AESKEK) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	return open(k.aead, wrapped, nil)
+}
+
+// decodeKey accepts a hex or base64 encoded key
+func decodeKey(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, errors.New("key is empty")
+	}
+	if key, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x")); err == nil {
+		return key, nil
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, data := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, data, additionalData)
+}
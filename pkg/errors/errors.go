@@ -33,6 +33,48 @@ var (
 	ErrWalletNotFound = NewAppError("WALLET_NOT_FOUND", "wallet not found", 404)
 )
 
+// Wallet Errors
+var (
+	ErrReshareFailed       = NewAppError("RESHARE_FAILED", "key share refresh failed", 500)
+	ErrShareDataRequired   = NewAppError("SHARE_DATA_REQUIRED", "share data is required for this wallet", 400)
+	ErrCustodyUnavailable  = NewAppError("CUSTODY_UNAVAILABLE", "custody is not configured", 503)
+	ErrShareDecryptFailure = NewAppError("SHARE_DECRYPT_FAILED", "failed to decrypt stored share", 500)
+)
+
+// Backup Errors
+var (
+	ErrInvalidBackup         = NewAppError("BACKUP_INVALID", "backup is damaged or in an unsupported format", 400)
+	ErrBackupWrongPassword   = NewAppError("BACKUP_WRONG_PASSWORD", "wrong backup password", 400)
+	ErrBackupWalletMismatch  = NewAppError("BACKUP_WALLET_MISMATCH", "backup belongs to another wallet", 400)
+	ErrBackupStale           = NewAppError("BACKUP_STALE", "backup was made before the last share refresh, export a new one", 409)
+	ErrBackupCustodialWallet = NewAppError("BACKUP_CUSTODIAL_WALLET", "the server keeps the share of custodial wallets", 400)
+	ErrTooManyAttempts       = NewAppError("TOO_MANY_ATTEMPTS", "too many attempts, try again later", 429)
+)
+
+// Signature Errors
+var (
+	ErrInvalidMessage          = NewAppError("INVALID_MESSAGE", "invalid message", 400)
+	ErrInvalidTypedData        = NewAppError("INVALID_TYPED_DATA", "invalid typed data", 400)
+	ErrInvalidTypedDataDomain  = NewAppError("INVALID_TYPED_DATA_DOMAIN", "typed data domain is invalid or its chain is not supported", 400)
+	ErrSignatureRecoveryFailed = NewAppError("SIGNATURE_RECOVERY_FAILED", "signature does not recover to the wallet address", 500)
+)
+
+// TSS Errors
+var (
+	ErrTSSSessionNotFound  = NewAppError("TSS_SESSION_NOT_FOUND", "tss session not found", 404)
+	ErrInvalidTSSSessionID = NewAppError("INVALID_TSS_SESSION_ID", "invalid tss session id", 400)
+	ErrTSSPartyUnavailable = NewAppError("TSS_PARTY_UNAVAILABLE", "not enough signing parties are available, try again later", 503)
+	ErrTSSNoQuorum         = NewAppError("TSS_NO_QUORUM", "not enough signing parties are online, try again later", 503)
+	ErrTSSTimeout          = NewAppError("TSS_TIMEOUT", "signing parties did not finish in time, try again later", 504)
+	ErrTSSAborted          = NewAppError("TSS_ABORTED", "signing protocol was aborted, try again later", 503)
+	ErrTSSInvalidShare     = NewAppError("TSS_INVALID_SHARE", "key share is invalid", 400)
+	ErrTSSPartyMisbehaved  = NewAppError("TSS_PARTY_MISBEHAVED", "a signing party misbehaved", 502)
+	ErrTSSInvalidTopology  = NewAppError("TSS_INVALID_TOPOLOGY", "invalid parties or threshold", 400)
+	ErrTSSFailed           = NewAppError("TSS_FAILED", "signing parties reported a failure", 502)
+	ErrTSSMalformedResult  = NewAppError("TSS_MALFORMED_RESULT", "signing parties returned a malformed result", 502)
+	ErrTSSKeyVerification  = NewAppError("TSS_KEY_VERIFICATION_FAILED", "generated key did not pass verification", 502)
+)
+
 // Asset Errors
 var (
 	ErrChainNotFound        = NewAppError("CHAIN_NOT_FOUND", "chain not found", 404)
@@ -40,15 +82,38 @@ var (
 	ErrInvalidChainID       = NewAppError("INVALID_CHAIN_ID", "invalid chain id", 400)
 	ErrInvalidSymbol        = NewAppError("INVALID_SYMBOL", "invalid symbol", 400)
 	ErrUnsupportedTokenType = NewAppError("UNSUPPORTED_TOKEN_TYPE", "unsupported token type", 400)
+	ErrChainNotSupported    = NewAppError("CHAIN_NOT_SUPPORTED", "chain is not supported or not active", 400)
 )
 
 // Transaction Errors
 var (
-	ErrTransactionNotFound = NewAppError("TRANSACTION_NOT_FOUND", "transaction not found", 404)
-	ErrTransactionFailed   = NewAppError("TRANSACTION_FAILED", "transaction failed", 400)
-	ErrNotImplemented      = NewAppError("NOT_IMPLEMENTED", "not implemented", 501)
-	ErrInvalidWallet       = NewAppError("INVALID_WALLET", "invalid wallet", 400)
-	ErrInvalidAmount       = NewAppError("INVALID_AMOUNT", "invalid amount", 400)
-	ErrInvalidAddress      = NewAppError("INVALID_ADDRESS", "invalid address", 400)
-	ErrInssuficientBalance = NewAppError("INSUFFICIENT_BALANCE", "insufficient balance", 400)
+	ErrTransactionNotFound       = NewAppError("TRANSACTION_NOT_FOUND", "transaction not found", 404)
+	ErrTransactionFailed         = NewAppError("TRANSACTION_FAILED", "transaction failed", 400)
+	ErrNotImplemented            = NewAppError("NOT_IMPLEMENTED", "not implemented", 501)
+	ErrInvalidWallet             = NewAppError("INVALID_WALLET", "invalid wallet", 400)
+	ErrInvalidAmount             = NewAppError("INVALID_AMOUNT", "invalid amount", 400)
+	ErrInvalidAddress            = NewAppError("INVALID_ADDRESS", "invalid address", 400)
+	ErrInssuficientBalance       = NewAppError("INSUFFICIENT_BALANCE", "insufficient balance", 400)
+	ErrGasEstimationFailed       = NewAppError("GAS_ESTIMATION_FAILED", "transaction would fail, gas could not be estimated", 400)
+	ErrNonceLocked               = NewAppError("NONCE_LOCKED", "another transaction from this wallet is being prepared, try again", 409)
+	ErrDynamicFeeUnsupported     = NewAppError("DYNAMIC_FEE_UNSUPPORTED", "chain does not support eip1559 transactions, use a legacy transaction", 400)
+	ErrInvalidTransactionID      = NewAppError("INVALID_TRANSACTION_ID", "invalid transaction id", 400)
+	ErrTransactionNotReplaceable = NewAppError("TRANSACTION_NOT_REPLACEABLE", "transaction is no longer pending and cannot be replaced", 409)
+	ErrReplacementUnderpriced    = NewAppError("REPLACEMENT_UNDERPRICED", "replacement fees are too low for the node, try again", 409)
+	ErrReplacementInProgress     = NewAppError("REPLACEMENT_IN_PROGRESS", "a speed-up or cancel of this transaction is already in progress", 409)
+	ErrTransactionSendUncertain  = NewAppError("TRANSACTION_SEND_UNCERTAIN", "the node did not answer, the transaction may have been broadcast", 502)
+)
+
+// Transaction Job Errors
+var (
+	ErrTransactionJobNotFound  = NewAppError("TRANSACTION_JOB_NOT_FOUND", "transaction job not found", 404)
+	ErrInvalidTransactionJobID = NewAppError("INVALID_TRANSACTION_JOB_ID", "invalid transaction job id", 400)
+	ErrTransactionQueueFull    = NewAppError("TRANSACTION_QUEUE_FULL", "too many pending transactions, try again later", 503)
+)
+
+// Idempotency Errors
+var (
+	ErrInvalidIdempotencyKey    = NewAppError("INVALID_IDEMPOTENCY_KEY", "idempotency key must be 1 to 255 characters", 400)
+	ErrIdempotencyKeyMismatch   = NewAppError("IDEMPOTENCY_KEY_MISMATCH", "idempotency key was already used with a different request", 422)
+	ErrIdempotencyKeyInProgress = NewAppError("IDEMPOTENCY_KEY_IN_PROGRESS", "a request with this idempotency key is in progress, try again", 409)
 )
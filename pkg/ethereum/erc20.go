@@ -0,0 +1,120 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// erc20ABI holds the ERC-20 methods used to send tokens
+const erc20ABI = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var erc20 = mustParseABI(erc20ABI)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid abi: %v", err))
+	}
+	return parsed
+}
+
+// transferData returns the calldata of an ERC-20 transfer(address,uint256)
+func transferData(to common.Address, amount *big.Int) ([]byte, error) {
+	data, err := erc20.Pack("transfer", to, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode token transfer: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeTransfer returns the recipient and amount of ERC-20 transfer
+// calldata. It reports false for any other call.
+func DecodeTransfer(data []byte) (common.Address, *big.Int, bool) {
+	method := erc20.Methods["transfer"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return common.Address{}, nil, false
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil || len(values) != 2 {
+		return common.Address{}, nil, false
+	}
+	to, ok := values[0].(common.Address)
+	if !ok {
+		return common.Address{}, nil, false
+	}
+	amount, ok := values[1].(*big.Int)
+	if !ok {
+		return common.Address{}, nil, false
+	}
+	return to, amount, true
+}
+
+// TokenBalance returns the ERC-20 balance of owner in base units
+func (c *EthClient) TokenBalance(ctx context.Context, token, owner string) (*big.Int, error) {
+	if !common.IsHexAddress(token) {
+		return nil, fmt.Errorf("invalid token address")
+	}
+	data, err := erc20.Pack("balanceOf", common.HexToAddress(owner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode balance call: %w", err)
+	}
+
+	tokenAddress := common.HexToAddress(token)
+	output, err := c.client.CallContract(ctx, geth.CallMsg{To: &tokenAddress, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch token balance: %w", err)
+	}
+	values, err := erc20.Unpack("balanceOf", output)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("invalid token balance response: %v", err)
+	}
+	balance, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("invalid token balance response")
+	}
+	return balance, nil
+}
+
+// IsEnoughTokenBalance reports whether the address holds enough of every
+// token sent by the transfers. Native transfers are ignored, they are covered
+// by IsEnoughBalance.
+func (c *EthClient) IsEnoughTokenBalance(ctx context.Context, address string, transfers []Transfer) (bool, error) {
+	totals := make(map[common.Address]*big.Int)
+	var tokens []common.Address
+	for _, transfer := range transfers {
+		if transfer.Token == "" {
+			continue
+		}
+		amount, err := ToBaseUnits(transfer.Amount, transfer.Decimals)
+		if err != nil {
+			return false, fmt.Errorf("invalid amount: %w", err)
+		}
+		token := common.HexToAddress(transfer.Token)
+		if totals[token] == nil {
+			totals[token] = new(big.Int)
+			tokens = append(tokens, token)
+		}
+		totals[token].Add(totals[token], amount)
+	}
+
+	for _, token := range tokens {
+		balance, err := c.TokenBalance(ctx, token.Hex(), address)
+		if err != nil {
+			return false, err
+		}
+		if balance.Cmp(totals[token]) < 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -2,99 +2,275 @@ package ethereum
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"mpc/pkg/logger"
+	"strings"
+	"sync"
 
+	geth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
+// NativeDecimals is the number of decimals of the native currency
+const NativeDecimals = 18
+
+var (
+	// ErrInsufficientFunds is returned when the sender cannot cover the value of a transfer
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrGasEstimation is returned when the node cannot estimate the gas of a
+	// transaction, usually because it would fail
+	ErrGasEstimation = errors.New("failed to estimate gas")
+	// ErrNonceTooLow is returned when the nonce of a transaction was already used
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrReplacementUnderpriced is returned when a transaction replacing a
+	// pending one does not raise the fees enough for the node
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrSendUncertain is returned when sending a transaction failed without
+	// an answer from the node, e.g. on a timeout or a reset connection, so
+	// the node may still have received it
+	ErrSendUncertain = errors.New("transaction may have been sent")
+)
+
 type EthClient struct {
 	rpcURL string
 	client *ethclient.Client
+	// gasLimitMargin is the percentage added to estimated gas limits
+	gasLimitMargin uint64
+
+	mu      sync.Mutex
+	chainID *big.Int
 }
 
-// NewEthClient initializes a new Ethereum client
-func NewEthClient(rpcURL string) (*EthClient, error) {
+// NewEthClient initializes a new Ethereum client. Estimated gas limits are
+// raised by gasLimitMargin percent.
+func NewEthClient(rpcURL string, gasLimitMargin uint64) (*EthClient, error) {
 	client, err := ethclient.Dial(rpcURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
 	}
 
 	return &EthClient{
-		rpcURL: rpcURL,
-		client: client,
+		rpcURL:         rpcURL,
+		client:         client,
+		gasLimitMargin: gasLimitMargin,
 	}, nil
 }
 
-// CreateTransaction sends a transaction from a wallet to another address
-func (c *EthClient) CreateTransaction(ctx context.Context, fromAddressHex string, to string, amount string) (*types.Transaction, error) {
-	// Validate recipient address and amount
-	toAddress, amountWei, err := c.validateTransactionInputs(to, amount)
+// Transfer is a payment from a wallet, in the native currency or in an ERC-20 token
+type Transfer struct {
+	To string
+	// Amount is in whole units of the currency, converted with Decimals
+	Amount   string
+	Decimals int32
+	// Token is the ERC-20 contract address, empty for the native currency
+	Token string
+}
+
+// CreateTransaction builds an unsigned transaction for a transfer
+func (c *EthClient) CreateTransaction(ctx context.Context, fromAddressHex string, nonce uint64, transfer Transfer, opts FeeOptions) (*types.Transaction, error) {
+	txs, err := c.CreateTransactions(ctx, fromAddressHex, []uint64{nonce}, []Transfer{transfer}, opts)
 	if err != nil {
 		return nil, err
 	}
+	return txs[0], nil
+}
 
+// CreateTransactions builds one transaction per transfer with the nonce at
+// the same index, so they can be signed together and mined in order. They
+// share the same fees.
+func (c *EthClient) CreateTransactions(ctx context.Context, fromAddressHex string, nonces []uint64, transfers []Transfer, opts FeeOptions) ([]*types.Transaction, error) {
+	if len(nonces) != len(transfers) {
+		return nil, fmt.Errorf("got %d nonces for %d transfers", len(nonces), len(transfers))
+	}
 	fromAddress := common.HexToAddress(fromAddressHex)
 
-	// Fetch nonce, gas price, and chain ID
-	nonce, err := c.fetchNonce(ctx, fromAddress)
+	// Fetch fees and chain ID
+	fees, err := c.SuggestFees(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
-	gasPrice, err := c.fetchGasPrice(ctx)
+	chainID, err := c.ChainID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	tx := types.NewTransaction(nonce, toAddress, amountWei, 210000, gasPrice, nil)
 
-	return tx, nil
+	txs := make([]*types.Transaction, len(transfers))
+	for i, transfer := range transfers {
+		to, value, data, err := c.transferCall(transfer)
+		if err != nil {
+			return nil, err
+		}
+		gas, err := c.estimateGas(ctx, fromAddress, to, value, data)
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = newTransaction(chainID, nonces[i], to, value, gas, fees, data)
+	}
+	return txs, nil
+}
+
+// transferCall returns the recipient, value and calldata of the transaction
+// making a transfer. A token transfer calls the token contract with no value.
+func (c *EthClient) transferCall(transfer Transfer) (common.Address, *big.Int, []byte, error) {
+	toAddress, amount, err := c.validateTransactionInputs(transfer.To, transfer.Amount, transfer.Decimals)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if transfer.Token == "" {
+		return toAddress, amount, nil, nil
+	}
+
+	if !common.IsHexAddress(transfer.Token) {
+		return common.Address{}, nil, nil, fmt.Errorf("invalid token address")
+	}
+	data, err := transferData(toAddress, amount)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return common.HexToAddress(transfer.Token), new(big.Int), data, nil
+}
+
+// Close closes the connection to the node
+func (c *EthClient) Close() {
+	c.client.Close()
+}
+
+// BlockByNumber returns a block, or the latest block when number is nil
+func (c *EthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	block, err := c.client.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch block: %w", err)
+	}
+	return block, nil
+}
+
+// BlockNumber returns the number of the latest block
+func (c *EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	number, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch block number: %w", err)
+	}
+	return number, nil
+}
+
+// TransactionReceipt returns the receipt of a mined transaction, or nil when
+// the transaction is not mined
+func (c *EthClient) TransactionReceipt(ctx context.Context, txHash string) (*types.Receipt, error) {
+	receipt, err := c.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		if errors.Is(err, geth.NotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// IsKnownTransaction reports whether the node has the transaction, mined or
+// in its pending pool
+func (c *EthClient) IsKnownTransaction(ctx context.Context, txHash string) (bool, error) {
+	_, _, err := c.client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		if errors.Is(err, geth.NotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch transaction: %w", err)
+	}
+	return true, nil
 }
 
+// TransactionSender returns the sender of the transaction at index of a block
+func (c *EthClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	sender, err := c.client.TransactionSender(ctx, tx, block, index)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to fetch sender: %w", err)
+	}
+	return sender, nil
+}
+
+// SendTransaction broadcasts a signed transaction and returns its hash. Errors
+// the node answered with mean it rejected the transaction; any other error
+// wraps ErrSendUncertain.
 func (c *EthClient) SendTransaction(ctx context.Context, signedTx *types.Transaction) (string, error) {
 	err := c.client.SendTransaction(ctx, signedTx)
 	if err != nil {
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			return "", fmt.Errorf("%w: %v", ErrSendUncertain, err)
+		}
+		if strings.Contains(err.Error(), "nonce too low") {
+			return "", fmt.Errorf("%w: %v", ErrNonceTooLow, err)
+		}
+		if strings.Contains(err.Error(), "replacement transaction underpriced") {
+			return "", fmt.Errorf("%w: %v", ErrReplacementUnderpriced, err)
+		}
+		if strings.Contains(err.Error(), "insufficient funds") {
+			return "", fmt.Errorf("%w: %v", ErrInsufficientFunds, err)
+		}
 		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 	return signedTx.Hash().Hex(), nil
 }
 
-func (c *EthClient) IsEnoughBalance(ctx context.Context, address string, amount string) (bool, error) {
-	// Validate recipient address and amount
-	_, amountWei, err := c.validateTransactionInputs(address, amount)
+// IsEnoughBalance reports whether the address can pay for txs, their values
+// and their gas limits at the max fee
+func (c *EthClient) IsEnoughBalance(ctx context.Context, address string, txs []*types.Transaction) (bool, error) {
+	cost := new(big.Int)
+	for _, tx := range txs {
+		cost.Add(cost, tx.Cost())
+	}
+
+	balance, err := c.Balance(ctx, address)
 	if err != nil {
 		return false, err
 	}
 
+	return balance.Cmp(cost) >= 0, nil
+}
+
+// Balance returns the native balance of the address in Wei
+func (c *EthClient) Balance(ctx context.Context, address string) (*big.Int, error) {
 	balance, err := c.client.BalanceAt(ctx, common.HexToAddress(address), nil)
 	if err != nil {
-		return false, fmt.Errorf("failed to fetch balance: %w", err)
+		return nil, fmt.Errorf("failed to fetch balance: %w", err)
 	}
+	return balance, nil
+}
 
-	return balance.Cmp(amountWei) >= 0, nil
+// IsContract reports whether there is contract code at the address
+func (c *EthClient) IsContract(ctx context.Context, address string) (bool, error) {
+	code, err := c.client.CodeAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch code: %w", err)
+	}
+	return len(code) > 0, nil
 }
 
-// validateTransactionInputs checks the recipient address and converts the amount to Wei
-func (c *EthClient) validateTransactionInputs(address string, amount string) (common.Address, *big.Int, error) {
+// validateTransactionInputs checks the recipient address and converts the amount to base units
+func (c *EthClient) validateTransactionInputs(address string, amount string, decimals int32) (common.Address, *big.Int, error) {
 	if !common.IsHexAddress(address) {
 		return common.Address{}, nil, fmt.Errorf("invalid recipient address")
 	}
 	addr := common.HexToAddress(address)
 
-	amountWei, err := c.convertToWei(amount)
+	baseAmount, err := ToBaseUnits(amount, decimals)
 	if err != nil {
 		return common.Address{}, nil, fmt.Errorf("invalid amount: %w", err)
 	}
-	return addr, amountWei, nil
+	return addr, baseAmount, nil
 }
 
-// fetchNonce retrieves the nonce without retry
-func (c *EthClient) fetchNonce(ctx context.Context, fromAddress common.Address) (uint64, error) {
-	nonce, err := c.client.PendingNonceAt(ctx, fromAddress)
+// PendingNonce returns the next nonce of the address, counting the
+// transactions in the node's pending pool
+func (c *EthClient) PendingNonce(ctx context.Context, address string) (uint64, error) {
+	nonce, err := c.client.PendingNonceAt(ctx, common.HexToAddress(address))
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch nonce: %w", err)
 	}
@@ -102,6 +278,36 @@ func (c *EthClient) fetchNonce(ctx context.Context, fromAddress common.Address)
 	return nonce, nil
 }
 
+// ConfirmedNonce returns the next nonce of the address in the latest block
+func (c *EthClient) ConfirmedNonce(ctx context.Context, address string) (uint64, error) {
+	nonce, err := c.client.NonceAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch confirmed nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// estimateGas returns the estimated gas limit of a call plus the margin
+func (c *EthClient) estimateGas(ctx context.Context, from, to common.Address, value *big.Int, data []byte) (uint64, error) {
+	gas, err := c.client.EstimateGas(ctx, geth.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		// Nodes check the value against the balance while estimating
+		if strings.Contains(err.Error(), "insufficient funds") {
+			return 0, fmt.Errorf("%w: %v", ErrInsufficientFunds, err)
+		}
+		return 0, fmt.Errorf("%w: %v", ErrGasEstimation, err)
+	}
+
+	limit := gas + gas*c.gasLimitMargin/100
+	logger.Info("estimated gas", zap.Uint64("gas", gas), zap.Uint64("gas_limit", limit))
+	return limit, nil
+}
+
 // fetchGasPrice retrieves the gas price without retry
 func (c *EthClient) fetchGasPrice(ctx context.Context) (*big.Int, error) {
 	gasPrice, err := c.client.SuggestGasPrice(ctx)
@@ -112,28 +318,30 @@ func (c *EthClient) fetchGasPrice(ctx context.Context) (*big.Int, error) {
 	return gasPrice, nil
 }
 
-// Convert amount to Wei
-func (c *EthClient) convertToWei(amount string) (*big.Int, error) {
+// ToBaseUnits converts an amount in whole units to base units, e.g. ETH to Wei
+func ToBaseUnits(amount string, decimals int32) (*big.Int, error) {
 	// Parse the decimal amount
 	decimalAmount, err := decimal.NewFromString(amount)
 	if err != nil {
 		return nil, fmt.Errorf("invalid decimal amount: %w", err)
 	}
 
-	// Multiply by 10^18 for Wei conversion
-	weiAmount := decimalAmount.Mul(decimal.NewFromBigInt(big.NewInt(1), 18))
-
-	// Convert to big.Int
-	wei := new(big.Int)
-	wei, ok := wei.SetString(weiAmount.String(), 10)
-	if !ok {
-		return nil, fmt.Errorf("failed to convert to wei")
+	// Multiply by 10^decimals, the result must be a whole number of base units
+	baseAmount := decimalAmount.Shift(decimals)
+	if !baseAmount.IsInteger() {
+		return nil, fmt.Errorf("amount has more than %d decimals", decimals)
 	}
+	base := baseAmount.BigInt()
 
 	logger.Info("amount conversion",
 		zap.String("original_amount", amount),
-		zap.String("decimal_amount", decimalAmount.String()),
-		zap.String("wei_amount", wei.String()))
+		zap.Int32("decimals", decimals),
+		zap.String("base_amount", base.String()))
+
+	return base, nil
+}
 
-	return wei, nil
+// FromBaseUnits converts an amount in base units to whole units, e.g. Wei to ETH
+func FromBaseUnits(amount *big.Int, decimals int32) string {
+	return decimal.NewFromBigInt(amount, -decimals).String()
 }
@@ -0,0 +1,189 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"mpc/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+// Transaction types a client can choose
+const (
+	TxTypeLegacy     = "legacy"
+	TxTypeDynamicFee = "eip1559"
+)
+
+// Speed presets, from cheapest to fastest to be mined
+const (
+	SpeedLow    = "low"
+	SpeedMedium = "medium"
+	SpeedHigh   = "high"
+)
+
+// feeHistoryBlocks is the number of recent blocks the priority fee is sampled from
+const feeHistoryBlocks = 20
+
+// speedPercentiles are the priority fee percentiles, of the transactions in
+// the sampled blocks, paid by each speed
+var speedPercentiles = map[string]float64{
+	SpeedLow:    10,
+	SpeedMedium: 50,
+	SpeedHigh:   90,
+}
+
+// ErrDynamicFeeUnsupported is returned for an eip1559 transaction on a chain
+// without a base fee
+var ErrDynamicFeeUnsupported = errors.New("chain does not support eip1559 transactions")
+
+// FeeOptions selects the transaction type and how fast it should be mined.
+// Empty fields default to an eip1559 transaction at medium speed.
+type FeeOptions struct {
+	Type  string
+	Speed string
+}
+
+// Fees are the fee caps of a transaction. GasPrice is set for legacy
+// transactions, GasTipCap and GasFeeCap for eip1559 transactions.
+type Fees struct {
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// WithDefaults fills the empty fields with their defaults
+func (o FeeOptions) WithDefaults() FeeOptions {
+	if o.Type == "" {
+		o.Type = TxTypeDynamicFee
+	}
+	if o.Speed == "" {
+		o.Speed = SpeedMedium
+	}
+	return o
+}
+
+// SuggestFees prices a transaction from the fee history of the recent blocks.
+// The priority fee is the preset percentile averaged over the blocks. The max
+// fee leaves room for the base fee to double, so the transaction stays valid
+// for several full blocks; only the base fee of the mined block is paid.
+func (c *EthClient) SuggestFees(ctx context.Context, opts FeeOptions) (Fees, error) {
+	opts = opts.WithDefaults()
+	if opts.Type != TxTypeLegacy && opts.Type != TxTypeDynamicFee {
+		return Fees{}, fmt.Errorf("unknown transaction type %q", opts.Type)
+	}
+	percentile, ok := speedPercentiles[opts.Speed]
+	if !ok {
+		return Fees{}, fmt.Errorf("unknown speed %q", opts.Speed)
+	}
+
+	history, err := c.client.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{percentile})
+	if err != nil {
+		return Fees{}, fmt.Errorf("failed to fetch fee history: %w", err)
+	}
+
+	// The last base fee is the one of the next block
+	var baseFee *big.Int
+	if n := len(history.BaseFee); n > 0 {
+		baseFee = history.BaseFee[n-1]
+	}
+	if baseFee == nil || baseFee.Sign() == 0 {
+		if opts.Type == TxTypeDynamicFee {
+			return Fees{}, ErrDynamicFeeUnsupported
+		}
+		gasPrice, err := c.fetchGasPrice(ctx)
+		if err != nil {
+			return Fees{}, err
+		}
+		return Fees{GasPrice: gasPrice}, nil
+	}
+
+	tip, err := c.averageTip(ctx, history.Reward)
+	if err != nil {
+		return Fees{}, err
+	}
+
+	var fees Fees
+	if opts.Type == TxTypeLegacy {
+		// A legacy transaction pays its whole gas price, so it only covers one
+		// base fee increase of 12.5%
+		headroom := new(big.Int).Div(baseFee, big.NewInt(8))
+		fees.GasPrice = new(big.Int).Add(new(big.Int).Add(baseFee, headroom), tip)
+	} else {
+		fees.GasTipCap = tip
+		fees.GasFeeCap = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+	}
+
+	logger.Info("suggested fees",
+		zap.String("type", opts.Type),
+		zap.String("speed", opts.Speed),
+		zap.String("base_fee", baseFee.String()),
+		zap.String("tip", tip.String()))
+	return fees, nil
+}
+
+// averageTip averages the sampled priority fees, skipping empty blocks. It
+// falls back to the node's suggestion when every sampled block is empty.
+func (c *EthClient) averageTip(ctx context.Context, rewards [][]*big.Int) (*big.Int, error) {
+	sum := new(big.Int)
+	count := int64(0)
+	for _, reward := range rewards {
+		if len(reward) == 0 || reward[0] == nil || reward[0].Sign() == 0 {
+			continue
+		}
+		sum.Add(sum, reward[0])
+		count++
+	}
+	if count > 0 {
+		return sum.Div(sum, big.NewInt(count)), nil
+	}
+
+	tip, err := c.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch gas tip: %w", err)
+	}
+	return tip, nil
+}
+
+// ChainID returns the chain ID of the node, fetched once
+func (c *EthClient) ChainID(ctx context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.chainID == nil {
+		chainID, err := c.client.ChainID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch chain id: %w", err)
+		}
+		c.chainID = chainID
+	}
+	return new(big.Int).Set(c.chainID), nil
+}
+
+// newTransaction builds a legacy transaction when fees has a gas price and an
+// eip1559 transaction otherwise
+func newTransaction(chainID *big.Int, nonce uint64, to common.Address, value *big.Int, gas uint64, fees Fees, data []byte) *types.Transaction {
+	if fees.GasPrice != nil {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &to,
+			Value:    value,
+			Gas:      gas,
+			GasPrice: fees.GasPrice,
+			Data:     data,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &to,
+		Value:     value,
+		Gas:       gas,
+		GasTipCap: fees.GasTipCap,
+		GasFeeCap: fees.GasFeeCap,
+		Data:      data,
+	})
+}
@@ -0,0 +1,136 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"mpc/internal/model"
+	"mpc/pkg/logger"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ErrChainNotSupported is returned for a chain that is not in the chains
+// table or is not active
+var ErrChainNotSupported = errors.New("chain not supported")
+
+// ChainLister lists the chains the registry can connect to
+type ChainLister interface {
+	GetChains(ctx context.Context) ([]model.Chain, error)
+}
+
+// Registry connects to the active chains of the chains table, one client per
+// chain, so adding a network only takes a row. Clients are created on first
+// use and kept for the life of the process.
+type Registry struct {
+	chains         ChainLister
+	gasLimitMargin uint64
+
+	mu      sync.Mutex
+	clients map[int]*EthClient
+}
+
+func NewRegistry(chains ChainLister, gasLimitMargin uint64) *Registry {
+	return &Registry{
+		chains:         chains,
+		gasLimitMargin: gasLimitMargin,
+		clients:        make(map[int]*EthClient),
+	}
+}
+
+// Client returns the client of an active chain. The chains are read and the
+// RPC dialled without holding the lock, so a slow or unreachable RPC does not
+// block the clients of other chains.
+func (r *Registry) Client(ctx context.Context, chainID int) (*EthClient, error) {
+	if client, ok := r.cached(chainID); ok {
+		return client, nil
+	}
+
+	// The chain may have been added since the clients were created
+	chains, err := r.chains.GetChains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, chain := range chains {
+		if chain.ChainID == chainID && chain.Status == model.ChainStatusActive {
+			return r.connect(ctx, chain)
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrChainNotSupported, chainID)
+}
+
+// Clients returns the clients of every active chain, by chain ID. Chains that
+// cannot be reached are logged and left out.
+func (r *Registry) Clients(ctx context.Context) (map[int]*EthClient, error) {
+	chains, err := r.chains.GetChains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[int]*EthClient)
+	for _, chain := range chains {
+		if chain.Status != model.ChainStatusActive {
+			continue
+		}
+		client, ok := r.cached(chain.ChainID)
+		if !ok {
+			if client, err = r.connect(ctx, chain); err != nil {
+				logger.Error("failed to connect to chain "+chain.Name, err)
+				continue
+			}
+		}
+		clients[chain.ChainID] = client
+	}
+	return clients, nil
+}
+
+// cached returns the client of a chain that is already connected
+func (r *Registry) cached(chainID int) (*EthClient, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client, ok := r.clients[chainID]
+	return client, ok
+}
+
+// connect dials the chain's RPC and checks it serves the chain, so a wrong URL
+// in the table cannot get transactions signed for another network. Callers
+// racing to connect the same chain all get the client installed first; the
+// others are closed.
+func (r *Registry) connect(ctx context.Context, chain model.Chain) (*EthClient, error) {
+	client, err := NewEthClient(chain.RPCURL, r.gasLimitMargin)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if chainID.Cmp(big.NewInt(int64(chain.ChainID))) != 0 {
+		client.Close()
+		return nil, fmt.Errorf("rpc of chain %d serves chain %s", chain.ChainID, chainID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if installed, ok := r.clients[chain.ChainID]; ok {
+		client.Close()
+		return installed, nil
+	}
+	logger.Info("connected to chain", zap.String("name", chain.Name), zap.Int("chain_id", chain.ChainID))
+	r.clients[chain.ChainID] = client
+	return client, nil
+}
+
+// Close closes every client
+func (r *Registry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for chainID, client := range r.clients {
+		client.Close()
+		delete(r.clients, chainID)
+	}
+}
@@ -0,0 +1,124 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"mpc/internal/model"
+)
+
+type chainList []model.Chain
+
+func (c chainList) GetChains(ctx context.Context) ([]model.Chain, error) {
+	return c, nil
+}
+
+// chainIDServer answers eth_chainId with chainID. With a gate, every request
+// is reported on arrived and answered once gate is closed.
+func chainIDServer(t *testing.T, chainID int, gate <-chan struct{}, arrived chan<- struct{}) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if gate != nil {
+			arrived <- struct{}{}
+			<-gate
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.ID, chainID)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRegistryConnectsWithoutBlocking(t *testing.T) {
+	gate, arrived := make(chan struct{}), make(chan struct{}, 3)
+	registry := NewRegistry(chainList{
+		{Name: "Fast", ChainID: 1, RPCURL: chainIDServer(t, 1, nil, nil), Status: model.ChainStatusActive},
+		{Name: "Slow", ChainID: 2, RPCURL: chainIDServer(t, 2, gate, arrived), Status: model.ChainStatusActive},
+	}, 20)
+	t.Cleanup(registry.Close)
+	// A failed test still lets the slow callers finish
+	var release sync.Once
+	t.Cleanup(func() { release.Do(func() { close(gate) }) })
+	ctx := context.Background()
+
+	fast, err := registry.Client(ctx, 1)
+	if err != nil {
+		t.Fatalf("connect fast chain: %v", err)
+	}
+
+	// Several callers wait on the slow chain at once
+	var wg sync.WaitGroup
+	slow := make([]*EthClient, 3)
+	for i := range slow {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := registry.Client(ctx, 2)
+			if err != nil {
+				t.Errorf("connect slow chain: %v", err)
+			}
+			slow[i] = client
+		}()
+	}
+
+	// The connected chain is served while the slow one is still dialling
+	<-arrived
+	done := make(chan *EthClient)
+	go func() {
+		client, _ := registry.Client(ctx, 1)
+		done <- client
+	}()
+	select {
+	case client := <-done:
+		if client != fast {
+			t.Fatal("got a new client for a connected chain")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a connected chain waited for another chain to connect")
+	}
+
+	release.Do(func() { close(gate) })
+	wg.Wait()
+	for _, client := range slow {
+		if client == nil || client != slow[0] {
+			t.Fatal("callers racing to connect a chain got different clients")
+		}
+	}
+}
+
+func TestRegistryRejectsWrongChain(t *testing.T) {
+	registry := NewRegistry(chainList{
+		{Name: "Mislabelled", ChainID: 1, RPCURL: chainIDServer(t, 5, nil, nil), Status: model.ChainStatusActive},
+		{Name: "Inactive", ChainID: 3, RPCURL: chainIDServer(t, 3, nil, nil), Status: model.ChainStatusInactive},
+	}, 20)
+	t.Cleanup(registry.Close)
+	ctx := context.Background()
+
+	if _, err := registry.Client(ctx, 1); err == nil {
+		t.Fatal("connected to an RPC serving another chain")
+	}
+	if _, err := registry.Client(ctx, 3); err == nil {
+		t.Fatal("connected to an inactive chain")
+	}
+
+	clients, err := registry.Clients(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 0 {
+		t.Fatalf("got %d clients, want none", len(clients))
+	}
+}
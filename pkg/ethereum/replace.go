@@ -0,0 +1,105 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ReplacementBump is the percentage the fees of a replacement must rise by
+// for nodes to accept it in place of a pending transaction with the same
+// nonce, the default price bump of geth's transaction pool
+const ReplacementBump = 10
+
+// ErrTransactionNotPending is returned when replacing a transaction the node
+// does not have in its pending pool, because it was mined or dropped
+var ErrTransactionNotPending = errors.New("transaction is not pending")
+
+// PendingTransaction returns a transaction of the node's pending pool
+func (c *EthClient) PendingTransaction(ctx context.Context, txHash string) (*types.Transaction, error) {
+	tx, isPending, err := c.client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		if errors.Is(err, geth.NotFound) {
+			return nil, ErrTransactionNotPending
+		}
+		return nil, fmt.Errorf("failed to fetch transaction: %w", err)
+	}
+	if !isPending {
+		return nil, ErrTransactionNotPending
+	}
+	return tx, nil
+}
+
+// SpeedUpTransaction builds a replacement of a pending transaction with the
+// same nonce, recipient, value, calldata and gas limit, priced for speed.
+func (c *EthClient) SpeedUpTransaction(ctx context.Context, tx *types.Transaction, speed string) (*types.Transaction, error) {
+	if tx.To() == nil {
+		return nil, fmt.Errorf("cannot replace a contract creation")
+	}
+	fees, err := c.replacementFees(ctx, tx, speed)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newTransaction(chainID, tx.Nonce(), *tx.To(), tx.Value(), tx.Gas(), fees, tx.Data()), nil
+}
+
+// CancelTransaction builds a replacement of a pending transaction that sends
+// nothing from the sender to itself, priced for speed. Once it is mined the
+// nonce is used and the original transaction can no longer be.
+func (c *EthClient) CancelTransaction(ctx context.Context, fromAddressHex string, tx *types.Transaction, speed string) (*types.Transaction, error) {
+	fees, err := c.replacementFees(ctx, tx, speed)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newTransaction(chainID, tx.Nonce(), common.HexToAddress(fromAddressHex), new(big.Int), params.TxGas, fees, nil), nil
+}
+
+// replacementFees returns the fees suggested for speed, raised where needed to
+// ReplacementBump percent above the fees of tx. The replacement keeps the type
+// of tx.
+func (c *EthClient) replacementFees(ctx context.Context, tx *types.Transaction, speed string) (Fees, error) {
+	opts := FeeOptions{Type: TxTypeDynamicFee, Speed: speed}
+	if tx.Type() == types.LegacyTxType {
+		opts.Type = TxTypeLegacy
+	}
+	fees, err := c.SuggestFees(ctx, opts)
+	if err != nil {
+		return Fees{}, err
+	}
+
+	if fees.GasPrice != nil {
+		fees.GasPrice = maxBigInt(fees.GasPrice, bumpFee(tx.GasPrice()))
+		return fees, nil
+	}
+	fees.GasTipCap = maxBigInt(fees.GasTipCap, bumpFee(tx.GasTipCap()))
+	fees.GasFeeCap = maxBigInt(fees.GasFeeCap, bumpFee(tx.GasFeeCap()))
+	return fees, nil
+}
+
+// bumpFee raises fee by ReplacementBump percent, rounding up
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+ReplacementBump))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBigInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,84 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	rd "mpc/internal/db/redis"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "lock:"
+	// retryInterval is how often a held lock is tried again while waiting
+	retryInterval = 50 * time.Millisecond
+)
+
+// ErrNotAcquired is returned when a lock is still held by another owner
+// after waiting for it
+var ErrNotAcquired = errors.New("lock: not acquired")
+
+// releaseScript deletes the lock only if it is still held by the owner, so a
+// lock that expired and was taken by someone else is left alone
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Locker hands out mutual exclusion locks stored in Redis, so they hold
+// across API instances
+type Locker struct {
+	redis *rd.Client
+}
+
+func NewLocker(redis *rd.Client) *Locker {
+	return &Locker{redis: redis}
+}
+
+// Acquire takes the lock on key, waiting up to wait for it to be free. The
+// lock expires after ttl in case its owner dies; release frees it earlier.
+func (l *Locker) Acquire(ctx context.Context, key string, ttl, wait time.Duration) (release func(context.Context), err error) {
+	key = keyPrefix + key
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		acquired, err := l.redis.SetNX(ctx, key, owner, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrNotAcquired, key)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+
+	return func(ctx context.Context) {
+		// An expired lock is released by Redis, nothing is left to do
+		_ = releaseScript.Run(ctx, l.redis, []string{key}, owner).Err()
+	}, nil
+}
+
+func newOwner() (string, error) {
+	owner := make([]byte, 16)
+	if _, err := rand.Read(owner); err != nil {
+		return "", fmt.Errorf("failed to generate lock owner: %w", err)
+	}
+	return hex.EncodeToString(owner), nil
+}
@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"mpc/internal/db/redis"
+	"time"
+)
+
+const keyPrefix = "ratelimit:"
+
+// Limiter counts attempts per key in fixed windows stored in Redis, so the
+// limit holds across API instances
+type Limiter struct {
+	redis *redis.Client
+}
+
+func NewLimiter(redis *redis.Client) *Limiter {
+	return &Limiter{redis: redis}
+}
+
+// Allow records an attempt for key and reports whether it is within limit
+// attempts per window
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	key = keyPrefix + key
+
+	pipe := l.redis.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	// Only the first attempt of a window sets the expiry
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to record attempt: %w", err)
+	}
+	return count.Val() <= int64(limit), nil
+}
@@ -0,0 +1,106 @@
+package tss
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Stream entry fields authenticating a result
+const (
+	nodeField = "node"
+	macField  = "mac"
+)
+
+// minResultKeyLength is the shortest accepted HMAC key, in bytes
+const minResultKeyLength = 32
+
+// errUnauthenticatedResult is returned for result entries that do not carry a
+// valid MAC from a known node
+var errUnauthenticatedResult = errors.New("result is not authenticated")
+
+// ResultSigner authenticates the results a node publishes with an HMAC over
+// the stream key and the payload, so a result cannot be forged, or replayed
+// into another session, by a process that only has Redis access
+type ResultSigner struct {
+	nodeID string
+	key    []byte
+}
+
+// NewResultSigner creates a signer for a node from its hex encoded key
+func NewResultSigner(nodeID string, hexKey string) (*ResultSigner, error) {
+	key, err := decodeResultKey(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result key for node %s: %w", nodeID, err)
+	}
+	return &ResultSigner{nodeID: nodeID, key: key}, nil
+}
+
+// fields returns the stream entry fields identifying the node and the MAC
+func (s *ResultSigner) fields(stream string, payload []byte) map[string]interface{} {
+	return map[string]interface{}{
+		nodeField: s.nodeID,
+		macField:  hex.EncodeToString(resultMAC(s.key, stream, payload)),
+	}
+}
+
+// resultVerifier checks result entries against the keys of the known nodes
+type resultVerifier struct {
+	keys map[string][]byte
+}
+
+// newResultVerifier creates a verifier from hex encoded keys by node ID. It
+// returns nil when there are no keys, and results are then not verified.
+func newResultVerifier(hexKeys map[string]string) (*resultVerifier, error) {
+	if len(hexKeys) == 0 {
+		return nil, nil
+	}
+
+	keys := make(map[string][]byte, len(hexKeys))
+	for nodeID, hexKey := range hexKeys {
+		key, err := decodeResultKey(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid result key for node %s: %w", nodeID, err)
+		}
+		keys[nodeID] = key
+	}
+	return &resultVerifier{keys: keys}, nil
+}
+
+// verify checks that the entry was published to stream by a known node
+func (v *resultVerifier) verify(stream string, values map[string]interface{}, payload []byte) error {
+	nodeID, _ := values[nodeField].(string)
+	key, ok := v.keys[nodeID]
+	if !ok {
+		return fmt.Errorf("%w: unknown node %q", errUnauthenticatedResult, nodeID)
+	}
+
+	encoded, _ := values[macField].(string)
+	mac, err := hex.DecodeString(encoded)
+	if err != nil || !hmac.Equal(mac, resultMAC(key, stream, payload)) {
+		return fmt.Errorf("%w: bad mac from node %q", errUnauthenticatedResult, nodeID)
+	}
+	return nil
+}
+
+// resultMAC is HMAC-SHA256 over the stream key and the payload
+func resultMAC(key []byte, stream string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stream))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func decodeResultKey(hexKey string) ([]byte, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("key must be hex encoded: %w", err)
+	}
+	if len(key) < minResultKeyLength {
+		return nil, fmt.Errorf("key must be at least %d bytes", minResultKeyLength)
+	}
+	return key, nil
+}
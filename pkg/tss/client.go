@@ -0,0 +1,72 @@
+package tss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"mpc/internal/config"
+	rd "mpc/internal/db/redis"
+)
+
+// Client modes selected with TSS_MODE
+const (
+	ModeGRPC     = "grpc"
+	ModeSoftware = "software"
+)
+
+// KeyGenerator creates keys and rotates their shares
+type KeyGenerator interface {
+	// DefaultTopology returns the topology used for keys that do not request one
+	DefaultTopology() Topology
+	// CreateWallet generates a key. The session ID becomes the key ID.
+	CreateWallet(ctx context.Context, sessionID string, topology Topology) (shareData string, publicKey *ecdsa.PublicKey, err error)
+	// RefreshShares rotates the shares of a key without changing the public key
+	RefreshShares(ctx context.Context, sessionID string, keyID string, shareData string, topology Topology) (newShareData string, publicKey *ecdsa.PublicKey, err error)
+}
+
+// Signer signs message hashes with a key and returns DER signatures
+type Signer interface {
+	Sign(ctx context.Context, sessionID string, keyID string, shareData string, message []byte, topology Topology) ([]byte, error)
+	// SignBatch signs several hashes in one session, in order
+	SignBatch(ctx context.Context, sessionID string, keyID string, shareData string, messages [][]byte, topology Topology) ([][]byte, error)
+}
+
+// Client generates keys and signs with them
+type Client interface {
+	KeyGenerator
+	Signer
+	HealthChecker
+}
+
+var (
+	_ Client = (*TSS)(nil)
+	_ Client = (*Software)(nil)
+	_ Client = (*Instrumented)(nil)
+)
+
+// NewClient creates the client selected by the config, wrapped with metrics
+// and retries
+func NewClient(redisClient *rd.Client, tssConfig *config.TSSConfig) (Client, error) {
+	var client Client
+	switch tssConfig.Mode {
+	case ModeGRPC:
+		tssClient, err := NewTSS(redisClient, tssConfig)
+		if err != nil {
+			return nil, err
+		}
+		client = tssClient
+	case ModeSoftware:
+		defaultTopology := Topology{
+			Parties:   tssConfig.Parties,
+			Threshold: tssConfig.Threshold,
+		}
+		if err := defaultTopology.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid default topology: %w", err)
+		}
+		client = NewSoftware([]byte(tssConfig.SoftwareSeed), defaultTopology)
+	default:
+		return nil, fmt.Errorf("unknown tss mode %q", tssConfig.Mode)
+	}
+
+	return NewInstrumented(client, tssConfig.Retries, tssConfig.RetryBackoff), nil
+}
@@ -0,0 +1,168 @@
+package tss
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"mpc/internal/config"
+	"mpc/pkg/logger"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLS modes selected with TSS_TLS_MODE
+const (
+	TLSModeInsecure = "insecure"
+	TLSModeTLS      = "tls"
+	TLSModeMutual   = "mtls"
+)
+
+// ClientCredentials returns the transport credentials for the channel to the
+// MPC coordinator. With mtls the client presents its certificate, which is
+// reloaded from disk when the files change.
+func ClientCredentials(tssConfig *config.TSSConfig) (credentials.TransportCredentials, error) {
+	switch tssConfig.TLSMode {
+	case TLSModeInsecure:
+		return insecure.NewCredentials(), nil
+	case TLSModeTLS, TLSModeMutual:
+	default:
+		return nil, fmt.Errorf("unknown tls mode %q", tssConfig.TLSMode)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: tssConfig.TLSServerName,
+	}
+	if tssConfig.TLSCAFile != "" {
+		pool, err := loadCertPool(tssConfig.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if tssConfig.TLSMode == TLSModeMutual {
+		reloader, err := newCertReloader(tssConfig.TLSCertFile, tssConfig.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return reloader.certificate()
+		}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// ServerCredentials returns the transport credentials of an MPC node. The
+// certificate is reloaded from disk when the files change. With a client CA,
+// clients must present a certificate signed by it.
+func ServerCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return reloader.certificate()
+		},
+	}
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// certReloader serves a key pair and reloads it when either file is modified,
+// so certificates can be rotated without a restart. Connections are long
+// lived, so the files are only checked when a handshake needs the certificate.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("tls certificate and key files are required")
+	}
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.certificate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// certificate returns the current key pair, reloading it if the files changed.
+// A failed reload keeps serving the previous certificate.
+func (r *certReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			logger.Warn("failed to check tls certificate, keeping the previous one: " + err.Error())
+			return r.cert, nil
+		}
+		return nil, err
+	}
+	if r.cert != nil && !modTime.After(r.modTime) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			logger.Warn("failed to reload tls certificate, keeping the previous one: " + err.Error())
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+	if r.cert != nil {
+		logger.Info("reloaded tls certificate " + r.certFile)
+	}
+	r.cert, r.modTime = &cert, modTime
+	return r.cert, nil
+}
+
+// latestModTime returns the most recent modification time of the files
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// loadCertPool reads the PEM certificates of a CA bundle
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,218 @@
+package tss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Failure codes reported by the MPC nodes
+const (
+	FailurePartyUnavailable = "party_unavailable"
+	FailureTimeout          = "timeout"
+	FailureAborted          = "aborted"
+	FailureCheating         = "cheating"
+	FailureInvalidShare     = "invalid_share"
+	FailureUnknown          = "unknown"
+)
+
+// Errors returned by TSS operations. The first three are transient and the
+// operation can be retried; the others will fail again without intervention.
+var (
+	ErrPartyUnavailable = errors.New("tss: party unavailable")
+	ErrTimeout          = errors.New("tss: protocol timed out")
+	ErrAborted          = errors.New("tss: protocol aborted")
+	ErrCheating         = errors.New("tss: party misbehaved")
+	ErrInvalidShare     = errors.New("tss: invalid key share")
+	ErrNodeFailure      = errors.New("tss: node failure")
+	// ErrMalformedResult is returned when a node reports success with a
+	// result that cannot be used, such as a public key that is not on the curve
+	ErrMalformedResult = errors.New("tss: malformed result")
+	// ErrSessionNotStarted marks failures before the nodes accepted a session,
+	// which can run again under the same session ID
+	ErrSessionNotStarted = errors.New("tss: session not started")
+)
+
+var failureErrors = map[string]error{
+	FailurePartyUnavailable: ErrPartyUnavailable,
+	FailureTimeout:          ErrTimeout,
+	FailureAborted:          ErrAborted,
+	FailureCheating:         ErrCheating,
+	FailureInvalidShare:     ErrInvalidShare,
+}
+
+var (
+	roundPattern = regexp.MustCompile(`(?i)\bround\s+(\d+)`)
+	partyPattern = regexp.MustCompile(`(?i)\bpart(?:y|ies)\s+\[?(\d+(?:\s*,\s*\d+)*)`)
+)
+
+// NodeFailure is the failure part of a result published by the nodes. Nodes
+// should set Code, Round and Parties; older nodes only send Error, in which
+// case the code, round and parties are parsed from the message.
+type NodeFailure struct {
+	Error   string   `json:"error,omitempty"`
+	Code    string   `json:"code,omitempty"`
+	Round   uint32   `json:"round,omitempty"`
+	Parties []uint32 `json:"parties,omitempty"`
+}
+
+// Failed reports whether the result describes a failure
+func (f NodeFailure) Failed() bool {
+	return f.Error != "" || f.Code != ""
+}
+
+// Err returns the failure as a *NodeError, or nil when the result succeeded
+func (f NodeFailure) Err() error {
+	if !f.Failed() {
+		return nil
+	}
+	return parseFailure(f)
+}
+
+// NodeError is a failure reported by the MPC nodes. It unwraps to one of the
+// package errors so callers can use errors.Is.
+type NodeError struct {
+	Code    string
+	Message string
+	// Round is the protocol round the failure happened in, 0 when unknown
+	Round uint32
+	// Parties are the unavailable or misbehaving parties, when identified
+	Parties []uint32
+}
+
+func (e *NodeError) Error() string {
+	msg := e.Code
+	if e.Round > 0 {
+		msg += fmt.Sprintf(" in round %d", e.Round)
+	}
+	if len(e.Parties) > 0 {
+		msg += fmt.Sprintf(" (parties %v)", e.Parties)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return "tss node error: " + msg
+}
+
+func (e *NodeError) Unwrap() error {
+	if err, ok := failureErrors[e.Code]; ok {
+		return err
+	}
+	return ErrNodeFailure
+}
+
+// IsRetryable reports whether a failed operation may succeed when retried
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrPartyUnavailable) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrAborted)
+}
+
+// parseFailure builds a NodeError from a failure, filling in what the node
+// left out from the message text
+func parseFailure(f NodeFailure) *NodeError {
+	nodeErr := &NodeError{
+		Code:    f.Code,
+		Message: f.Error,
+		Round:   f.Round,
+		Parties: f.Parties,
+	}
+	if _, ok := failureErrors[nodeErr.Code]; !ok {
+		nodeErr.Code = classifyFailure(f.Error)
+	}
+	if nodeErr.Round == 0 {
+		if m := roundPattern.FindStringSubmatch(f.Error); m != nil {
+			round, _ := strconv.ParseUint(m[1], 10, 32)
+			nodeErr.Round = uint32(round)
+		}
+	}
+	if len(nodeErr.Parties) == 0 {
+		if m := partyPattern.FindStringSubmatch(f.Error); m != nil {
+			for _, id := range strings.Split(m[1], ",") {
+				party, err := strconv.ParseUint(strings.TrimSpace(id), 10, 32)
+				if err == nil {
+					nodeErr.Parties = append(nodeErr.Parties, uint32(party))
+				}
+			}
+		}
+	}
+	return nodeErr
+}
+
+// classifyFailure maps a free form node message to a failure code
+func classifyFailure(message string) string {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "cheat") || strings.Contains(msg, "culprit") || strings.Contains(msg, "malicious"):
+		return FailureCheating
+	case strings.Contains(msg, "invalid share") || strings.Contains(msg, "share not found") || strings.Contains(msg, "decrypt"):
+		return FailureInvalidShare
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out"):
+		return FailureTimeout
+	case strings.Contains(msg, "unavailable") || strings.Contains(msg, "unreachable") || strings.Contains(msg, "not connected"):
+		return FailurePartyUnavailable
+	case strings.Contains(msg, "abort"):
+		return FailureAborted
+	default:
+		return FailureUnknown
+	}
+}
+
+// notStartedError is a failure before the nodes accepted a session. It
+// matches both ErrSessionNotStarted and the failure itself.
+type notStartedError struct {
+	err error
+}
+
+func (e *notStartedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notStartedError) Unwrap() []error {
+	return []error{ErrSessionNotStarted, e.err}
+}
+
+// notStarted marks err as a failure before the session started
+func notStarted(err error) error {
+	return &notStartedError{err: err}
+}
+
+// checkActionResponse turns a rejected NotifyAction call into a typed error.
+// The nodes rejected the session, so it did not start.
+func checkActionResponse(success bool, message string) error {
+	if success {
+		return nil
+	}
+	return notStarted(parseFailure(NodeFailure{Error: message}))
+}
+
+// notifyError classifies a failed NotifyAction call. An unavailable
+// coordinator never received the request, so the session did not start;
+// after a timeout the nodes may have started it.
+func notifyError(err error) error {
+	classified := classifyRPCError(err)
+	if status.Code(err) == codes.Unavailable {
+		return notStarted(classified)
+	}
+	return classified
+}
+
+// classifyRPCError maps transport failures of the coordinator to package errors
+func classifyRPCError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %v", ErrPartyUnavailable, err)
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	case codes.Aborted:
+		return fmt.Errorf("%w: %v", ErrAborted, err)
+	}
+	return err
+}
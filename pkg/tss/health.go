@@ -0,0 +1,156 @@
+package tss
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	rd "mpc/internal/db/redis"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// heartbeatPrefix prefixes the key each party refreshes while it is running
+const heartbeatPrefix = "tss:heartbeat:"
+
+// ErrNoQuorum is returned before a session starts when fewer parties than it
+// needs are alive. Unlike ErrPartyUnavailable it is not retried: the
+// session was never started and waiting for it would only delay the error.
+var ErrNoQuorum = errors.New("tss: not enough parties alive")
+
+// partyMetrics are published on expvar as "tss_parties" every time the party
+// heartbeats are read
+var partyMetrics = expvar.NewMap("tss_parties")
+
+// HealthChecker reports whether the MPC parties are alive
+type HealthChecker interface {
+	// Health returns the liveness of the parties of the default topology
+	Health(ctx context.Context) (ClusterHealth, error)
+}
+
+// Heartbeat is published by every party while it is running
+type Heartbeat struct {
+	Party     uint32    `json:"party"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PartyStatus is the liveness of a single party
+type PartyStatus struct {
+	Party uint32
+	Alive bool
+	// LastSeen is the time of the last heartbeat, nil when none was found
+	LastSeen *time.Time
+}
+
+// ClusterHealth is the liveness of a party set
+type ClusterHealth struct {
+	Parties   []PartyStatus
+	Alive     int
+	Threshold uint32
+}
+
+// Quorum reports whether enough parties are alive to sign
+func (h ClusterHealth) Quorum() bool {
+	return h.Alive >= int(h.Threshold)
+}
+
+// HeartbeatKey returns the key a party publishes its heartbeat to
+func HeartbeatKey(party uint32) string {
+	return heartbeatPrefix + strconv.FormatUint(uint64(party), 10)
+}
+
+// PublishHeartbeat marks a party alive for ttl
+func PublishHeartbeat(ctx context.Context, redisClient *rd.Client, party uint32, ttl time.Duration) error {
+	payload, err := json.Marshal(Heartbeat{Party: party, Timestamp: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode heartbeat: %w", err)
+	}
+	if err := redisClient.Set(ctx, HeartbeatKey(party), payload, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to publish heartbeat: %w", err)
+	}
+	return nil
+}
+
+// readHealth returns the liveness of the topology parties. A party is alive
+// when its last heartbeat is at most timeout old.
+func readHealth(ctx context.Context, redisClient *rd.Client, topology Topology, timeout time.Duration) (ClusterHealth, error) {
+	keys := make([]string, len(topology.Parties))
+	for i, party := range topology.Parties {
+		keys[i] = HeartbeatKey(party)
+	}
+
+	values, err := redisClient.MGet(ctx, keys...).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return ClusterHealth{}, fmt.Errorf("failed to read party heartbeats: %w", err)
+	}
+
+	health := ClusterHealth{
+		Parties:   make([]PartyStatus, len(topology.Parties)),
+		Threshold: topology.Threshold,
+	}
+	for i, party := range topology.Parties {
+		status := PartyStatus{Party: party}
+		if payload, ok := values[i].(string); ok {
+			var heartbeat Heartbeat
+			if err := json.Unmarshal([]byte(payload), &heartbeat); err == nil {
+				status.LastSeen = &heartbeat.Timestamp
+				status.Alive = time.Since(heartbeat.Timestamp) <= timeout
+			}
+		}
+		if status.Alive {
+			health.Alive++
+		}
+		health.Parties[i] = status
+	}
+
+	recordHealth(health)
+	return health, nil
+}
+
+// Health returns the liveness of the parties of the default topology
+func (t *TSS) Health(ctx context.Context) (ClusterHealth, error) {
+	return readHealth(ctx, t.redisClient, t.defaultTopology, t.heartbeatTimeout)
+}
+
+// checkQuorum returns ErrNoQuorum, naming the missing parties, when fewer
+// than required parties of the topology are alive. It is skipped when
+// TSS_QUORUM_CHECK is off, for nodes that do not publish heartbeats.
+func (t *TSS) checkQuorum(ctx context.Context, topology Topology, required int) error {
+	if !t.quorumCheck {
+		return nil
+	}
+
+	health, err := readHealth(ctx, t.redisClient, topology, t.heartbeatTimeout)
+	if err != nil {
+		return err
+	}
+	if health.Alive >= required {
+		return nil
+	}
+
+	var missing []uint32
+	for _, status := range health.Parties {
+		if !status.Alive {
+			missing = append(missing, status.Party)
+		}
+	}
+	partyMetrics.Add("quorum_failures", 1)
+	return fmt.Errorf("%w: %d of %d required parties alive, no heartbeat from parties %v", ErrNoQuorum, health.Alive, required, missing)
+}
+
+// recordHealth publishes the liveness of every party as a 0/1 gauge
+func recordHealth(health ClusterHealth) {
+	for _, status := range health.Parties {
+		alive := new(expvar.Int)
+		if status.Alive {
+			alive.Set(1)
+		}
+		partyMetrics.Set("party_"+strconv.FormatUint(uint64(status.Party), 10)+"_alive", alive)
+	}
+	alive := new(expvar.Int)
+	alive.Set(int64(health.Alive))
+	partyMetrics.Set("alive", alive)
+}
@@ -0,0 +1,112 @@
+package tss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"expvar"
+	"time"
+
+	"mpc/pkg/logger"
+)
+
+// metrics are published on expvar as "tss", keyed by <operation>_<counter>
+var metrics = expvar.NewMap("tss")
+
+// Instrumented wraps a Client, recording call counts, failures and latency and
+// retrying calls that failed because parties were unavailable before the
+// nodes accepted the session. A session that started may still be running or
+// have signed, so failures reported after NotifyAction was accepted are never
+// retried under the same session ID.
+type Instrumented struct {
+	next    Client
+	retries int
+	backoff time.Duration
+}
+
+// NewInstrumented wraps next, retrying up to retries times with a linear backoff
+func NewInstrumented(next Client, retries int, backoff time.Duration) *Instrumented {
+	return &Instrumented{
+		next:    next,
+		retries: retries,
+		backoff: backoff,
+	}
+}
+
+// DefaultTopology returns the topology of the wrapped client
+func (i *Instrumented) DefaultTopology() Topology {
+	return i.next.DefaultTopology()
+}
+
+// CreateWallet calls the wrapped client
+func (i *Instrumented) CreateWallet(ctx context.Context, sessionID string, topology Topology) (shareData string, publicKey *ecdsa.PublicKey, err error) {
+	err = i.call(ctx, "keygen", func() error {
+		shareData, publicKey, err = i.next.CreateWallet(ctx, sessionID, topology)
+		return err
+	})
+	return shareData, publicKey, err
+}
+
+// Sign calls the wrapped client
+func (i *Instrumented) Sign(ctx context.Context, sessionID string, keyID string, shareData string, message []byte, topology Topology) (signature []byte, err error) {
+	err = i.call(ctx, "sign", func() error {
+		signature, err = i.next.Sign(ctx, sessionID, keyID, shareData, message, topology)
+		return err
+	})
+	return signature, err
+}
+
+// SignBatch calls the wrapped client
+func (i *Instrumented) SignBatch(ctx context.Context, sessionID string, keyID string, shareData string, messages [][]byte, topology Topology) (signatures [][]byte, err error) {
+	err = i.call(ctx, "sign_batch", func() error {
+		signatures, err = i.next.SignBatch(ctx, sessionID, keyID, shareData, messages, topology)
+		return err
+	})
+	return signatures, err
+}
+
+// RefreshShares calls the wrapped client
+func (i *Instrumented) RefreshShares(ctx context.Context, sessionID string, keyID string, shareData string, topology Topology) (newShareData string, publicKey *ecdsa.PublicKey, err error) {
+	err = i.call(ctx, "reshare", func() error {
+		newShareData, publicKey, err = i.next.RefreshShares(ctx, sessionID, keyID, shareData, topology)
+		return err
+	})
+	return newShareData, publicKey, err
+}
+
+// Health calls the wrapped client
+func (i *Instrumented) Health(ctx context.Context) (ClusterHealth, error) {
+	return i.next.Health(ctx)
+}
+
+// call runs fn, retrying while parties are unavailable and the session has
+// not started, and records metrics
+func (i *Instrumented) call(ctx context.Context, op string, fn func() error) error {
+	start := time.Now()
+	defer func() {
+		metrics.Add(op+"_calls", 1)
+		metrics.Add(op+"_duration_ms", time.Since(start).Milliseconds())
+	}()
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= i.retries || !errors.Is(err, ErrPartyUnavailable) || !errors.Is(err, ErrSessionNotStarted) {
+			break
+		}
+
+		metrics.Add(op+"_retries", 1)
+		logger.Warn("tss " + op + " failed, retrying: " + err.Error())
+		select {
+		case <-ctx.Done():
+			metrics.Add(op+"_failures", 1)
+			return err
+		case <-time.After(time.Duration(attempt+1) * i.backoff):
+		}
+	}
+
+	metrics.Add(op+"_failures", 1)
+	return err
+}
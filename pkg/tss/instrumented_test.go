@@ -0,0 +1,71 @@
+package tss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// failingSigner fails Sign with errs in turn, then succeeds
+type failingSigner struct {
+	*Software
+	errs  []error
+	calls int
+}
+
+func (f *failingSigner) Sign(ctx context.Context, sessionID string, keyID string, shareData string, message []byte, topology Topology) ([]byte, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return []byte("signature"), nil
+}
+
+func TestInstrumentedRetries(t *testing.T) {
+	unavailable := fmt.Errorf("failed to notify signing action: %w", notStarted(fmt.Errorf("%w: connection refused", ErrPartyUnavailable)))
+	rejected := fmt.Errorf("signing action rejected: %w", checkActionResponse(false, "party 3 unavailable"))
+	started := fmt.Errorf("signature generation failed: %w", parseFailure(NodeFailure{Error: "party 3 unavailable", Code: FailurePartyUnavailable}))
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "coordinator unavailable", errs: []error{unavailable}, wantCalls: 2},
+		{name: "action rejected", errs: []error{rejected, rejected}, wantCalls: 3},
+		{name: "retries exhausted", errs: []error{unavailable, unavailable, unavailable}, wantCalls: 3, wantErr: ErrPartyUnavailable},
+		{name: "failure after the session started", errs: []error{started}, wantCalls: 1, wantErr: ErrPartyUnavailable},
+		{name: "invalid share", errs: []error{notStarted(ErrInvalidShare)}, wantCalls: 1, wantErr: ErrInvalidShare},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &failingSigner{Software: NewSoftware(nil, Topology{Parties: []uint32{1, 2}, Threshold: 2}), errs: tt.errs}
+			client := NewInstrumented(next, 2, 0)
+
+			_, err := client.Sign(context.Background(), "session", "key", "", make([]byte, 32), next.DefaultTopology())
+			if next.calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", next.calls, tt.wantCalls)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("got %v, want success", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSessionStartedFailuresAreNotMarkedNotStarted(t *testing.T) {
+	err := parseFailure(NodeFailure{Error: "party 2 unavailable", Code: FailurePartyUnavailable})
+	if errors.Is(err, ErrSessionNotStarted) {
+		t.Fatal("a failure published by the nodes must not be marked as not started")
+	}
+
+	var nodeErr *NodeError
+	if !errors.As(checkActionResponse(false, "party 2 unavailable"), &nodeErr) || nodeErr.Code != FailurePartyUnavailable {
+		t.Fatalf("rejected action lost its node error: %v", nodeErr)
+	}
+}
@@ -0,0 +1,171 @@
+package tss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	rd "mpc/internal/db/redis"
+	"mpc/pkg/logger"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// resultTTL bounds how long an unread result is kept in Redis
+	resultTTL = 10 * time.Minute
+	// resultReadBlock is how long a single XREAD waits before the context is checked again
+	resultReadBlock = 5 * time.Second
+	// resultField is the stream entry field holding the JSON payload
+	resultField = "payload"
+)
+
+// KeygenResult is published by the nodes when keygen or reshare finishes.
+// PubKey is the hex encoded 65 byte uncompressed secp256k1 public key; the
+// address is derived from it, never taken from the node.
+type KeygenResult struct {
+	ShareData string `json:"share_data,omitempty"`
+	PubKey    string `json:"pub_key,omitempty"`
+	NodeFailure
+}
+
+// SignResult is published by the nodes when signing finishes. Signature is a
+// base64 encoded DER signature; batch sessions set Signatures instead, in the
+// order of the requested hashes.
+type SignResult struct {
+	Signature  string   `json:"signature,omitempty"`
+	Signatures []string `json:"signatures,omitempty"`
+	NodeFailure
+}
+
+// ParsePublicKey decodes a hex encoded uncompressed secp256k1 public key,
+// with or without 0x prefix, and checks that it is on the curve
+func ParsePublicKey(hexKey string) (*ecdsa.PublicKey, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("public key is not hex: %w", err)
+	}
+	if len(raw) != 65 || raw[0] != 0x04 {
+		return nil, fmt.Errorf("public key must be 65 byte uncompressed, got %d bytes", len(raw))
+	}
+	publicKey, err := crypto.UnmarshalPubkey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if !crypto.S256().IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, fmt.Errorf("public key is not on the secp256k1 curve")
+	}
+	return publicKey, nil
+}
+
+// KeygenResultKey returns the stream the keygen result of a session is published to
+func KeygenResultKey(sessionID string) string {
+	return keygenPrefix + sessionID
+}
+
+// SignResultKey returns the stream the sign result of a session is published to
+func SignResultKey(sessionID string) string {
+	return signPrefix + sessionID
+}
+
+// ReshareResultKey returns the stream the reshare result of a session is published to
+func ReshareResultKey(sessionID string) string {
+	return resharePrefix + sessionID
+}
+
+// PublishResult appends a result to a session stream. Results are kept until
+// read or until resultTTL passes, so a result published before the waiter
+// starts reading is still delivered. With a signer the entry carries the node
+// ID and a MAC of the result.
+func PublishResult(ctx context.Context, redisClient *rd.Client, signer *ResultSigner, key string, result interface{}) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	values := map[string]interface{}{resultField: payload}
+	if signer != nil {
+		for field, value := range signer.fields(key, payload) {
+			values[field] = value
+		}
+	}
+
+	pipe := redisClient.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		Values: values,
+	})
+	pipe.Expire(ctx, key, resultTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish result: %w", err)
+	}
+	return nil
+}
+
+// awaitResult reads a session stream from its first entry until handle reports
+// that an entry completed the session, or the context ends. The stream is
+// deleted afterwards so a session is only ever completed once. When result
+// keys are configured, entries without a valid MAC are skipped.
+func (t *TSS) awaitResult(ctx context.Context, key string, handle func(payload []byte) (bool, error)) error {
+	defer func() {
+		// Use a fresh context, ctx may already be cancelled
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := t.redisClient.Del(cleanupCtx, key).Err(); err != nil {
+			logger.Warn("failed to delete result stream " + key)
+		}
+	}()
+
+	lastID := "0"
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		streams, err := t.redisClient.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{key, lastID},
+			Block:   resultReadBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to read result stream: %w", err)
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				lastID = msg.ID
+
+				payload, ok := msg.Values[resultField].(string)
+				if !ok {
+					logger.Warn("result entry without payload in " + key)
+					continue
+				}
+				if t.verifier != nil {
+					if err := t.verifier.verify(key, msg.Values, []byte(payload)); err != nil {
+						metrics.Add("results_rejected", 1)
+						logger.Warn("rejected result entry in " + key + ": " + err.Error())
+						continue
+					}
+				}
+
+				done, err := handle([]byte(payload))
+				if err != nil {
+					return err
+				}
+				if done {
+					return nil
+				}
+			}
+		}
+	}
+}
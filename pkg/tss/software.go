@@ -0,0 +1,184 @@
+package tss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Software is a Client that keeps a plain secp256k1 key per key ID in memory.
+// It is meant for tests and local development: there is no MPC, the share
+// data is only a token identifying the current share version.
+//
+// With a seed, keys are derived from the seed and the key ID, so the same
+// session IDs always produce the same addresses and signatures.
+type Software struct {
+	seed            []byte
+	defaultTopology Topology
+
+	mu   sync.Mutex
+	keys map[string]*softwareKey
+}
+
+type softwareKey struct {
+	privateKey *ecdsa.PrivateKey
+	version    int
+}
+
+type derSignature struct {
+	R, S *big.Int
+}
+
+// NewSoftware creates a software client. An empty seed generates random keys.
+func NewSoftware(seed []byte, defaultTopology Topology) *Software {
+	return &Software{
+		seed:            seed,
+		defaultTopology: defaultTopology,
+		keys:            make(map[string]*softwareKey),
+	}
+}
+
+// DefaultTopology returns the topology used for wallets that do not request one
+func (s *Software) DefaultTopology() Topology {
+	return s.defaultTopology
+}
+
+// CreateWallet generates a key for the session and returns its public key
+func (s *Software) CreateWallet(ctx context.Context, sessionID string, topology Topology) (string, *ecdsa.PublicKey, error) {
+	if err := topology.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	privateKey, err := s.generateKey(sessionID)
+	if err != nil {
+		return "", nil, fmt.Errorf("key generation failed: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[sessionID]; ok {
+		return "", nil, fmt.Errorf("key generation failed: key %s already exists", sessionID)
+	}
+	key := &softwareKey{privateKey: privateKey}
+	s.keys[sessionID] = key
+	return key.shareData(sessionID), &privateKey.PublicKey, nil
+}
+
+// Sign signs the message hash with the key if the share data is current
+func (s *Software) Sign(ctx context.Context, sessionID string, keyID string, shareData string, message []byte, topology Topology) ([]byte, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := s.lookup(keyID, shareData)
+	if err != nil {
+		return nil, fmt.Errorf("signature generation failed: %w", err)
+	}
+
+	der, err := key.sign(message)
+	if err != nil {
+		return nil, fmt.Errorf("signature generation failed: %w", err)
+	}
+	return der, nil
+}
+
+// SignBatch signs every message with the key if the share data is current
+func (s *Software) SignBatch(ctx context.Context, sessionID string, keyID string, shareData string, messages [][]byte, topology Topology) ([][]byte, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := s.lookup(keyID, shareData)
+	if err != nil {
+		return nil, fmt.Errorf("batch signature generation failed: %w", err)
+	}
+
+	signatures := make([][]byte, len(messages))
+	for i, message := range messages {
+		if signatures[i], err = key.sign(message); err != nil {
+			return nil, fmt.Errorf("batch signature generation failed: %w", err)
+		}
+	}
+	return signatures, nil
+}
+
+// RefreshShares invalidates the previous share data of the key and returns a new one
+func (s *Software) RefreshShares(ctx context.Context, sessionID string, keyID string, shareData string, topology Topology) (string, *ecdsa.PublicKey, error) {
+	if err := topology.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	key, err := s.lookup(keyID, shareData)
+	if err != nil {
+		return "", nil, fmt.Errorf("key reshare failed: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key.version++
+	return key.shareData(keyID), &key.privateKey.PublicKey, nil
+}
+
+// Health reports every party of the default topology alive, there are no
+// remote parties to lose
+func (s *Software) Health(ctx context.Context) (ClusterHealth, error) {
+	health := ClusterHealth{
+		Parties:   make([]PartyStatus, len(s.defaultTopology.Parties)),
+		Alive:     len(s.defaultTopology.Parties),
+		Threshold: s.defaultTopology.Threshold,
+	}
+	for i, party := range s.defaultTopology.Parties {
+		health.Parties[i] = PartyStatus{Party: party, Alive: true}
+	}
+	return health, nil
+}
+
+// generateKey derives the key from the seed, or generates a random one
+func (s *Software) generateKey(keyID string) (*ecdsa.PrivateKey, error) {
+	if len(s.seed) == 0 {
+		return crypto.GenerateKey()
+	}
+	return crypto.ToECDSA(crypto.Keccak256(s.seed, []byte(keyID)))
+}
+
+// lookup returns the key if shareData is its current share
+func (s *Software) lookup(keyID, shareData string) (*softwareKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, &NodeError{Code: FailureInvalidShare, Message: "key " + keyID + " not found"}
+	}
+	if shareData != key.shareData(keyID) {
+		return nil, &NodeError{Code: FailureInvalidShare, Message: "share data is not current"}
+	}
+	return key, nil
+}
+
+// sign signs a 32 byte hash and returns a DER signature
+func (k *softwareKey) sign(hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, k.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	der, err := asn1.Marshal(derSignature{
+		R: new(big.Int).SetBytes(sig[:32]),
+		S: new(big.Int).SetBytes(sig[32:64]),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signature: %w", err)
+	}
+	return der, nil
+}
+
+// shareData is the opaque share token handed to the client
+func (k *softwareKey) shareData(keyID string) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d", keyID, k.version)))
+}
@@ -0,0 +1,111 @@
+package tss
+
+import (
+	"errors"
+	"fmt"
+	"mpc/internal/config"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTopology is returned when a party set or threshold cannot be used
+var ErrInvalidTopology = errors.New("invalid tss topology")
+
+// Topology describes the parties holding shares of a key and the threshold
+// passed to the nodes for keygen and signing
+type Topology struct {
+	Parties   []uint32
+	Threshold uint32
+}
+
+// Validate checks that the party IDs are unique and non-zero and that the
+// threshold can be reached by the party set
+func (t Topology) Validate() error {
+	if len(t.Parties) == 0 {
+		return fmt.Errorf("%w: no parties", ErrInvalidTopology)
+	}
+	if t.Threshold == 0 || int(t.Threshold) > len(t.Parties) {
+		return fmt.Errorf("%w: threshold %d with %d parties", ErrInvalidTopology, t.Threshold, len(t.Parties))
+	}
+
+	seen := make(map[uint32]bool, len(t.Parties))
+	for _, party := range t.Parties {
+		if party == 0 {
+			return fmt.Errorf("%w: party id must be non-zero", ErrInvalidTopology)
+		}
+		if seen[party] {
+			return fmt.Errorf("%w: duplicate party %d", ErrInvalidTopology, party)
+		}
+		seen[party] = true
+	}
+	return nil
+}
+
+// String formats the topology as t-of-n
+func (t Topology) String() string {
+	return fmt.Sprintf("%d-of-%d %v", t.Threshold, len(t.Parties), t.Parties)
+}
+
+// Matches reports whether the topology has the given threshold and the given
+// parties, in any order
+func (t Topology) Matches(parties []uint32, threshold uint32) bool {
+	if t.Threshold != threshold {
+		return false
+	}
+	want, got := slices.Clone(t.Parties), slices.Clone(parties)
+	slices.Sort(want)
+	slices.Sort(got)
+	return slices.Equal(want, got)
+}
+
+// AllowedTopologies returns the topologies new wallets may use: the default
+// one of TSS_PARTIES and TSS_THRESHOLD first, then those of TSS_TOPOLOGIES.
+// Each needs a threshold of at least 2, so no single share is the key, and
+// parties that are all among TSS_PARTIES.
+func AllowedTopologies(tssConfig *config.TSSConfig) ([]Topology, error) {
+	topologies := []Topology{{Parties: tssConfig.Parties, Threshold: tssConfig.Threshold}}
+	for _, entry := range tssConfig.Topologies {
+		topology, err := parseTopology(entry)
+		if err != nil {
+			return nil, err
+		}
+		topologies = append(topologies, topology)
+	}
+
+	for _, topology := range topologies {
+		if err := topology.Validate(); err != nil {
+			return nil, err
+		}
+		if topology.Threshold < 2 {
+			return nil, fmt.Errorf("%w: threshold %d lets one party sign alone", ErrInvalidTopology, topology.Threshold)
+		}
+		for _, party := range topology.Parties {
+			if !slices.Contains(tssConfig.Parties, party) {
+				return nil, fmt.Errorf("%w: party %d of %s is not in TSS_PARTIES", ErrInvalidTopology, party, topology)
+			}
+		}
+	}
+	return topologies, nil
+}
+
+// parseTopology parses a threshold:parties entry such as 2:1,2,3
+func parseTopology(entry string) (Topology, error) {
+	threshold, parties, ok := strings.Cut(strings.TrimSpace(entry), ":")
+	if !ok {
+		return Topology{}, fmt.Errorf("%w: %q is not threshold:parties", ErrInvalidTopology, entry)
+	}
+	t, err := strconv.ParseUint(threshold, 10, 32)
+	if err != nil {
+		return Topology{}, fmt.Errorf("%w: invalid threshold in %q", ErrInvalidTopology, entry)
+	}
+	topology := Topology{Threshold: uint32(t)}
+	for _, field := range strings.Split(parties, ",") {
+		party, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err != nil {
+			return Topology{}, fmt.Errorf("%w: invalid party in %q", ErrInvalidTopology, entry)
+		}
+		topology.Parties = append(topology.Parties, uint32(party))
+	}
+	return topology, nil
+}
@@ -0,0 +1,48 @@
+package tss
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"mpc/internal/config"
+)
+
+func TestAllowedTopologies(t *testing.T) {
+	tests := []struct {
+		name       string
+		parties    []uint32
+		threshold  uint32
+		topologies []string
+		want       []Topology
+		wantErr    bool
+	}{
+		{name: "default only", parties: []uint32{1, 2, 3}, threshold: 2, want: []Topology{{Parties: []uint32{1, 2, 3}, Threshold: 2}}},
+		{
+			name: "retail and treasury", parties: []uint32{1, 2, 3, 4, 5}, threshold: 3, topologies: []string{"2:1,2", " 3:1, 2, 3, 4, 5"},
+			want: []Topology{{Parties: []uint32{1, 2, 3, 4, 5}, Threshold: 3}, {Parties: []uint32{1, 2}, Threshold: 2}, {Parties: []uint32{1, 2, 3, 4, 5}, Threshold: 3}},
+		},
+		{name: "single party", parties: []uint32{1, 2, 3}, threshold: 2, topologies: []string{"1:1"}, wantErr: true},
+		{name: "single party default", parties: []uint32{1}, threshold: 1, wantErr: true},
+		{name: "party not configured", parties: []uint32{1, 2, 3}, threshold: 2, topologies: []string{"2:1,4"}, wantErr: true},
+		{name: "threshold above parties", parties: []uint32{1, 2, 3}, threshold: 2, topologies: []string{"3:1,2"}, wantErr: true},
+		{name: "malformed", parties: []uint32{1, 2, 3}, threshold: 2, topologies: []string{"1,2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AllowedTopologies(&config.TSSConfig{Parties: tt.parties, Threshold: tt.threshold, Topologies: tt.topologies})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTopology) {
+					t.Fatalf("got %v, want %v", err, ErrInvalidTopology)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(got, tt.want, func(a, b Topology) bool { return a.Threshold == b.Threshold && slices.Equal(a.Parties, b.Parties) }) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,189 +2,360 @@ package tss
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
+	"mpc/internal/config"
 	rd "mpc/internal/db/redis"
 	"mpc/pkg/logger"
 	pb "mpc/proto"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
-	grpcAddress  = "localhost:50051"
-	rpcTimeout   = 5 * time.Minute
-	keygenPrefix = "keygen:"
-	signPrefix   = "sign:"
+	rpcTimeout    = 5 * time.Minute
+	keygenPrefix  = "keygen:"
+	signPrefix    = "sign:"
+	resharePrefix = "reshare:"
 )
 
-// Configuration for TSS operations
-type Config struct {
-	Parties   []uint32
-	Threshold uint32
-}
-
 // TSS handles threshold signature operations
 type TSS struct {
-	redisClient *rd.Client
-	rpcClient   pb.MPCServiceClient
-	config      Config
+	redisClient      *rd.Client
+	rpcClient        pb.MPCServiceClient
+	defaultTopology  Topology
+	quorumCheck      bool
+	heartbeatTimeout time.Duration
+	verifier         *resultVerifier
 }
 
 // NewTSS creates a new TSS instance with connection pooling
-func NewTSS(redisClient *rd.Client) (*TSS, error) {
-	conn, err := grpc.Dial(grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewTSS(redisClient *rd.Client, tssConfig *config.TSSConfig) (*TSS, error) {
+	defaultTopology := Topology{
+		Parties:   tssConfig.Parties,
+		Threshold: tssConfig.Threshold,
+	}
+	if err := defaultTopology.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid default topology: %w", err)
+	}
+
+	creds, err := ClientCredentials(tssConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls config: %w", err)
+	}
+	if tssConfig.TLSMode == TLSModeInsecure {
+		logger.Warn("TSS gRPC channel is not encrypted, set TSS_TLS_MODE to tls or mtls")
+	}
+
+	verifier, err := newResultVerifier(tssConfig.ResultKeys)
+	if err != nil {
+		return nil, err
+	}
+	if verifier == nil {
+		logger.Warn("TSS results are not authenticated, set TSS_RESULT_KEYS")
+	}
+
+	conn, err := grpc.Dial(tssConfig.GRPCAddress, grpc.WithTransportCredentials(creds))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to gRPC server: %w", err)
 	}
 
 	return &TSS{
-		redisClient: redisClient,
-		rpcClient:   pb.NewMPCServiceClient(conn),
-		config: Config{
-			Parties:   []uint32{1, 2, 3},
-			Threshold: 2,
-		},
+		redisClient:      redisClient,
+		rpcClient:        pb.NewMPCServiceClient(conn),
+		defaultTopology:  defaultTopology,
+		quorumCheck:      tssConfig.QuorumCheck,
+		heartbeatTimeout: tssConfig.HeartbeatTimeout,
+		verifier:         verifier,
 	}, nil
 }
 
-// CreateWallet initiates key generation for a new wallet
-func (t *TSS) CreateWallet(ctx context.Context, sessionID string) (shareData string, publicKey string, err error) {
+// DefaultTopology returns the topology used for wallets that do not request one
+func (t *TSS) DefaultTopology() Topology {
+	return t.defaultTopology
+}
+
+// CreateWallet initiates key generation for a new wallet. The session ID
+// becomes the key ID used to sign with the key later.
+func (t *TSS) CreateWallet(ctx context.Context, sessionID string, topology Topology) (shareData string, publicKey *ecdsa.PublicKey, err error) {
+	if err := topology.Validate(); err != nil {
+		return "", nil, err
+	}
+
 	// Set up context with timeout
 	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
 	defer cancel()
 
+	// Every party takes part in keygen
+	if err := t.checkQuorum(ctx, topology, len(topology.Parties)); err != nil {
+		return "", nil, fmt.Errorf("keygen not started: %w", err)
+	}
+
 	// Notify key generation action
-	_, err = t.rpcClient.NotifyAction(ctx, &pb.ActionRequest{
+	resp, err := t.rpcClient.NotifyAction(ctx, &pb.ActionRequest{
 		SessionId: sessionID,
-		Parties:   t.config.Parties,
-		Threshold: t.config.Threshold,
+		Parties:   topology.Parties,
+		Threshold: topology.Threshold,
 		Action:    pb.Action_INIT_KEYGEN,
+		KeyId:     sessionID,
 	})
 	if err != nil {
-		return "", "", fmt.Errorf("failed to notify keygen action: %w", err)
+		return "", nil, fmt.Errorf("failed to notify keygen action: %w", notifyError(err))
+	}
+	if err := checkActionResponse(resp.GetSuccess(), resp.GetError()); err != nil {
+		return "", nil, fmt.Errorf("keygen action rejected: %w", err)
 	}
-
-	// Subscribe to keygen results channel
-	keygenChannel := fmt.Sprintf("%s%s", keygenPrefix, sessionID)
-	logger.Debug("Subscribing to keygen channel: " + keygenChannel)
-	pubsub := t.redisClient.Subscribe(ctx, keygenChannel)
-	defer pubsub.Close()
 
 	// Wait for results with timeout
-	shareData, publicKey, err = processKeygenResult(ctx, pubsub.Channel())
-	if err != nil {
-		return "", "", fmt.Errorf("key generation failed: %w", err)
+	keygenStream := KeygenResultKey(sessionID)
+	logger.Debug("Reading keygen stream: " + keygenStream)
+	var result keygenOutput
+	if err := t.awaitResult(ctx, keygenStream, processKeygenResult(&result)); err != nil {
+		return "", nil, fmt.Errorf("key generation failed: %w", classifyRPCError(err))
 	}
 
-	return shareData, publicKey, nil
+	return result.shareData, result.publicKey, nil
 }
 
-// Sign creates a threshold signature for the given message
-func (t *TSS) Sign(ctx context.Context, sessionID string, shareData string, message []byte) ([]byte, error) {
+// Sign creates a threshold signature for the given message using the
+// topology the key was generated with
+func (t *TSS) Sign(ctx context.Context, sessionID string, keyID string, shareData string, message []byte, topology Topology) ([]byte, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Set up context with timeout
 	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
 	defer cancel()
 
 	encryptedShare, err := base64.StdEncoding.DecodeString(shareData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode share data: %w", err)
+		return nil, fmt.Errorf("%w: failed to decode share data: %v", ErrInvalidShare, err)
+	}
+
+	if err := t.checkQuorum(ctx, topology, int(topology.Threshold)); err != nil {
+		return nil, fmt.Errorf("signing not started: %w", err)
 	}
 
 	// Notify signing action
-	_, err = t.rpcClient.NotifyAction(ctx, &pb.ActionRequest{
+	resp, err := t.rpcClient.NotifyAction(ctx, &pb.ActionRequest{
 		SessionId: sessionID,
-		Parties:   t.config.Parties,
-		Threshold: t.config.Threshold,
+		Parties:   topology.Parties,
+		Threshold: topology.Threshold,
 		MsgHash:   message,
 		ShareData: encryptedShare,
 		Action:    pb.Action_INIT_SIGN,
+		KeyId:     keyID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to notify signing action: %w", err)
+		return nil, fmt.Errorf("failed to notify signing action: %w", notifyError(err))
+	}
+	if err := checkActionResponse(resp.GetSuccess(), resp.GetError()); err != nil {
+		return nil, fmt.Errorf("signing action rejected: %w", err)
 	}
-
-	// Subscribe to signing results channel
-	signChannel := fmt.Sprintf("%s%s", signPrefix, sessionID)
-	pubsub := t.redisClient.Subscribe(ctx, signChannel)
-	defer pubsub.Close()
 
 	// Wait for signature with timeout
-	signature, err := processSignResult(ctx, pubsub.Channel())
-	if err != nil {
-		return nil, fmt.Errorf("signature generation failed: %w", err)
+	var signature []byte
+	if err := t.awaitResult(ctx, SignResultKey(sessionID), processSignResult(&signature)); err != nil {
+		return nil, fmt.Errorf("signature generation failed: %w", classifyRPCError(err))
 	}
 
 	return signature, nil
 }
 
-// processKeygenResult handles the keygen result from Redis PubSub
-func processKeygenResult(ctx context.Context, ch <-chan *redis.Message) (string, string, error) {
-	for {
-		select {
-		case <-ctx.Done():
-			return "", "", ctx.Err()
-		case msg, ok := <-ch:
-			if !ok {
-				return "", "", fmt.Errorf("channel closed")
-			}
+// SignBatch signs every message in one session and returns the DER signatures
+// in the same order
+func (t *TSS) SignBatch(ctx context.Context, sessionID string, keyID string, shareData string, messages [][]byte, topology Topology) ([][]byte, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to sign")
+	}
 
-			var result map[string]string
-			if err := json.Unmarshal([]byte(msg.Payload), &result); err != nil {
-				log.Printf("Warning: Failed to parse JSON: %v", err)
-				continue
-			}
+	// Set up context with timeout
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
 
-			shareData, ok := result["share_data"]
-			if !ok {
-				continue // Incomplete message, wait for next one
-			}
+	encryptedShare, err := base64.StdEncoding.DecodeString(shareData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode share data: %v", ErrInvalidShare, err)
+	}
 
-			publicKey, ok := result["pub_key"]
-			if !ok {
-				continue // Incomplete message, wait for next one
-			}
+	if err := t.checkQuorum(ctx, topology, int(topology.Threshold)); err != nil {
+		return nil, fmt.Errorf("batch signing not started: %w", err)
+	}
+
+	// Notify batch signing action
+	resp, err := t.rpcClient.NotifyAction(ctx, &pb.ActionRequest{
+		SessionId: sessionID,
+		Parties:   topology.Parties,
+		Threshold: topology.Threshold,
+		MsgHashes: messages,
+		ShareData: encryptedShare,
+		Action:    pb.Action_INIT_SIGN_BATCH,
+		KeyId:     keyID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to notify batch signing action: %w", notifyError(err))
+	}
+	if err := checkActionResponse(resp.GetSuccess(), resp.GetError()); err != nil {
+		return nil, fmt.Errorf("batch signing action rejected: %w", err)
+	}
+
+	// Wait for signatures with timeout
+	var signatures [][]byte
+	if err := t.awaitResult(ctx, SignResultKey(sessionID), processSignBatchResult(&signatures, len(messages))); err != nil {
+		return nil, fmt.Errorf("batch signature generation failed: %w", classifyRPCError(err))
+	}
 
-			return shareData, publicKey, nil
+	return signatures, nil
+}
+
+// RefreshShares rotates the key shares of every party holding the key. The
+// public key, and therefore the wallet address, stays the same; the returned
+// share data replaces the caller's previous share.
+func (t *TSS) RefreshShares(ctx context.Context, sessionID string, keyID string, shareData string, topology Topology) (newShareData string, publicKey *ecdsa.PublicKey, err error) {
+	if err := topology.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	// Set up context with timeout
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	encryptedShare, err := base64.StdEncoding.DecodeString(shareData)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: failed to decode share data: %v", ErrInvalidShare, err)
+	}
+
+	// Every party receives a new share
+	if err := t.checkQuorum(ctx, topology, len(topology.Parties)); err != nil {
+		return "", nil, fmt.Errorf("reshare not started: %w", err)
+	}
+
+	// Notify reshare action
+	resp, err := t.rpcClient.NotifyAction(ctx, &pb.ActionRequest{
+		SessionId: sessionID,
+		Parties:   topology.Parties,
+		Threshold: topology.Threshold,
+		ShareData: encryptedShare,
+		Action:    pb.Action_INIT_RESHARE,
+		KeyId:     keyID,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to notify reshare action: %w", notifyError(err))
+	}
+	if err := checkActionResponse(resp.GetSuccess(), resp.GetError()); err != nil {
+		return "", nil, fmt.Errorf("reshare action rejected: %w", err)
+	}
+
+	// Wait for results with timeout, the result has the same shape as keygen
+	var result keygenOutput
+	if err := t.awaitResult(ctx, ReshareResultKey(sessionID), processKeygenResult(&result)); err != nil {
+		return "", nil, fmt.Errorf("key reshare failed: %w", classifyRPCError(err))
+	}
+
+	return result.shareData, result.publicKey, nil
+}
+
+// keygenOutput is a keygen or reshare result with its public key decoded
+type keygenOutput struct {
+	shareData string
+	publicKey *ecdsa.PublicKey
+}
+
+// processKeygenResult returns a handler that stores the first complete keygen
+// or reshare result in out. A result with a malformed public key fails the
+// session instead of being handed to the caller.
+func processKeygenResult(out *keygenOutput) func(payload []byte) (bool, error) {
+	return func(payload []byte) (bool, error) {
+		var result KeygenResult
+		if err := json.Unmarshal(payload, &result); err != nil {
+			log.Printf("Warning: Failed to parse JSON: %v", err)
+			return false, nil
+		}
+
+		if err := result.Err(); err != nil {
+			return false, err
+		}
+		if result.ShareData == "" || result.PubKey == "" {
+			return false, nil // Incomplete message, wait for next one
 		}
+
+		publicKey, err := ParsePublicKey(result.PubKey)
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrMalformedResult, err)
+		}
+
+		*out = keygenOutput{shareData: result.ShareData, publicKey: publicKey}
+		return true, nil
 	}
 }
 
-// processSignResult handles the signing result from Redis PubSub
-func processSignResult(ctx context.Context, ch <-chan *redis.Message) ([]byte, error) {
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case msg, ok := <-ch:
-			if !ok {
-				return nil, fmt.Errorf("channel closed")
-			}
+// processSignResult returns a handler that stores the first decodable
+// signature in out
+func processSignResult(out *[]byte) func(payload []byte) (bool, error) {
+	return func(payload []byte) (bool, error) {
+		var result SignResult
+		if err := json.Unmarshal(payload, &result); err != nil {
+			log.Printf("Warning: Failed to parse JSON: %v", err)
+			return false, nil
+		}
 
-			var result map[string]string
-			if err := json.Unmarshal([]byte(msg.Payload), &result); err != nil {
-				log.Printf("Warning: Failed to parse JSON: %v", err)
-				continue
-			}
+		if err := result.Err(); err != nil {
+			return false, err
+		}
+		if result.Signature == "" {
+			return false, nil // Incomplete message, wait for next one
+		}
 
-			signatureBase64, ok := result["signature"]
-			if !ok {
-				continue // Incomplete message, wait for next one
-			}
+		// Decode base64 signature
+		signature, err := base64.StdEncoding.DecodeString(result.Signature)
+		if err != nil {
+			log.Printf("Warning: Failed to decode base64 signature: %v", err)
+			return false, nil
+		}
+
+		*out = signature
+		return true, nil
+	}
+}
+
+// processSignBatchResult returns a handler that stores the signatures of the
+// first result with one decodable signature per message in out
+func processSignBatchResult(out *[][]byte, count int) func(payload []byte) (bool, error) {
+	return func(payload []byte) (bool, error) {
+		var result SignResult
+		if err := json.Unmarshal(payload, &result); err != nil {
+			log.Printf("Warning: Failed to parse JSON: %v", err)
+			return false, nil
+		}
 
-			// Decode base64 signature
-			signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+		if err := result.Err(); err != nil {
+			return false, err
+		}
+		if len(result.Signatures) == 0 {
+			return false, nil // Incomplete message, wait for next one
+		}
+		if len(result.Signatures) != count {
+			return false, fmt.Errorf("expected %d signatures, got %d", count, len(result.Signatures))
+		}
+
+		signatures := make([][]byte, len(result.Signatures))
+		for i, encoded := range result.Signatures {
+			signature, err := base64.StdEncoding.DecodeString(encoded)
 			if err != nil {
-				log.Printf("Warning: Failed to decode base64 signature: %v", err)
-				continue
+				return false, fmt.Errorf("failed to decode signature %d: %w", i, err)
 			}
-
-			return signature, nil
+			signatures[i] = signature
 		}
+
+		*out = signatures
+		return true, nil
 	}
 }
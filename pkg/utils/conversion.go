@@ -54,3 +54,21 @@ func padTo32(b []byte) []byte {
 	copy(p[32-len(b):], b)
 	return p
 }
+
+// ToInt32Slice converts party IDs to the int32 slice used by pgx for INT[] columns
+func ToInt32Slice(values []uint32) []int32 {
+	result := make([]int32, len(values))
+	for i, v := range values {
+		result[i] = int32(v)
+	}
+	return result
+}
+
+// ToUint32Slice converts an INT[] column back to party IDs
+func ToUint32Slice(values []int32) []uint32 {
+	result := make([]uint32, len(values))
+	for i, v := range values {
+		result[i] = uint32(v)
+	}
+	return result
+}
@@ -46,8 +46,12 @@ func ToWalletResponse(wallet model.Wallet) model.WalletResponse {
 		return model.WalletResponse{}
 	}
 	return model.WalletResponse{
-		ID:      wallet.ID,
-		UserID:  wallet.UserID,
-		Address: wallet.Address,
+		ID:           wallet.ID,
+		UserID:       wallet.UserID,
+		Address:      wallet.Address,
+		Parties:      wallet.Parties,
+		Threshold:    wallet.Threshold,
+		Custodial:    wallet.EncryptedPrivateKey != "",
+		ShareVersion: wallet.ShareVersion,
 	}
 }
@@ -26,8 +26,14 @@ type Action int32
 const (
 	Action_KEYGEN      Action = 0
 	Action_SIGN        Action = 1
+	Action_RESHARE     Action = 2
 	Action_INIT_KEYGEN Action = 3
 	Action_INIT_SIGN   Action = 4
+	// Rotate every party's share of an existing key. The public key is unchanged.
+	Action_INIT_RESHARE Action = 5
+	// Sign every hash in msg_hashes in one session. Signatures are returned in
+	// the same order.
+	Action_INIT_SIGN_BATCH Action = 6
 )
 
 // Enum value maps for Action.
@@ -35,14 +41,20 @@ var (
 	Action_name = map[int32]string{
 		0: "KEYGEN",
 		1: "SIGN",
+		2: "RESHARE",
 		3: "INIT_KEYGEN",
 		4: "INIT_SIGN",
+		5: "INIT_RESHARE",
+		6: "INIT_SIGN_BATCH",
 	}
 	Action_value = map[string]int32{
-		"KEYGEN":      0,
-		"SIGN":        1,
-		"INIT_KEYGEN": 3,
-		"INIT_SIGN":   4,
+		"KEYGEN":          0,
+		"SIGN":            1,
+		"RESHARE":         2,
+		"INIT_KEYGEN":     3,
+		"INIT_SIGN":       4,
+		"INIT_RESHARE":    5,
+		"INIT_SIGN_BATCH": 6,
 	}
 )
 
@@ -150,13 +162,18 @@ func (x *TSSMessage) GetBroadcast() bool {
 }
 
 type ActionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Parties       []uint32               `protobuf:"varint,2,rep,packed,name=parties,proto3" json:"parties,omitempty"`
-	Threshold     uint32                 `protobuf:"varint,3,opt,name=threshold,proto3" json:"threshold,omitempty"`
-	MsgHash       []byte                 `protobuf:"bytes,4,opt,name=msg_hash,json=msgHash,proto3" json:"msg_hash,omitempty"`
-	ShareData     []byte                 `protobuf:"bytes,5,opt,name=share_data,json=shareData,proto3" json:"share_data,omitempty"`
-	Action        Action                 `protobuf:"varint,6,opt,name=action,proto3,enum=tss.Action" json:"action,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Parties   []uint32               `protobuf:"varint,2,rep,packed,name=parties,proto3" json:"parties,omitempty"`
+	Threshold uint32                 `protobuf:"varint,3,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	MsgHash   []byte                 `protobuf:"bytes,4,opt,name=msg_hash,json=msgHash,proto3" json:"msg_hash,omitempty"`
+	ShareData []byte                 `protobuf:"bytes,5,opt,name=share_data,json=shareData,proto3" json:"share_data,omitempty"`
+	Action    Action                 `protobuf:"varint,6,opt,name=action,proto3,enum=tss.Action" json:"action,omitempty"`
+	// Identifies the key to sign or reshare with. Sessions are unique per
+	// operation, so this is the session ID of the keygen that created the key.
+	KeyId string `protobuf:"bytes,7,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// Hashes signed in a single INIT_SIGN_BATCH session, msg_hash is unused
+	MsgHashes     [][]byte `protobuf:"bytes,8,rep,name=msg_hashes,json=msgHashes,proto3" json:"msg_hashes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -233,6 +250,20 @@ func (x *ActionRequest) GetAction() Action {
 	return Action_KEYGEN
 }
 
+func (x *ActionRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *ActionRequest) GetMsgHashes() [][]byte {
+	if x != nil {
+		return x.MsgHashes
+	}
+	return nil
+}
+
 type ActionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -298,7 +329,7 @@ var file_proto_tss_proto_rawDesc = string([]byte{
 	0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02,
 	0x74, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x72, 0x6f, 0x61, 0x64, 0x63, 0x61, 0x73, 0x74, 0x18,
 	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x62, 0x72, 0x6f, 0x61, 0x64, 0x63, 0x61, 0x73, 0x74,
-	0x22, 0xc5, 0x01, 0x0a, 0x0d, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x22, 0xfb, 0x01, 0x0a, 0x0d, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49,
 	0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
@@ -310,25 +341,31 @@ var file_proto_tss_proto_rawDesc = string([]byte{
 	0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x68, 0x61, 0x72, 0x65, 0x44,
 	0x61, 0x74, 0x61, 0x12, 0x23, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20,
 	0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x74, 0x73, 0x73, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x40, 0x0a, 0x0e, 0x41, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75,
-	0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x2a, 0x3e, 0x0a, 0x06, 0x41, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06, 0x4b, 0x45, 0x59, 0x47, 0x45, 0x4e, 0x10, 0x00,
-	0x12, 0x08, 0x0a, 0x04, 0x53, 0x49, 0x47, 0x4e, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x4e,
-	0x49, 0x54, 0x5f, 0x4b, 0x45, 0x59, 0x47, 0x45, 0x4e, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x49,
-	0x4e, 0x49, 0x54, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x10, 0x04, 0x32, 0x81, 0x01, 0x0a, 0x0a, 0x4d,
-	0x50, 0x43, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x38, 0x0a, 0x0e, 0x53, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x0f, 0x2e, 0x74, 0x73,
-	0x73, 0x2e, 0x54, 0x53, 0x53, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x0f, 0x2e, 0x74,
-	0x73, 0x73, 0x2e, 0x54, 0x53, 0x53, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x00, 0x28,
-	0x01, 0x30, 0x01, 0x12, 0x39, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x41, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x12, 0x2e, 0x74, 0x73, 0x73, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x74, 0x73, 0x73, 0x2e, 0x41, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x0b,
-	0x5a, 0x09, 0x6d, 0x70, 0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x06, 0x6b, 0x65, 0x79, 0x5f,
+	0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6b, 0x65, 0x79, 0x49, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6d, 0x73, 0x67, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x08, 0x20,
+	0x03, 0x28, 0x0c, 0x52, 0x09, 0x6d, 0x73, 0x67, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x40,
+	0x0a, 0x0e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x2a, 0x72, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06, 0x4b, 0x45,
+	0x59, 0x47, 0x45, 0x4e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x49, 0x47, 0x4e, 0x10, 0x01,
+	0x12, 0x0b, 0x0a, 0x07, 0x52, 0x45, 0x53, 0x48, 0x41, 0x52, 0x45, 0x10, 0x02, 0x12, 0x0f, 0x0a,
+	0x0b, 0x49, 0x4e, 0x49, 0x54, 0x5f, 0x4b, 0x45, 0x59, 0x47, 0x45, 0x4e, 0x10, 0x03, 0x12, 0x0d,
+	0x0a, 0x09, 0x49, 0x4e, 0x49, 0x54, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x10, 0x04, 0x12, 0x10, 0x0a,
+	0x0c, 0x49, 0x4e, 0x49, 0x54, 0x5f, 0x52, 0x45, 0x53, 0x48, 0x41, 0x52, 0x45, 0x10, 0x05, 0x12,
+	0x13, 0x0a, 0x0f, 0x49, 0x4e, 0x49, 0x54, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x5f, 0x42, 0x41, 0x54,
+	0x43, 0x48, 0x10, 0x06, 0x32, 0x81, 0x01, 0x0a, 0x0a, 0x4d, 0x50, 0x43, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x38, 0x0a, 0x0e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x0f, 0x2e, 0x74, 0x73, 0x73, 0x2e, 0x54, 0x53, 0x53, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x0f, 0x2e, 0x74, 0x73, 0x73, 0x2e, 0x54, 0x53, 0x53,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x39, 0x0a,
+	0x0c, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x2e,
+	0x74, 0x73, 0x73, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x13, 0x2e, 0x74, 0x73, 0x73, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x0b, 0x5a, 0x09, 0x6d, 0x70, 0x63, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -29,7 +29,7 @@ const (
 type MPCServiceClient interface {
 	// Stream messages between nodes for TSS protocol communication
 	StreamMessages(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[TSSMessage, TSSMessage], error)
-	// Notify other nodes to start keygen or signing process
+	// Notify other nodes to start keygen, signing or resharing process
 	NotifyAction(ctx context.Context, in *ActionRequest, opts ...grpc.CallOption) (*ActionResponse, error)
 }
 
@@ -70,7 +70,7 @@ func (c *mPCServiceClient) NotifyAction(ctx context.Context, in *ActionRequest,
 type MPCServiceServer interface {
 	// Stream messages between nodes for TSS protocol communication
 	StreamMessages(grpc.BidiStreamingServer[TSSMessage, TSSMessage]) error
-	// Notify other nodes to start keygen or signing process
+	// Notify other nodes to start keygen, signing or resharing process
 	NotifyAction(context.Context, *ActionRequest) (*ActionResponse, error)
 	mustEmbedUnimplementedMPCServiceServer()
 }